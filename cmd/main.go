@@ -1,10 +1,16 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/Builtbyjb/yay/pkg/lib"
+	"github.com/Builtbyjb/yay/pkg/lib/core"
 	"github.com/Builtbyjb/yay/pkg/tui"
 	"github.com/spf13/cobra"
 )
@@ -30,7 +36,8 @@ var rootCmd = &cobra.Command{
 			os.Exit(0)
 		}
 
-		if err := tui.Run(db, settings, VERSION); err != nil {
+		theme, _ := cmd.Flags().GetString("theme")
+		if err := tui.Run(db, settings, VERSION, theme); err != nil {
 			fmt.Println("Error running TUI:", err)
 			os.Exit(1)
 		}
@@ -59,7 +66,62 @@ var startCmd = &cobra.Command{
 		}
 		defer db.Close()
 
-		lib.KeyEventListener(db, nil)
+		// Stop listening on Ctrl+C or kill so the database is closed
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			lib.StopEventTap()
+		}()
+
+		logListener := func(msg string, err error) {
+			if err != nil {
+				fmt.Printf("%s: %v\n", msg, err)
+			} else {
+				fmt.Println(msg)
+			}
+		}
+		var onEvent func(lib.KeyEvent)
+		if path, _ := cmd.Flags().GetString("record"); path != "" {
+			f, err := os.Create(path)
+			if err != nil {
+				fmt.Println("Error creating recording:", err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			onEvent = lib.RecordKeyEvents(f, logListener)
+			fmt.Println("Recording every key event to", path+", including passwords typed")
+		}
+
+		if err := lib.KeyEventListener(db, onEvent, logListener); err != nil {
+			fmt.Println("Error starting listener:", err)
+			fmt.Println("Run yay doctor to see how to fix it.")
+			os.Exit(1)
+		}
+	},
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check permissions, settings and the database",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		request, _ := cmd.Flags().GetBool("request")
+
+		failed := false
+		for _, check := range lib.Doctor(request) {
+			fmt.Printf("[%s] %s: %s\n", check.Status, check.Name, check.Detail)
+			if check.Fix != "" {
+				fmt.Printf("    Fix: %s\n", check.Fix)
+			}
+			if check.Status == core.CheckFailed {
+				failed = true
+			}
+		}
+
+		if failed {
+			os.Exit(1)
+		}
 	},
 }
 
@@ -81,6 +143,316 @@ var updateCmd = &cobra.Command{
 	},
 }
 
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage application tags",
+	// Long:  `Add, remove and list the tags used to group applications.`,
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add <app> <tag>...",
+	Short: "Add tags to an application",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		db, setting := findApp(args[0])
+		defer db.Close()
+
+		for _, tag := range args[1:] {
+			if err := db.AddTag(setting.Id, tag); err != nil {
+				fmt.Println("Error adding tag:", err)
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:   "remove <app> <tag>...",
+	Short: "Remove tags from an application",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		db, setting := findApp(args[0])
+		defer db.Close()
+
+		for _, tag := range args[1:] {
+			if err := db.RemoveTag(setting.Id, tag); err != nil {
+				fmt.Println("Error removing tag:", err)
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+var tagListCmd = &cobra.Command{
+	Use:   "list [app]",
+	Short: "List application tags",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 1 {
+			db, setting := findApp(args[0])
+			defer db.Close()
+
+			tags, err := db.GetTags()
+			if err != nil {
+				fmt.Println("Error fetching tags:", err)
+				os.Exit(1)
+			}
+			for _, tag := range tags[setting.Id] {
+				fmt.Println(tag)
+			}
+			return
+		}
+
+		db, err := lib.GetDatabase()
+		if err != nil {
+			fmt.Println("Error occurred while fetching database:", err)
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		settings, err := db.GetAllSettings()
+		if err != nil {
+			fmt.Println("Error fetching applications:", err)
+			os.Exit(1)
+		}
+		for _, s := range settings {
+			if len(s.Tags) > 0 {
+				fmt.Printf("%s: %s\n", s.Title(), strings.Join(s.Tags, ", "))
+			}
+		}
+	},
+}
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause hotkeys until resumed, or for a while with --for",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		duration, _ := cmd.Flags().GetDuration("for")
+		if duration < 0 {
+			fmt.Println("Error: --for must be positive")
+			os.Exit(1)
+		}
+
+		db := openDatabase()
+		defer db.Close()
+
+		var until time.Time
+		if duration > 0 {
+			until = time.Now().Add(duration)
+		}
+		if err := db.Pause(until); err != nil {
+			fmt.Println("Error pausing hotkeys:", err)
+			os.Exit(1)
+		}
+
+		if until.IsZero() {
+			fmt.Println("Hotkeys paused until resumed.")
+		} else {
+			fmt.Printf("Hotkeys paused until %s.\n", until.Format("15:04"))
+		}
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume paused hotkeys",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+
+		if err := db.Resume(); err != nil {
+			fmt.Println("Error resuming hotkeys:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Hotkeys resumed.")
+	},
+}
+
+var excludeCmd = &cobra.Command{
+	Use:   "exclude",
+	Short: "Manage apps hotkeys pass through in",
+	// Long:  `Hotkeys are ignored while an excluded app is frontmost.`,
+}
+
+var excludeAddCmd = &cobra.Command{
+	Use:   "add <app>...",
+	Short: "Let hotkeys pass through while an app is frontmost",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, name := range args {
+			db, setting := findApp(name)
+			err := db.Exclude(setting.Id)
+			db.Close()
+			if err != nil {
+				fmt.Println("Error excluding application:", err)
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+var excludeRemoveCmd = &cobra.Command{
+	Use:   "remove <app>...",
+	Short: "Handle hotkeys again while an app is frontmost",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, name := range args {
+			db, setting := findApp(name)
+			err := db.Include(setting.Id)
+			db.Close()
+			if err != nil {
+				fmt.Println("Error including application:", err)
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+var excludeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List excluded applications",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openDatabase()
+		defer db.Close()
+
+		settings, err := db.GetExclusions()
+		if err != nil {
+			fmt.Println("Error fetching excluded applications:", err)
+			os.Exit(1)
+		}
+		for _, s := range settings {
+			fmt.Println(s.Title())
+		}
+	},
+}
+
+var scopeCmd = &cobra.Command{
+	Use:   "scope <app>",
+	Short: "Limit the apps an application's hotkey applies in",
+	Long: `Limit the apps an application's hotkey applies in, by the frontmost app.
+Apps are given by name or bundle id. Without flags the hotkey applies everywhere.
+A hotkey limited with --only takes precedence over the same hotkey bound everywhere.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		only, _ := cmd.Flags().GetStringSlice("only")
+		except, _ := cmd.Flags().GetStringSlice("except")
+		if len(only) > 0 && len(except) > 0 {
+			fmt.Println("Error: use either --only or --except")
+			os.Exit(1)
+		}
+
+		db, setting := findApp(args[0])
+		defer db.Close()
+
+		scope := core.Scope{Only: bundleIds(db, only), Except: bundleIds(db, except)}
+		if err := db.SetScope(setting.Id, scope); err != nil {
+			fmt.Println("Error setting scope:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s: %s\n", setting.Title(), scope.Describe())
+	},
+}
+
+// bundleIds resolves app names to bundle ids, keeping anything that isn't a
+// known app name as a bundle id.
+func bundleIds(db *core.Database, apps []string) []string {
+	var ids []string
+	for _, app := range apps {
+		setting, err := db.FindApp(app)
+		if err != nil {
+			fmt.Println("Error fetching application:", err)
+			os.Exit(1)
+		}
+		switch {
+		case setting != nil && setting.BundleId != "":
+			ids = append(ids, setting.BundleId)
+		case setting != nil:
+			fmt.Printf("%s has no bundle id.\n", setting.Title())
+			os.Exit(1)
+		case strings.Contains(app, "."):
+			ids = append(ids, app)
+		default:
+			fmt.Printf("No application named %q found, give its bundle id instead.\n", app)
+			os.Exit(1)
+		}
+	}
+	return ids
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the last hotkey, mode or enabled change",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		replayJournal("Undid", (*core.Database).Undo)
+	},
+}
+
+var redoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Reapply the last undone change",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		replayJournal("Redid", (*core.Database).Redo)
+	},
+}
+
+// replayJournal undoes or redoes the last edit and prints what changed.
+func replayJournal(verb string, replay func(*core.Database) ([]core.Change, error)) {
+	db := openDatabase()
+	defer db.Close()
+
+	changes, err := replay(db)
+	switch {
+	case errors.Is(err, core.ErrNothingToUndo):
+		fmt.Println("Nothing to undo.")
+		return
+	case errors.Is(err, core.ErrNothingToRedo):
+		fmt.Println("Nothing to redo.")
+		return
+	case err != nil:
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	for _, c := range changes {
+		fmt.Printf("%s: %s\n", verb, c)
+	}
+}
+
+// openDatabase opens the database, exiting when it can't be opened.
+func openDatabase() *core.Database {
+	db, err := lib.GetDatabase()
+	if err != nil {
+		fmt.Println("Error occurred while fetching database:", err)
+		os.Exit(1)
+	}
+	return db
+}
+
+// findApp opens the database and looks up an application by name or bundle
+// id, exiting when it can't be found.
+func findApp(name string) (*core.Database, *core.Setting) {
+	db := openDatabase()
+
+	setting, err := db.FindApp(name)
+	if err != nil {
+		db.Close()
+		fmt.Println("Error fetching application:", err)
+		os.Exit(1)
+	}
+	if setting == nil {
+		db.Close()
+		fmt.Printf("No application named %q found.\n", name)
+		os.Exit(1)
+	}
+
+	return db, setting
+}
+
 var helpCmd = &cobra.Command{
 	Use:   "help",
 	Short: "Show help information",
@@ -92,10 +464,26 @@ var helpCmd = &cobra.Command{
 }
 
 func main() {
+	rootCmd.Flags().String("theme", tui.THEME_AUTO, "color theme: "+strings.Join(tui.ThemeNames(), ", "))
 	rootCmd.AddCommand(versionCmd)
+	startCmd.Flags().String("record", "", "write every key event to this file, to replay in tests")
 	rootCmd.AddCommand(startCmd)
 	rootCmd.AddCommand(stopCmd)
+	doctorCmd.Flags().Bool("request", false, "ask macOS for the missing permissions")
+	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(updateCmd)
+	tagCmd.AddCommand(tagAddCmd, tagRemoveCmd, tagListCmd)
+	rootCmd.AddCommand(tagCmd)
+	pauseCmd.Flags().Duration("for", 0, "resume automatically after this long, e.g. 30m or 2h")
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
+	excludeCmd.AddCommand(excludeAddCmd, excludeRemoveCmd, excludeListCmd)
+	rootCmd.AddCommand(excludeCmd)
+	scopeCmd.Flags().StringSlice("only", nil, "apps the hotkey applies in")
+	scopeCmd.Flags().StringSlice("except", nil, "apps the hotkey doesn't apply in")
+	rootCmd.AddCommand(scopeCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(redoCmd)
 	rootCmd.AddCommand(helpCmd)
 
 	err := rootCmd.Execute()
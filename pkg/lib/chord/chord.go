@@ -0,0 +1,40 @@
+// Package chord turns key events into hotkeys. It holds the rules every
+// platform shares for combining modifiers, and the macOS key tables and event
+// adapter. It has no cgo so the listeners' chord matching can be tested, and
+// recorded events replayed, on any platform.
+package chord
+
+// Matcher tracks the modifiers held down across key events, on one keyboard
+// or several. Every held modifier is part of the hotkey a key press
+// completes, in canonical order. The zero value is ready to use.
+type Matcher struct {
+	held map[uint16]string // modifiers held down, by keycode
+}
+
+// Modifier records the modifier named mod, e.g. "command", being pressed or
+// released with the key of keycode. Both keys of a modifier can be held, it
+// stays held until both are released.
+func (m *Matcher) Modifier(keycode uint16, mod string, pressed bool) {
+	if !pressed {
+		delete(m.held, keycode)
+		return
+	}
+	if m.held == nil {
+		m.held = map[uint16]string{}
+	}
+	m.held[keycode] = mod
+}
+
+// Key returns the hotkey pressing key completes with the held modifiers,
+// e.g. "command+shift+a". ok is false when no modifier is held, the key then
+// passes through.
+func (m *Matcher) Key(key string) (hotkey string, ok bool) {
+	if len(m.held) == 0 {
+		return "", false
+	}
+	mods := make([]string, 0, len(m.held))
+	for _, mod := range m.held {
+		mods = append(mods, mod)
+	}
+	return CanonicalHotkey(mods, key), true
+}
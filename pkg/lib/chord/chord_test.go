@@ -0,0 +1,140 @@
+package chord
+
+import (
+	"slices"
+	"testing"
+)
+
+// Keycodes and event flags used in the tests
+const (
+	keycodeCommand = 55
+	keycodeShift   = 56
+	keycodeOption  = 58
+	keycodeA       = 0
+	keycode1       = 18
+
+	flagsNone    = 0x100
+	flagsCommand = 0x100108
+	flagsShift   = 0x20102
+	flagsBoth    = 0x12010a
+)
+
+func flagsChanged(keycode uint16, flags uint64) KeyEvent {
+	return KeyEvent{Keycode: keycode, Flags: flags, EventType: EventFlagsChanged}
+}
+
+func keyDown(keycode uint16, flags uint64) KeyEvent {
+	return KeyEvent{Keycode: keycode, Flags: flags, EventType: EventKeyDown}
+}
+
+func keyUp(keycode uint16, flags uint64) KeyEvent {
+	return KeyEvent{Keycode: keycode, Flags: flags, EventType: EventKeyUp}
+}
+
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		events   []KeyEvent
+		expected []string
+	}{
+		{
+			"command",
+			[]KeyEvent{flagsChanged(keycodeCommand, flagsCommand), keyDown(keycode1, flagsCommand), keyUp(keycode1, flagsCommand)},
+			[]string{"command+1"},
+		},
+		{
+			"no modifier",
+			[]KeyEvent{keyDown(keycodeA, flagsNone), keyUp(keycodeA, flagsNone)},
+			nil,
+		},
+		{
+			"command then shift",
+			[]KeyEvent{flagsChanged(keycodeCommand, flagsCommand), flagsChanged(keycodeShift, flagsBoth), keyDown(keycodeA, flagsBoth)},
+			[]string{"command+shift+a"},
+		},
+		{
+			"shift then command",
+			[]KeyEvent{flagsChanged(keycodeShift, flagsShift), flagsChanged(keycodeCommand, flagsBoth), keyDown(keycodeA, flagsBoth)},
+			[]string{"command+shift+a"},
+		},
+		{
+			"released",
+			[]KeyEvent{flagsChanged(keycodeCommand, flagsCommand), flagsChanged(keycodeCommand, flagsNone), keyDown(keycode1, flagsNone)},
+			nil,
+		},
+		{
+			"every modifier",
+			[]KeyEvent{flagsChanged(keycodeOption, 0x80120), flagsChanged(59, 0xc0121), flagsChanged(keycodeCommand, 0x1c0129), keyDown(keycode1, 0x1c0129)},
+			[]string{"command+control+option+1"},
+		},
+		{
+			"both commands",
+			[]KeyEvent{flagsChanged(keycodeCommand, flagsCommand), flagsChanged(54, 0x100118), flagsChanged(keycodeCommand, 0x100110), keyDown(keycode1, 0x100110)},
+			[]string{"command+1"},
+		},
+		{
+			"missed release",
+			[]KeyEvent{flagsChanged(keycodeCommand, flagsCommand), keyDown(keycode1, flagsNone)},
+			nil,
+		},
+		{
+			"unknown keycode",
+			[]KeyEvent{flagsChanged(keycodeCommand, flagsCommand), keyDown(200, flagsCommand)},
+			nil,
+		},
+		{
+			"option",
+			[]KeyEvent{flagsChanged(keycodeOption, 0x80120), keyDown(123, 0xa80120)},
+			[]string{"option+left arrow"},
+		},
+	}
+	for _, tc := range tests {
+		var m Matcher
+		var got []string
+		for _, event := range tc.events {
+			if hotkey, ok := m.Feed(event); ok {
+				got = append(got, hotkey)
+			}
+		}
+		if !slices.Equal(got, tc.expected) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestMatcherKey(t *testing.T) {
+	var m Matcher
+	if _, ok := m.Key("a"); ok {
+		t.Error("Expected no hotkey without a modifier")
+	}
+	m.Modifier(1, "shift", true)
+	m.Modifier(2, "command", true)
+	if hotkey, ok := m.Key("a"); !ok || hotkey != "command+shift+a" {
+		t.Errorf("Key = %q, %v, want command+shift+a", hotkey, ok)
+	}
+	m.Modifier(1, "shift", false)
+	if hotkey, ok := m.Key("a"); !ok || hotkey != "command+a" {
+		t.Errorf("Key = %q, %v, want command+a", hotkey, ok)
+	}
+}
+
+func TestIsModifierPressed(t *testing.T) {
+	tests := []struct {
+		flags   uint64
+		keycode uint16
+		pressed bool
+	}{
+		{flagsCommand, keycodeCommand, true},
+		{flagsCommand, 54, true}, // right command
+		{flagsCommand, keycodeShift, false},
+		{flagsBoth, keycodeShift, true},
+		{flagsNone, keycodeCommand, false},
+		{0x40101, 59, true}, // control
+		{flagsCommand, keycodeA, false},
+	}
+	for _, tc := range tests {
+		if got := IsModifierPressed(tc.flags, tc.keycode); got != tc.pressed {
+			t.Errorf("IsModifierPressed(%#x, %d) = %v, want %v", tc.flags, tc.keycode, got, tc.pressed)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package chord
+
+import (
+	"slices"
+	"strings"
+)
+
+// ModifierOrder is the order modifiers appear in in a canonical hotkey,
+// e.g. "command+shift+e".
+var ModifierOrder = []string{"command", "control", "option", "shift"}
+
+// CanonicalHotkey joins modifiers and a key into Yay's hotkey notation,
+// ordering the modifiers by ModifierOrder and dropping duplicates.
+func CanonicalHotkey(modifiers []string, key string) string {
+	mods := slices.Clone(modifiers)
+	slices.SortFunc(mods, func(a, b string) int {
+		return modifierRank(a) - modifierRank(b)
+	})
+	mods = slices.Compact(mods)
+	return strings.Join(append(mods, key), "+")
+}
+
+func modifierRank(mod string) int {
+	if i := slices.Index(ModifierOrder, mod); i >= 0 {
+		return i
+	}
+	return len(ModifierOrder)
+}
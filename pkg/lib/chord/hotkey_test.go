@@ -0,0 +1,22 @@
+package chord
+
+import "testing"
+
+func TestCanonicalHotkey(t *testing.T) {
+	tests := []struct {
+		mods     []string
+		key      string
+		expected string
+	}{
+		{[]string{"command"}, "e", "command+e"},
+		{[]string{"shift", "command"}, "e", "command+shift+e"},
+		{[]string{"option", "control", "command"}, "space", "command+control+option+space"},
+		{[]string{"command", "command"}, "e", "command+e"},
+		{nil, "f11", "f11"},
+	}
+	for _, tc := range tests {
+		if got := CanonicalHotkey(tc.mods, tc.key); got != tc.expected {
+			t.Errorf("CanonicalHotkey(%v, %q) = %q, want %q", tc.mods, tc.key, got, tc.expected)
+		}
+	}
+}
@@ -1,4 +1,49 @@
-package darwin
+package chord
+
+import "slices"
+
+// KeyEvent is a key event of the macOS event tap
+type KeyEvent struct {
+	Keycode   uint16
+	Flags     uint64
+	EventType int
+}
+
+// CGEventType values of the events the tap listens to
+const (
+	EventKeyDown      = 10
+	EventKeyUp        = 11
+	EventFlagsChanged = 12
+)
+
+// Feed updates the held modifiers with a macOS event and returns the hotkey
+// it completes, e.g. "command+1". ok is false for modifier changes, key ups,
+// unknown keys and keys pressed without a modifier, which all pass through.
+// Key repeats complete the hotkey again, so the tap swallows them too.
+func (m *Matcher) Feed(event KeyEvent) (hotkey string, ok bool) {
+	k, ok := RawToKeyDarwin[event.Keycode]
+	if !ok {
+		return "", false
+	}
+
+	switch event.EventType {
+	case EventFlagsChanged:
+		if slices.Contains(ModifiersMacos, k) {
+			m.Modifier(event.Keycode, k, IsModifierPressed(event.Flags, event.Keycode))
+		}
+
+	case EventKeyDown:
+		// Releases missed while the tap was off would leave modifiers held,
+		// the flags of the key press tell which really are
+		for keycode := range m.held {
+			if !IsModifierPressed(event.Flags, keycode) {
+				delete(m.held, keycode)
+			}
+		}
+		return m.Key(k)
+	}
+	return "", false
+}
 
 var ModifiersMacos = []string{"shift", "option", "control", "command"}
 
@@ -109,3 +154,20 @@ var RawToKeyDarwin = map[uint16]string{
 	126: "up arrow",
 	179: "fn", // undefined in `keytoraw` map
 }
+
+// IsModifierPressed checks whether the modifier flag corresponding to the
+// given keycode is currently set in the CGEvent flags bitmask.
+func IsModifierPressed(flags uint64, keycode uint16) bool {
+	switch keycode {
+	case 55, 54: // l-command, r-command
+		return flags&0x100000 != 0
+	case 56, 60: // l-shift, r-shift
+		return flags&0x020000 != 0
+	case 58, 61: // l-option, r-option
+		return flags&0x080000 != 0
+	case 59: // control
+		return flags&0x040000 != 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,91 @@
+package chord
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Names of the event types in recordings
+var eventTypeNames = map[int]string{
+	EventKeyDown:      "key-down",
+	EventKeyUp:        "key-up",
+	EventFlagsChanged: "flags-changed",
+}
+
+// WriteEvent appends event to a recording, one line per event with its
+// type, keycode and flags, e.g. "key-down 18 0x100108".
+func WriteEvent(w io.Writer, event KeyEvent) error {
+	name, ok := eventTypeNames[event.EventType]
+	if !ok {
+		name = strconv.Itoa(event.EventType)
+	}
+	_, err := fmt.Fprintf(w, "%s %d %#x\n", name, event.Keycode, event.Flags)
+	return err
+}
+
+// ReadEvents reads the events of a recording. Blank lines and lines starting
+// with # are skipped.
+func ReadEvents(r io.Reader) ([]KeyEvent, error) {
+	var events []KeyEvent
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		event, err := parseEvent(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+func parseEvent(line string) (KeyEvent, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return KeyEvent{}, fmt.Errorf("expected type, keycode and flags, got %q", line)
+	}
+
+	eventType := -1
+	for t, name := range eventTypeNames {
+		if name == fields[0] {
+			eventType = t
+		}
+	}
+	if eventType < 0 {
+		t, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return KeyEvent{}, fmt.Errorf("unknown event type %q", fields[0])
+		}
+		eventType = t
+	}
+
+	keycode, err := strconv.ParseUint(fields[1], 10, 16)
+	if err != nil {
+		return KeyEvent{}, fmt.Errorf("invalid keycode %q", fields[1])
+	}
+	flags, err := strconv.ParseUint(fields[2], 0, 64)
+	if err != nil {
+		return KeyEvent{}, fmt.Errorf("invalid flags %q", fields[2])
+	}
+	return KeyEvent{Keycode: uint16(keycode), Flags: flags, EventType: eventType}, nil
+}
+
+// Replay feeds events to a new Matcher and returns the hotkeys they
+// complete, in order.
+func Replay(events []KeyEvent) []string {
+	var m Matcher
+	var hotkeys []string
+	for _, event := range events {
+		if hotkey, ok := m.Feed(event); ok {
+			hotkeys = append(hotkeys, hotkey)
+		}
+	}
+	return hotkeys
+}
@@ -0,0 +1,93 @@
+package chord
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	events := []KeyEvent{
+		flagsChanged(keycodeCommand, flagsCommand),
+		keyDown(keycode1, flagsCommand),
+		keyUp(keycode1, flagsCommand),
+		{Keycode: 3, Flags: 0, EventType: 14}, // not a key event type
+	}
+
+	var buf bytes.Buffer
+	for _, event := range events {
+		if err := WriteEvent(&buf, event); err != nil {
+			t.Fatalf("WriteEvent returned error: %v", err)
+		}
+	}
+	if !strings.HasPrefix(buf.String(), "flags-changed 55 0x100108\nkey-down 18 0x100108\n") {
+		t.Errorf("Unexpected recording:\n%s", buf.String())
+	}
+
+	got, err := ReadEvents(&buf)
+	if err != nil {
+		t.Fatalf("ReadEvents returned error: %v", err)
+	}
+	if !slices.Equal(got, events) {
+		t.Errorf("Expected %v, got %v", events, got)
+	}
+}
+
+func TestReadEventsInvalid(t *testing.T) {
+	for _, recording := range []string{
+		"key-down 18",
+		"key-press 18 0x0",
+		"key-down 70000 0x0",
+		"key-down 18 flags",
+		"# fine\n\nkey-down x 0x0",
+	} {
+		if _, err := ReadEvents(strings.NewReader(recording)); err == nil {
+			t.Errorf("Expected an error for %q", recording)
+		}
+	}
+}
+
+// Recordings in testdata replay to the hotkeys of their "# expect:" line,
+// a comma separated list. Record new ones with yay start --record.
+func TestReplayRecordings(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.keys"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("No recordings found: %v", err)
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+
+		var expected []string
+		found := false
+		for line := range strings.Lines(string(data)) {
+			if list, ok := strings.CutPrefix(strings.TrimSpace(line), "# expect:"); ok {
+				found = true
+				for hotkey := range strings.SplitSeq(list, ",") {
+					if hotkey = strings.TrimSpace(hotkey); hotkey != "" {
+						expected = append(expected, hotkey)
+					}
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%s has no # expect: line", path)
+			continue
+		}
+
+		events, err := ReadEvents(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		if got := Replay(events); !slices.Equal(got, expected) {
+			t.Errorf("%s: expected %q, got %q", path, expected, got)
+		}
+	}
+}
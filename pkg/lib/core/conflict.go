@@ -0,0 +1,81 @@
+package core
+
+import "fmt"
+
+// What a hotkey collides with
+type ConflictKind int
+
+const (
+	ConflictReserved ConflictKind = iota // a chord handled by Yay itself
+	ConflictSetting                      // bound to another application
+	ConflictSystem                       // an operating system shortcut
+)
+
+// Shortcut is a named system wide key binding.
+type Shortcut struct {
+	Hotkey string
+	Name   string
+}
+
+type Conflict struct {
+	Kind    ConflictKind
+	Hotkey  string
+	Name    string   // the app or shortcut the hotkey belongs to
+	Setting *Setting // the other application, for ConflictSetting
+}
+
+func (c Conflict) String() string {
+	switch c.Kind {
+	case ConflictReserved:
+		return fmt.Sprintf("%s is reserved by Yay (%s)", c.Hotkey, c.Name)
+	case ConflictSetting:
+		return fmt.Sprintf("%s is already used by %s", c.Hotkey, c.Name)
+	default:
+		return fmt.Sprintf("%s is a system shortcut (%s)", c.Hotkey, c.Name)
+	}
+}
+
+// ConflictChecker finds what else a hotkey is bound to. Reserved and System
+// are platform specific and may be left empty.
+type ConflictChecker struct {
+	Reserved func(hotkey string) (string, bool)
+	System   []Shortcut
+}
+
+// Check returns the conflicts of assigning hotkey to the setting with the
+// given id, ordered reserved, other settings, then system shortcuts. Other
+// settings only conflict when their scopes overlap.
+func (c ConflictChecker) Check(hotkey string, id int, settings []Setting) []Conflict {
+	var scope Scope
+	for _, s := range settings {
+		if s.Id == id {
+			scope, _ = ParseScope(s.Scope)
+		}
+	}
+
+	var conflicts []Conflict
+
+	if c.Reserved != nil {
+		if name, ok := c.Reserved(hotkey); ok {
+			conflicts = append(conflicts, Conflict{Kind: ConflictReserved, Hotkey: hotkey, Name: name})
+		}
+	}
+
+	for i := range settings {
+		s := &settings[i]
+		if s.Id == id || !s.HotKey.Valid || s.HotKey.String != hotkey {
+			continue
+		}
+		if other, err := ParseScope(s.Scope); err == nil && scope.Overlaps(other) {
+			conflicts = append(conflicts, Conflict{Kind: ConflictSetting, Hotkey: hotkey, Name: s.Title(), Setting: s})
+		}
+	}
+
+	for _, shortcut := range c.System {
+		if shortcut.Hotkey == hotkey {
+			conflicts = append(conflicts, Conflict{Kind: ConflictSystem, Hotkey: hotkey, Name: shortcut.Name})
+		}
+	}
+
+	return conflicts
+}
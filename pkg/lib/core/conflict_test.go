@@ -0,0 +1,100 @@
+package core
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func conflictSettings() []Setting {
+	return []Setting{
+		{Id: 1, Name: "Firefox", HotKey: sql.NullString{String: "command+f", Valid: true}},
+		{Id: 2, Name: "Notes", HotKey: sql.NullString{String: "command+n", Valid: true}},
+		{Id: 3, Name: "Safari"},
+	}
+}
+
+func TestCheckNoConflict(t *testing.T) {
+	var checker ConflictChecker
+	if conflicts := checker.Check("command+s", 3, conflictSettings()); len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %v", conflicts)
+	}
+}
+
+func TestCheckOwnHotkeyIsNotAConflict(t *testing.T) {
+	var checker ConflictChecker
+	if conflicts := checker.Check("command+f", 1, conflictSettings()); len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %v", conflicts)
+	}
+}
+
+func TestCheckSettingConflict(t *testing.T) {
+	var checker ConflictChecker
+	conflicts := checker.Check("command+n", 3, conflictSettings())
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %d", len(conflicts))
+	}
+
+	c := conflicts[0]
+	if c.Kind != ConflictSetting {
+		t.Errorf("Expected ConflictSetting, got %d", c.Kind)
+	}
+	if c.Setting == nil || c.Setting.Id != 2 {
+		t.Errorf("Expected conflict with setting 2, got %v", c.Setting)
+	}
+	if c.String() != "command+n is already used by Notes" {
+		t.Errorf("Unexpected description %q", c.String())
+	}
+}
+
+func TestCheckReservedAndSystemConflicts(t *testing.T) {
+	checker := ConflictChecker{
+		Reserved: func(hotkey string) (string, bool) {
+			return "switch desktops", hotkey == "command+esc"
+		},
+		System: []Shortcut{
+			{Hotkey: "command+space", Name: "Show Spotlight search"},
+			{Hotkey: "command+esc", Name: "Something else"},
+		},
+	}
+
+	conflicts := checker.Check("command+esc", 3, conflictSettings())
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts, got %d", len(conflicts))
+	}
+	if conflicts[0].Kind != ConflictReserved {
+		t.Errorf("Expected reserved conflict first, got %d", conflicts[0].Kind)
+	}
+	if conflicts[1].Kind != ConflictSystem {
+		t.Errorf("Expected system conflict second, got %d", conflicts[1].Kind)
+	}
+
+	conflicts = checker.Check("command+space", 3, conflictSettings())
+	if len(conflicts) != 1 || conflicts[0].Name != "Show Spotlight search" {
+		t.Errorf("Expected Spotlight conflict, got %v", conflicts)
+	}
+}
+
+func TestCheckScopedSettings(t *testing.T) {
+	settings := []Setting{
+		{Id: 1, Name: "Firefox", HotKey: sql.NullString{String: "command+f", Valid: true}},
+		{Id: 2, Name: "Finder", HotKey: sql.NullString{String: "command+f", Valid: true}, Scope: "only:com.apple.Safari"},
+		{Id: 3, Name: "Notes", Scope: "only:com.apple.Safari,com.apple.Terminal"},
+		{Id: 4, Name: "Safari", Scope: "except:com.apple.Terminal"},
+	}
+
+	var checker ConflictChecker
+	// A binding scoped to Safari takes precedence over the global one
+	if conflicts := checker.Check("command+f", 2, settings); len(conflicts) != 0 {
+		t.Errorf("Expected no conflict with the global binding, got %v", conflicts)
+	}
+
+	conflicts := checker.Check("command+f", 3, settings)
+	if len(conflicts) != 1 || conflicts[0].Setting.Id != 2 {
+		t.Errorf("Expected a conflict with the binding also scoped to Safari, got %v", conflicts)
+	}
+
+	conflicts = checker.Check("command+f", 4, settings)
+	if len(conflicts) != 1 || conflicts[0].Setting.Id != 1 {
+		t.Errorf("Expected a conflict with the global binding only, got %v", conflicts)
+	}
+}
@@ -2,6 +2,8 @@ package core
 
 import (
 	"database/sql"
+	"fmt"
+	"slices"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -19,32 +21,216 @@ func NewDatabase(dbPath string) (*Database, error) {
 	return &Database{conn: db}, nil
 }
 
-func (d *Database) Init() error {
-	createTableQuery := `
-	CREATE TABLE IF NOT EXISTS settings (
+// Columns of the settings table. A hotkey is unique per scope, which the
+// indexes created by createHotkeyIndexes enforce.
+const settingsTableColumns = `
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		name TEXT NOT NULL,
 		bin_name TEXT NOT NULL,
 		path TEXT NOT NULL,
-		hotkey TEXT UNIQUE,
+		hotkey TEXT,
 		mode TEXT CHECK(mode IN ('default', 'desktop')),
-		enabled BOOLEAN
-	);`
+		enabled BOOLEAN,
+		display_name TEXT NOT NULL DEFAULT '',
+		version TEXT NOT NULL DEFAULT '',
+		bundle_id TEXT NOT NULL DEFAULT '',
+		icon_path TEXT NOT NULL DEFAULT '',
+		category TEXT NOT NULL DEFAULT '',
+		scope TEXT NOT NULL DEFAULT ''`
+
+func (d *Database) Init() error {
+	createTableQuery := "CREATE TABLE IF NOT EXISTS settings (" + settingsTableColumns + ");"
 
 	_, err := d.conn.Exec(createTableQuery)
 	if err != nil {
 		return err
 	}
 
+	if err := d.migrateSettings(); err != nil {
+		return err
+	}
+
 	createHotkeyIndex := `CREATE INDEX IF NOT EXISTS idx_hotkey ON settings (hotkey)`
 	_, err = d.conn.Exec(createHotkeyIndex)
 	if err != nil {
 		return err
 	}
 
+	createTagsTable := `
+	CREATE TABLE IF NOT EXISTS tags (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		setting_id INTEGER NOT NULL REFERENCES settings(id) ON DELETE CASCADE,
+		tag TEXT NOT NULL,
+		source TEXT NOT NULL DEFAULT 'user' CHECK(source IN ('user', 'category')),
+		UNIQUE(setting_id, tag)
+	);`
+	_, err = d.conn.Exec(createTagsTable)
+	if err != nil {
+		return err
+	}
+
+	if err := d.createJournal(); err != nil {
+		return err
+	}
+
+	if err := d.createUsage(); err != nil {
+		return err
+	}
+
+	if err := d.createPreferences(); err != nil {
+		return err
+	}
+
+	if err := d.createExclusions(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// metadataColumns lists the app metadata columns added after the initial
+// settings schema. Databases created by older versions are migrated in place.
+var metadataColumns = []string{"display_name", "version", "bundle_id", "icon_path", "category"}
+
+// Columns added after the initial settings schema: the app metadata and the
+// scope of the hotkey
+var addedColumns = append(slices.Clone(metadataColumns), "scope")
+
+// settingColumns is the column order expected by scanSetting.
+const settingColumns = "id, name, bin_name, path, hotkey, mode, enabled, display_name, version, bundle_id, icon_path, category, scope"
+
+func (d *Database) migrateSettings() error {
+	rows, err := d.conn.Query("PRAGMA table_info(settings)")
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]struct{})
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   bool
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	for _, col := range addedColumns {
+		if _, ok := existing[col]; ok {
+			continue
+		}
+		query := fmt.Sprintf("ALTER TABLE settings ADD COLUMN %s TEXT NOT NULL DEFAULT ''", col)
+		if _, err := d.conn.Exec(query); err != nil {
+			return err
+		}
+	}
+
+	// Older versions made a hotkey unique across all apps with a constraint,
+	// which can only be dropped by rebuilding the table
+	unique, err := d.hasUniqueConstraint()
+	if err != nil {
+		return err
+	}
+	if unique {
+		if err := d.rebuildSettings(); err != nil {
+			return fmt.Errorf("migrating hotkey scopes: %w", err)
+		}
+	}
+
+	return d.createHotkeyIndexes()
+}
+
+// hasUniqueConstraint reports whether a column of the settings table is
+// declared UNIQUE.
+func (d *Database) hasUniqueConstraint() (bool, error) {
+	rows, err := d.conn.Query("PRAGMA index_list(settings)")
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			seq     int
+			name    string
+			unique  bool
+			origin  string
+			partial bool
+		)
+		if err := rows.Scan(&seq, &name, &unique, &origin, &partial); err != nil {
+			return false, err
+		}
+		// "u" for indexes created by a UNIQUE constraint
+		if origin == "u" {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// rebuildSettings copies the settings into a table with the current schema.
+// Other tables reference settings by id, which is kept.
+func (d *Database) rebuildSettings() error {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		"CREATE TABLE settings_rebuild (" + settingsTableColumns + ")",
+		"INSERT INTO settings_rebuild (" + settingColumns + ") SELECT " + settingColumns + " FROM settings",
+		"DROP TABLE settings",
+		"ALTER TABLE settings_rebuild RENAME TO settings",
+	} {
+		if _, err := tx.Exec(query); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// createHotkeyIndexes makes a hotkey unique per scope. Bindings limited to
+// some apps may share a hotkey, as long as their scopes differ, while only
+// one binding applying everywhere, or everywhere except some apps, may use
+// it. Specific scopes naming the same app are caught by ConflictChecker.
+func (d *Database) createHotkeyIndexes() error {
+	for _, query := range []string{
+		"CREATE UNIQUE INDEX IF NOT EXISTS settings_hotkey_scope ON settings(hotkey, scope)",
+		"CREATE UNIQUE INDEX IF NOT EXISTS settings_hotkey_unscoped ON settings(hotkey) WHERE scope NOT LIKE '" + scopeOnly + "%'",
+	} {
+		if _, err := d.conn.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSetting(row rowScanner) (Setting, error) {
+	var s Setting
+	err := row.Scan(
+		&s.Id, &s.Name, &s.BinName, &s.Path, &s.HotKey, &s.Mode, &s.Enabled,
+		&s.DisplayName, &s.Version, &s.BundleId, &s.IconPath, &s.Category, &s.Scope,
+	)
+	return s, err
+}
+
 func (d *Database) Close() error {
 	return d.conn.Close()
 }
@@ -55,44 +241,148 @@ func (d *Database) Insert(name string, path string, binName string, hotkey sql.N
 	return err
 }
 
+// UpdateEnabled turns a setting on or off. Like the other setting edits it
+// is recorded in the change journal and can be undone.
 func (d *Database) UpdateEnabled(id int, enabled bool) error {
-	query := "UPDATE settings SET enabled = ? WHERE id = ? "
-	_, err := d.conn.Exec(query, enabled, id)
-	return err
+	return d.UpdateEnabledAll([]int{id}, enabled)
+}
+
+// UpdateEnabledAll turns several settings on or off as a single edit.
+func (d *Database) UpdateEnabledAll(ids []int, enabled bool) error {
+	return d.editAll(ids, FieldEnabled, enabledValue(enabled))
 }
 
 func (d *Database) UpdateMode(id int, mode string) error {
-	query := "update settings set mode = ? where id = ? "
-	_, err := d.conn.Exec(query, mode, id)
-	return err
+	return d.UpdateModeAll([]int{id}, mode)
+}
+
+// UpdateModeAll sets the mode of several settings as a single edit.
+func (d *Database) UpdateModeAll(ids []int, mode string) error {
+	return d.editAll(ids, FieldMode, sql.NullString{String: mode, Valid: true})
 }
 
+// FindByHotkey returns the setting hotkey is bound to while no app with a
+// scoped binding is frontmost, see FindByHotkeyIn.
 func (d *Database) FindByHotkey(hotkey string) (*Setting, error) {
-	query := "SELECT * FROM settings WHERE hotkey = ?"
-	row := d.conn.QueryRow(query, hotkey)
+	return d.FindByHotkeyIn(hotkey, "")
+}
 
-	var s Setting
-	if err := row.Scan(&s.Id, &s.Name, &s.BinName, &s.Path, &s.HotKey, &s.Mode, &s.Enabled); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
+// FindByHotkeyIn returns the setting hotkey triggers while the app with
+// bundleId is frontmost, nil when there is none. Bindings scoped to that app
+// take precedence over the others, and enabled settings over disabled ones.
+func (d *Database) FindByHotkeyIn(hotkey string, bundleId string) (*Setting, error) {
+	return d.FindByHotkeyFor(hotkey, func() string { return bundleId })
+}
+
+// FindByHotkeyFor is FindByHotkeyIn with the bundle id of the frontmost app
+// returned by frontmost, which is only called when a binding of hotkey is
+// scoped. Listeners use it to find the frontmost app only when it matters.
+func (d *Database) FindByHotkeyFor(hotkey string, frontmost func() string) (*Setting, error) {
+	query := "SELECT " + settingColumns + " FROM settings WHERE hotkey = ? ORDER BY id"
+	rows, err := d.conn.Query(query, hotkey)
+	if err != nil {
 		return nil, err
 	}
-	return &s, nil
+	defer rows.Close()
+
+	var bundleId string
+	looked := false
+
+	var best *Setting
+	bestRank := -1
+	for rows.Next() {
+		s, err := scanSetting(rows)
+		if err != nil {
+			return nil, err
+		}
+		scope, err := ParseScope(s.Scope)
+		if err != nil {
+			continue
+		}
+		if scope.String() != "" && !looked {
+			bundleId, looked = frontmost(), true
+		}
+		if !scope.Applies(bundleId) {
+			continue
+		}
+
+		rank := 0
+		if s.Enabled {
+			rank += 2
+		}
+		if scope.Specific() {
+			rank += 1
+		}
+		if rank > bestRank {
+			best, bestRank = &s, rank
+		}
+	}
+	return best, rows.Err()
 }
 
-func (d *Database) UpdateHotkey(id int, hotkey sql.NullString) error {
-	query := "UPDATE settings SET hotkey = ? WHERE id = ? "
-	_, err := d.conn.Exec(query, hotkey, id)
+// SetScope limits where the hotkey of a setting applies.
+func (d *Database) SetScope(id int, scope Scope) error {
+	_, err := d.conn.Exec("UPDATE settings SET scope = ? WHERE id = ?", scope.String(), id)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("the hotkey is already bound %s by another app", scope.Describe())
+	}
 	return err
 }
 
+func (d *Database) UpdateHotkey(id int, hotkey sql.NullString) error {
+	return d.edit(func(tx *sql.Tx) ([]Change, error) {
+		return []Change{{SettingId: id, Field: FieldHotkey, New: hotkey}}, nil
+	})
+}
+
 func (d *Database) ClearHotkey(id int) error {
-	query := "UPDATE settings SET hotkey = ? WHERE id = ?"
-	_, err := d.conn.Exec(query, nil, id)
-	return err
+	return d.ClearHotkeys([]int{id})
 }
 
+// ClearHotkeys removes the hotkeys of several settings as a single edit.
+func (d *Database) ClearHotkeys(ids []int) error {
+	return d.editAll(ids, FieldHotkey, sql.NullString{})
+}
+
+// SwapHotkeys exchanges the hotkeys of two settings, along with the scopes
+// they apply in.
+func (d *Database) SwapHotkeys(aId int, bId int) error {
+	return d.edit(func(tx *sql.Tx) ([]Change, error) {
+		var changes []Change
+		for _, field := range []string{FieldHotkey, FieldScope} {
+			a, err := readField(tx, aId, field)
+			if err != nil {
+				return nil, err
+			}
+			b, err := readField(tx, bId, field)
+			if err != nil {
+				return nil, err
+			}
+			if a == b {
+				continue
+			}
+			changes = append(changes,
+				Change{SettingId: aId, Field: field, New: b},
+				Change{SettingId: bId, Field: field, New: a},
+			)
+		}
+		return changes, nil
+	})
+}
+
+// ReassignHotkey moves hotkey from one setting to another, leaving the first
+// without a hotkey.
+func (d *Database) ReassignHotkey(fromId int, toId int, hotkey string) error {
+	return d.edit(func(tx *sql.Tx) ([]Change, error) {
+		return []Change{
+			{SettingId: fromId, Field: FieldHotkey},
+			{SettingId: toId, Field: FieldHotkey, New: sql.NullString{String: hotkey, Valid: true}},
+		}, nil
+	})
+}
+
+// Refresh adds the apps not stored yet, updates the metadata of the stored
+// ones and removes the apps no longer installed, in a single transaction.
 func (d *Database) Refresh(apps []App) ([]Setting, error) {
 	// Build a map of app paths for quick lookup
 	appMap := make(map[string]App)
@@ -100,35 +390,94 @@ func (d *Database) Refresh(apps []App) ([]Setting, error) {
 		appMap[app.Path] = app
 	}
 
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	// Fetch all existing settings from the database
-	existingPaths, err := d.getExistingPaths()
+	existing, err := getExistingApps(tx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Add apps in apps list but not in the database
+	// Add apps in apps list but not in the database, and refresh the
+	// metadata of the ones already there (versions change on update)
 	for _, app := range apps {
-		if _, exists := existingPaths[app.Path]; !exists {
-			_, err := d.conn.Exec(
-				"INSERT INTO settings (name, path, bin_name, hotkey, mode, enabled) VALUES (?, ?, ?, ?, ?, ?)",
+		stored, exists := existing[app.Path]
+		if !exists {
+			result, err := tx.Exec(
+				`INSERT INTO settings (name, path, bin_name, hotkey, mode, enabled,
+				display_name, version, bundle_id, icon_path, category)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 				app.Name, app.Path, app.BinName, sql.NullString{String: "", Valid: false}, "default", true,
+				app.DisplayName, app.Version, app.BundleId, app.IconPath, app.Category,
 			)
 			if err != nil {
 				return nil, err
 			}
+			insertedId, err := result.LastInsertId()
+			if err != nil {
+				return nil, err
+			}
+			existing[app.Path] = storedApp{id: int(insertedId), app: app}
+			if err := syncCategoryTag(tx, int(insertedId), app.Category); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if stored.app != app {
+			_, err := tx.Exec(
+				`UPDATE settings SET name = ?, bin_name = ?, display_name = ?, version = ?,
+				bundle_id = ?, icon_path = ?, category = ? WHERE path = ?`,
+				app.Name, app.BinName, app.DisplayName, app.Version,
+				app.BundleId, app.IconPath, app.Category, app.Path,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := syncCategoryTag(tx, stored.id, app.Category); err != nil {
+			return nil, err
 		}
 	}
 
 	// Remove apps in the database but not in the apps list
-	for path := range existingPaths {
+	for path, stored := range existing {
 		if _, exists := appMap[path]; !exists {
-			_, err := d.conn.Exec("DELETE FROM settings WHERE path = ?", path)
+			// Foreign keys aren't enforced by default in SQLite, so clean up
+			// the tags, journal, usage and exclusions explicitly. Journal
+			// batches are dropped whole, undoing part of a bulk edit would
+			// be surprising.
+			_, err := tx.Exec("DELETE FROM tags WHERE setting_id = ?", stored.id)
+			if err != nil {
+				return nil, err
+			}
+			_, err = tx.Exec("DELETE FROM changes WHERE batch IN (SELECT batch FROM changes WHERE setting_id = ?)", stored.id)
+			if err != nil {
+				return nil, err
+			}
+			_, err = tx.Exec("DELETE FROM usage WHERE setting_id = ?", stored.id)
+			if err != nil {
+				return nil, err
+			}
+			_, err = tx.Exec("DELETE FROM exclusions WHERE setting_id = ?", stored.id)
+			if err != nil {
+				return nil, err
+			}
+			_, err = tx.Exec("DELETE FROM settings WHERE path = ?", path)
 			if err != nil {
 				return nil, err
 			}
 		}
 	}
 
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	// Return the updated settings list
 	settings, err := d.GetAllSettings()
 	if err != nil {
@@ -138,30 +487,41 @@ func (d *Database) Refresh(apps []App) ([]Setting, error) {
 	return settings, nil
 }
 
-func (d *Database) getExistingPaths() (map[string]struct{}, error) {
-	rows, err := d.conn.Query("SELECT * FROM settings")
+// storedApp is the setting id and metadata of a stored app
+type storedApp struct {
+	id  int
+	app App
+}
+
+// getExistingApps maps the path of every stored app to its setting id and
+// metadata.
+func getExistingApps(tx *sql.Tx) (map[string]storedApp, error) {
+	rows, err := tx.Query(`SELECT id, name, bin_name, path, display_name, version,
+		bundle_id, icon_path, category FROM settings`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	existingPaths := make(map[string]struct{})
+	existing := make(map[string]storedApp)
 	for rows.Next() {
-		var s Setting
-		if err := rows.Scan(&s.Id, &s.Name, &s.BinName, &s.Path, &s.HotKey, &s.Mode, &s.Enabled); err != nil {
+		var s storedApp
+		a := &s.app
+		if err := rows.Scan(&s.id, &a.Name, &a.BinName, &a.Path, &a.DisplayName, &a.Version,
+			&a.BundleId, &a.IconPath, &a.Category); err != nil {
 			return nil, err
 		}
-		existingPaths[s.Path] = struct{}{}
+		existing[a.Path] = s
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return existingPaths, nil
+	return existing, nil
 }
 
 func (d *Database) GetAllSettings() ([]Setting, error) {
-	updatedRows, err := d.conn.Query("SELECT * FROM settings ORDER BY name ASC")
+	updatedRows, err := d.conn.Query("SELECT " + settingColumns + " FROM settings ORDER BY COALESCE(NULLIF(display_name, ''), name) COLLATE NOCASE ASC")
 	if err != nil {
 		return nil, err
 	}
@@ -169,8 +529,8 @@ func (d *Database) GetAllSettings() ([]Setting, error) {
 
 	var settings []Setting
 	for updatedRows.Next() {
-		var s Setting
-		if err := updatedRows.Scan(&s.Id, &s.Name, &s.BinName, &s.Path, &s.HotKey, &s.Mode, &s.Enabled); err != nil {
+		s, err := scanSetting(updatedRows)
+		if err != nil {
 			return nil, err
 		}
 		settings = append(settings, s)
@@ -179,5 +539,19 @@ func (d *Database) GetAllSettings() ([]Setting, error) {
 		return nil, err
 	}
 
+	tags, err := d.GetTags()
+	if err != nil {
+		return nil, err
+	}
+	usage, err := d.GetUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range settings {
+		settings[i].Tags = tags[settings[i].Id]
+		settings[i].Usage = usage[settings[i].Id]
+	}
+
 	return settings, nil
 }
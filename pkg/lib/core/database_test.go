@@ -2,6 +2,7 @@ package core
 
 import (
 	"database/sql"
+	"slices"
 	"testing"
 )
 
@@ -291,6 +292,67 @@ func TestRefreshNoChange(t *testing.T) {
 	}
 }
 
+func TestRefreshSkipsUnchangedApps(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	apps := []App{
+		{Name: "App1", Path: "/usr/bin/app1", Version: "1.0"},
+		{Name: "App2", Path: "/usr/bin/app2", Category: "public.app-category.utilities"},
+	}
+	seedApps(t, db, apps)
+
+	// Count the rows written from now on
+	_, err := db.conn.Exec(`
+	CREATE TABLE writes (tbl TEXT);
+	CREATE TRIGGER settings_updated AFTER UPDATE ON settings BEGIN INSERT INTO writes VALUES ('settings'); END;
+	CREATE TRIGGER tags_deleted AFTER DELETE ON tags BEGIN INSERT INTO writes VALUES ('tags'); END;
+	CREATE TRIGGER tags_inserted AFTER INSERT ON tags BEGIN INSERT INTO writes VALUES ('tags'); END;`)
+	if err != nil {
+		t.Fatalf("Failed to create triggers: %v", err)
+	}
+	writes := func() int {
+		t.Helper()
+		var n int
+		if err := db.conn.QueryRow("SELECT COUNT(*) FROM writes").Scan(&n); err != nil {
+			t.Fatalf("Failed to count writes: %v", err)
+		}
+		return n
+	}
+
+	seedApps(t, db, apps)
+	if n := writes(); n != 0 {
+		t.Errorf("Expected unchanged apps not to be written, got %d writes", n)
+	}
+
+	apps[0].Version = "2.0"
+	seedApps(t, db, apps)
+	if n := writes(); n != 1 {
+		t.Errorf("Expected only the updated app to be written, got %d writes", n)
+	}
+}
+
+func TestGetAllSettingsOrdersByTitle(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "Zed", Path: "/usr/bin/zed", DisplayName: "Alpha"},
+		{Name: "beta", Path: "/usr/bin/beta"},
+		{Name: "Code", Path: "/usr/bin/code", DisplayName: "Visual Studio Code"},
+		{Name: "Calendar", Path: "/usr/bin/calendar"},
+	})
+
+	var titles []string
+	for _, s := range settings {
+		titles = append(titles, s.Title())
+	}
+	expected := []string{"Alpha", "beta", "Calendar", "Visual Studio Code"}
+	if !slices.Equal(titles, expected) {
+		t.Errorf("Expected %v, got %v", expected, titles)
+	}
+}
+
 func TestRefreshWithDuplicatePaths(t *testing.T) {
 	db := setupTestDatabase(t)
 	defer db.Close()
@@ -342,6 +404,211 @@ func TestRefreshLargeAppList(t *testing.T) {
 	}
 }
 
+func TestRefreshStoresMetadata(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	apps := []App{
+		{
+			Name:        "Editor",
+			Path:        "/Applications/Editor.app/Contents/MacOS",
+			BinName:     "editor",
+			DisplayName: "Code Editor",
+			Version:     "1.0",
+			BundleId:    "com.example.editor",
+			IconPath:    "/Applications/Editor.app/Contents/Resources/Editor.icns",
+			Category:    "public.app-category.developer-tools",
+		},
+	}
+
+	settings := seedApps(t, db, apps)
+	if len(settings) != 1 {
+		t.Fatalf("Expected 1 setting, got %d", len(settings))
+	}
+
+	s := settings[0]
+	if s.DisplayName != "Code Editor" {
+		t.Errorf("Expected display name %q, got %q", "Code Editor", s.DisplayName)
+	}
+	if s.Version != "1.0" {
+		t.Errorf("Expected version %q, got %q", "1.0", s.Version)
+	}
+	if s.BundleId != "com.example.editor" {
+		t.Errorf("Expected bundle id %q, got %q", "com.example.editor", s.BundleId)
+	}
+	if s.IconPath != apps[0].IconPath {
+		t.Errorf("Expected icon path %q, got %q", apps[0].IconPath, s.IconPath)
+	}
+	if s.Category != "public.app-category.developer-tools" {
+		t.Errorf("Expected category %q, got %q", "public.app-category.developer-tools", s.Category)
+	}
+	if s.Title() != "Code Editor" {
+		t.Errorf("Expected title %q, got %q", "Code Editor", s.Title())
+	}
+}
+
+func TestRefreshUpdatesMetadata(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	apps := []App{
+		{Name: "Editor", Path: "/usr/bin/editor", Version: "1.0"},
+	}
+	settings := seedApps(t, db, apps)
+	id := settings[0].Id
+
+	if err := db.UpdateHotkey(id, sql.NullString{String: "command+e", Valid: true}); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+
+	apps[0].Version = "2.0"
+	refreshed := seedApps(t, db, apps)
+
+	if refreshed[0].Id != id {
+		t.Errorf("Expected id to remain %d, got %d", id, refreshed[0].Id)
+	}
+	if refreshed[0].Version != "2.0" {
+		t.Errorf("Expected version %q, got %q", "2.0", refreshed[0].Version)
+	}
+	if refreshed[0].HotKey.String != "command+e" {
+		t.Errorf("Expected hotkey to be preserved, got %q", refreshed[0].HotKey.String)
+	}
+}
+
+func TestInitMigratesLegacySchema(t *testing.T) {
+	db, err := NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Schema as created by versions without app metadata
+	_, err = db.conn.Exec(`
+	CREATE TABLE settings (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		bin_name TEXT NOT NULL,
+		path TEXT NOT NULL,
+		hotkey TEXT UNIQUE,
+		mode TEXT CHECK(mode IN ('default', 'desktop')),
+		enabled BOOLEAN
+	);`)
+	if err != nil {
+		t.Fatalf("Failed to create legacy table: %v", err)
+	}
+
+	if err := db.Insert("App1", "/usr/bin/app1", "app1", sql.NullString{}, "default", true); err != nil {
+		t.Fatalf("Failed to insert legacy row: %v", err)
+	}
+
+	if err := db.Init(); err != nil {
+		t.Fatalf("Expected Init to migrate legacy schema, got %v", err)
+	}
+
+	settings, err := db.GetAllSettings()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(settings) != 1 {
+		t.Fatalf("Expected 1 setting, got %d", len(settings))
+	}
+	if settings[0].Title() != "App1" {
+		t.Errorf("Expected title to fall back to %q, got %q", "App1", settings[0].Title())
+	}
+
+	// The UNIQUE constraint on hotkeys is replaced by the per scope indexes
+	if unique, err := db.hasUniqueConstraint(); err != nil || unique {
+		t.Errorf("Expected the UNIQUE constraint to be dropped, got %v, %v", unique, err)
+	}
+}
+
+func TestSwapHotkeys(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "App1", Path: "/usr/bin/app1"},
+		{Name: "App2", Path: "/usr/bin/app2"},
+	})
+	a, b := settings[0].Id, settings[1].Id
+
+	if err := db.UpdateHotkey(a, sql.NullString{String: "command+a", Valid: true}); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+	if err := db.UpdateHotkey(b, sql.NullString{String: "command+b", Valid: true}); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+
+	if err := db.SwapHotkeys(a, b); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	swapped, err := db.GetAllSettings()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if swapped[0].HotKey.String != "command+b" || swapped[1].HotKey.String != "command+a" {
+		t.Errorf("Expected hotkeys to be swapped, got %q and %q", swapped[0].HotKey.String, swapped[1].HotKey.String)
+	}
+}
+
+func TestSwapHotkeysWithEmptyHotkey(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "App1", Path: "/usr/bin/app1"},
+		{Name: "App2", Path: "/usr/bin/app2"},
+	})
+	a, b := settings[0].Id, settings[1].Id
+
+	if err := db.UpdateHotkey(b, sql.NullString{String: "command+b", Valid: true}); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+
+	if err := db.SwapHotkeys(a, b); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	swapped, err := db.GetAllSettings()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if swapped[0].HotKey.String != "command+b" {
+		t.Errorf("Expected App1 to get command+b, got %q", swapped[0].HotKey.String)
+	}
+	if swapped[1].HotKey.Valid {
+		t.Errorf("Expected App2 to have no hotkey, got %q", swapped[1].HotKey.String)
+	}
+}
+
+func TestReassignHotkey(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "App1", Path: "/usr/bin/app1"},
+		{Name: "App2", Path: "/usr/bin/app2"},
+	})
+	a, b := settings[0].Id, settings[1].Id
+
+	if err := db.UpdateHotkey(a, sql.NullString{String: "command+a", Valid: true}); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+
+	if err := db.ReassignHotkey(a, b, "command+a"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	s, err := db.FindByHotkey("command+a")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if s == nil || s.Id != b {
+		t.Errorf("Expected command+a to belong to App2, got %v", s)
+	}
+}
+
 func TestGetUpdatedSettingsEmpty(t *testing.T) {
 	db := setupTestDatabase(t)
 	defer db.Close()
@@ -376,3 +643,133 @@ func TestGetUpdatedSettingsReturnsAll(t *testing.T) {
 		t.Fatalf("Expected 3 settings, got %d", len(settings))
 	}
 }
+
+func TestFindByHotkeyInPrecedence(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "Firefox", Path: "/usr/bin/firefox"},
+		{Name: "Notes", Path: "/usr/bin/notes"},
+		{Name: "Terminal", Path: "/usr/bin/terminal"},
+	})
+	firefox, notes, terminal := settings[0].Id, settings[1].Id, settings[2].Id
+
+	bind := func(id int, scope Scope) {
+		t.Helper()
+		if err := db.SetScope(id, scope); err != nil {
+			t.Fatalf("Failed to set scope: %v", err)
+		}
+		if err := db.UpdateHotkey(id, sql.NullString{String: "command+a", Valid: true}); err != nil {
+			t.Fatalf("Failed to update hotkey: %v", err)
+		}
+	}
+	bind(firefox, Scope{})
+	bind(notes, Scope{Only: []string{"com.apple.Safari"}})
+	bind(terminal, Scope{Only: []string{"com.apple.Terminal"}})
+
+	tests := []struct {
+		bundleId string
+		expected int
+	}{
+		{"", firefox},
+		{"com.apple.Finder", firefox},
+		{"com.apple.Safari", notes},
+		{"com.apple.Terminal", terminal},
+	}
+	for _, tc := range tests {
+		s, err := db.FindByHotkeyIn("command+a", tc.bundleId)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if s == nil || s.Id != tc.expected {
+			t.Errorf("FindByHotkeyIn(%q) = %v, want setting %d", tc.bundleId, s, tc.expected)
+		}
+	}
+
+	// A disabled scoped binding falls back to the global one
+	if err := db.UpdateEnabled(notes, false); err != nil {
+		t.Fatalf("Failed to disable: %v", err)
+	}
+	if s, _ := db.FindByHotkeyIn("command+a", "com.apple.Safari"); s == nil || s.Id != firefox {
+		t.Errorf("Expected the global binding while the scoped one is disabled, got %v", s)
+	}
+}
+
+func TestFindByHotkeyForLooksUpFrontmostOnlyForScopedBindings(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "Firefox", Path: "/usr/bin/firefox"},
+		{Name: "Notes", Path: "/usr/bin/notes"},
+	})
+	if err := db.UpdateHotkey(settings[0].Id, sql.NullString{String: "command+a", Valid: true}); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+	if err := db.SetScope(settings[1].Id, Scope{Except: []string{"com.apple.Safari"}}); err != nil {
+		t.Fatalf("Failed to set scope: %v", err)
+	}
+	if err := db.UpdateHotkey(settings[1].Id, sql.NullString{String: "command+b", Valid: true}); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+
+	calls := 0
+	frontmost := func() string {
+		calls++
+		return "com.apple.Safari"
+	}
+
+	for _, hotkey := range []string{"command+a", "command+z"} {
+		if _, err := db.FindByHotkeyFor(hotkey, frontmost); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("Expected no frontmost lookup without scoped bindings, got %d", calls)
+	}
+
+	s, err := db.FindByHotkeyFor("command+b", frontmost)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if s != nil {
+		t.Errorf("Expected the binding not to apply in Safari, got %v", s)
+	}
+	if calls != 1 {
+		t.Errorf("Expected a single frontmost lookup, got %d", calls)
+	}
+}
+
+func TestHotkeyUniquePerScope(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "App1", Path: "/usr/bin/app1"},
+		{Name: "App2", Path: "/usr/bin/app2"},
+	})
+	a, b := settings[0].Id, settings[1].Id
+
+	if err := db.UpdateHotkey(a, sql.NullString{String: "command+a", Valid: true}); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+	if err := db.SetScope(b, Scope{Except: []string{"com.apple.Terminal"}}); err != nil {
+		t.Fatalf("Failed to set scope: %v", err)
+	}
+	if err := db.UpdateHotkey(b, sql.NullString{String: "command+a", Valid: true}); err == nil {
+		t.Fatal("Expected two bindings applying everywhere to be rejected")
+	}
+
+	if err := db.SetScope(b, Scope{Only: []string{"com.apple.Terminal"}}); err != nil {
+		t.Fatalf("Failed to set scope: %v", err)
+	}
+	if err := db.UpdateHotkey(b, sql.NullString{String: "command+a", Valid: true}); err != nil {
+		t.Fatalf("Expected a scoped binding to share the hotkey, got %v", err)
+	}
+
+	err := db.SetScope(b, Scope{})
+	if err == nil || err.Error() != "the hotkey is already bound everywhere by another app" {
+		t.Errorf("Expected making the scoped binding global to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// CheckStatus is the outcome of a doctor check
+type CheckStatus int
+
+const (
+	CheckOK CheckStatus = iota
+	CheckWarning
+	CheckFailed
+)
+
+func (s CheckStatus) String() string {
+	switch s {
+	case CheckOK:
+		return "ok"
+	case CheckWarning:
+		return "warning"
+	default:
+		return "failed"
+	}
+}
+
+// Check is the result of checking one thing Yay depends on, with how to fix
+// it when it isn't right.
+type Check struct {
+	Name   string
+	Status CheckStatus
+	Detail string
+	Fix    string // empty when the check passed
+}
+
+// Integrity runs SQLite's integrity check on the database.
+func (d *Database) Integrity() error {
+	var result string
+	if err := d.conn.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return err
+	}
+	if result != "ok" {
+		return errors.New(result)
+	}
+	return nil
+}
+
+// CheckDatabase checks that the database at path can be opened, is intact
+// and holds the scanned applications.
+func CheckDatabase(path string) Check {
+	check := Check{Name: "Database"}
+	rebuild := fmt.Sprintf("Move %s aside and run yay to rebuild it, hotkeys will need to be set again", path)
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		check.Status = CheckWarning
+		check.Detail = path + " doesn't exist yet"
+		check.Fix = "Run yay to scan your applications"
+		return check
+	}
+
+	db, err := NewDatabase(path)
+	if err != nil {
+		check.Status = CheckFailed
+		check.Detail = err.Error()
+		check.Fix = rebuild
+		return check
+	}
+	defer db.Close()
+
+	if err := db.Integrity(); err != nil {
+		check.Status = CheckFailed
+		check.Detail = fmt.Sprintf("%s is damaged: %v", path, err)
+		check.Fix = rebuild
+		return check
+	}
+	if err := db.Init(); err != nil {
+		check.Status = CheckFailed
+		check.Detail = fmt.Sprintf("%s can't be updated: %v", path, err)
+		check.Fix = rebuild
+		return check
+	}
+
+	settings, err := db.GetAllSettings()
+	if err != nil {
+		check.Status = CheckFailed
+		check.Detail = err.Error()
+		check.Fix = rebuild
+		return check
+	}
+	if len(settings) == 0 {
+		check.Status = CheckWarning
+		check.Detail = path + " has no applications"
+		check.Fix = "Run yay to scan your applications"
+		return check
+	}
+
+	check.Detail = fmt.Sprintf("%s, %d applications", path, len(settings))
+	return check
+}
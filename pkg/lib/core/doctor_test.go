@@ -0,0 +1,71 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckDatabaseMissing(t *testing.T) {
+	check := CheckDatabase(filepath.Join(t.TempDir(), "yay.db"))
+	if check.Status != CheckWarning {
+		t.Errorf("Expected a warning for a missing database, got %s", check.Status)
+	}
+	if check.Fix == "" {
+		t.Error("Expected a fix")
+	}
+}
+
+func TestCheckDatabaseEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "yay.db")
+	db, err := NewDatabase(path)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	db.Close()
+
+	check := CheckDatabase(path)
+	if check.Status != CheckWarning || !strings.Contains(check.Detail, "no applications") {
+		t.Errorf("Expected a warning about no applications, got %s: %s", check.Status, check.Detail)
+	}
+}
+
+func TestCheckDatabaseOK(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "yay.db")
+	db, err := NewDatabase(path)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	seedApps(t, db, []App{{Name: "Safari.app", BinName: "Safari", Path: "/Applications/Safari.app"}})
+	db.Close()
+
+	check := CheckDatabase(path)
+	if check.Status != CheckOK {
+		t.Fatalf("Expected ok, got %s: %s", check.Status, check.Detail)
+	}
+	if !strings.Contains(check.Detail, "1 applications") || check.Fix != "" {
+		t.Errorf("Unexpected result %+v", check)
+	}
+}
+
+func TestCheckDatabaseCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "yay.db")
+	if err := os.WriteFile(path, []byte("not a database, just some text long enough to have a header"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	check := CheckDatabase(path)
+	if check.Status != CheckFailed {
+		t.Errorf("Expected a corrupt database to fail, got %s: %s", check.Status, check.Detail)
+	}
+	if !strings.Contains(check.Fix, path) {
+		t.Errorf("Expected the fix to name the database, got %q", check.Fix)
+	}
+}
@@ -3,19 +3,41 @@ package core
 import "database/sql"
 
 type App struct {
-	Name    string
-	BinName string
-	Path    string
+	Name        string
+	BinName     string
+	Path        string
+	DisplayName string // CFBundleDisplayName, falling back to CFBundleName
+	Version     string // CFBundleShortVersionString
+	BundleId    string // CFBundleIdentifier
+	IconPath    string // resolved from CFBundleIconFile
+	Category    string // LSApplicationCategoryType
 }
 
 type Setting struct {
-	Id      int
-	Name    string
-	BinName string
-	Path    string
-	HotKey  sql.NullString
-	Mode    string
-	Enabled bool
+	Id          int
+	Name        string
+	BinName     string
+	Path        string
+	HotKey      sql.NullString
+	Mode        string
+	Enabled     bool
+	DisplayName string
+	Version     string
+	BundleId    string
+	IconPath    string
+	Category    string
+	Scope       string   // where the hotkey applies, see ParseScope
+	Tags        []string // user tags and the category tag, sorted
+	Usage       Usage
+}
+
+// Title returns the name the application presents to the user, falling back
+// to the bundle file name when the bundle doesn't declare one.
+func (s Setting) Title() string {
+	if s.DisplayName != "" {
+		return s.DisplayName
+	}
+	return s.Name
 }
 
 type Update struct {
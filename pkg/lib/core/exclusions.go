@@ -0,0 +1,62 @@
+package core
+
+import "database/sql"
+
+// Hotkeys pass through to the frontmost app when it is excluded, e.g. a
+// terminal or an IDE with its own shortcuts.
+func (d *Database) createExclusions() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS exclusions (
+		setting_id INTEGER PRIMARY KEY REFERENCES settings(id) ON DELETE CASCADE
+	);`
+	_, err := d.conn.Exec(query)
+	return err
+}
+
+// Exclude lets hotkeys pass through while the app is frontmost.
+func (d *Database) Exclude(settingId int) error {
+	_, err := d.conn.Exec("INSERT OR IGNORE INTO exclusions (setting_id) VALUES (?)", settingId)
+	return err
+}
+
+// Include handles hotkeys again while the app is frontmost.
+func (d *Database) Include(settingId int) error {
+	_, err := d.conn.Exec("DELETE FROM exclusions WHERE setting_id = ?", settingId)
+	return err
+}
+
+// GetExclusions returns the excluded apps sorted by name.
+func (d *Database) GetExclusions() ([]Setting, error) {
+	query := "SELECT " + settingColumns + ` FROM settings
+	WHERE id IN (SELECT setting_id FROM exclusions)
+	ORDER BY name ASC`
+	rows, err := d.conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := []Setting{}
+	for rows.Next() {
+		s, err := scanSetting(rows)
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, s)
+	}
+	return settings, rows.Err()
+}
+
+// IsExcludedPath reports whether the app whose binaries are in path, the
+// Path of its setting, is excluded.
+func (d *Database) IsExcludedPath(path string) (bool, error) {
+	query := `
+	SELECT 1 FROM exclusions JOIN settings ON settings.id = exclusions.setting_id
+	WHERE settings.path = ? LIMIT 1`
+	var one int
+	err := d.conn.QueryRow(query, path).Scan(&one)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
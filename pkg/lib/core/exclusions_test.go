@@ -0,0 +1,64 @@
+package core
+
+import "testing"
+
+func TestExclusions(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "Terminal", Path: "/System/Applications/Utilities/Terminal.app/Contents/MacOS"},
+		{Name: "Safari", Path: "/Applications/Safari.app/Contents/MacOS"},
+	})
+
+	// Refresh returns the apps sorted by name, Terminal comes second
+	if err := db.Exclude(settings[1].Id); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Excluding twice is fine
+	if err := db.Exclude(settings[1].Id); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	excluded, err := db.GetExclusions()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(excluded) != 1 || excluded[0].Name != "Terminal" {
+		t.Errorf("Expected Terminal to be excluded, got %v", excluded)
+	}
+
+	ok, err := db.IsExcludedPath("/System/Applications/Utilities/Terminal.app/Contents/MacOS")
+	if err != nil || !ok {
+		t.Errorf("Expected Terminal's path to be excluded, got %v, %v", ok, err)
+	}
+	if ok, _ := db.IsExcludedPath("/Applications/Safari.app/Contents/MacOS"); ok {
+		t.Error("Expected Safari not to be excluded")
+	}
+
+	if err := db.Include(settings[1].Id); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ok, _ := db.IsExcludedPath("/System/Applications/Utilities/Terminal.app/Contents/MacOS"); ok {
+		t.Error("Expected Terminal to be included again")
+	}
+}
+
+func TestExclusionsRemovedWithApp(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{{Name: "Terminal", Path: "/System/Applications/Utilities/Terminal.app/Contents/MacOS"}})
+	if err := db.Exclude(settings[0].Id); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	seedApps(t, db, []App{})
+
+	var count int
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM exclusions").Scan(&count); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected the exclusion to be removed with the app, got %d", count)
+	}
+}
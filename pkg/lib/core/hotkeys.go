@@ -0,0 +1,209 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// PauseToggleHotkey pauses every other hotkey, or resumes them when paused
+const PauseToggleHotkey = "command+shift+esc"
+
+// How often listeners pick up pauses made by another process
+const pauseRefreshInterval = 5 * time.Second
+
+// ErrUnboundHotkey is returned when triggering a hotkey no enabled app or
+// listener action is bound to.
+var ErrUnboundHotkey = errors.New("nothing is bound to this hotkey")
+
+// ErrFrontmostUnknown is returned for hotkeys with app scoped bindings on
+// platforms that can't tell the frontmost app.
+var ErrFrontmostUnknown = errors.New("app scoped bindings and exclusions aren't supported on this platform")
+
+// SuspendedError is returned when triggering a bound hotkey that passes
+// through right now, e.g. while hotkeys are paused.
+type SuspendedError struct {
+	Reason string // as returned by SuspendReason
+}
+
+func (e *SuspendedError) Error() string {
+	return "hotkeys pass through, " + e.Reason
+}
+
+// HotkeyAction is what pressing a hotkey does
+type HotkeyAction struct {
+	Desc    string
+	Run     func() error
+	Consume bool // swallow the key event so the frontmost app doesn't get it
+}
+
+// Hotkeys handles what the listeners of every platform share: the pause
+// toggle, opening the apps bound in the settings and telling when hotkeys
+// pass through. The zero value of the unexported fields is ready to use.
+type Hotkeys struct {
+	DB *Database
+	// Launch opens the app of a setting in its mode
+	Launch func(Setting) error
+	// Log receives what the actions report, with err set when something
+	// went wrong. It may be nil.
+	Log func(msg string, err error)
+
+	pause     PauseState
+	mu        sync.Mutex
+	suspended string // last reason hotkeys passed through, to log changes
+}
+
+func (h *Hotkeys) log(msg string, err error) {
+	if h.Log != nil {
+		h.Log(msg, err)
+	}
+}
+
+// Resolve looks up what pressing hotkey does while the app whose bundle id
+// bundleId returns is frontmost. bundleId is only called for scoped bindings,
+// and is nil on platforms that can't tell the frontmost app, which makes
+// hotkeys with scoped bindings fail with ErrFrontmostUnknown. ok is false when
+// neither the pause toggle nor an enabled app is bound to hotkey.
+func (h *Hotkeys) Resolve(hotkey string, bundleId func() string) (HotkeyAction, bool, error) {
+	if action, ok := h.Reserved(hotkey); ok {
+		return action, true, nil
+	}
+
+	unknown := false
+	frontmost := bundleId
+	if frontmost == nil {
+		frontmost = func() string {
+			unknown = true
+			return ""
+		}
+	}
+	setting, err := h.DB.FindByHotkeyFor(hotkey, frontmost)
+	if err != nil {
+		return HotkeyAction{}, false, err
+	}
+	if unknown {
+		return HotkeyAction{}, false, ErrFrontmostUnknown
+	}
+	if setting == nil || !setting.Enabled {
+		return HotkeyAction{}, false, nil
+	}
+
+	s := *setting
+	return HotkeyAction{
+		Desc:    "open " + s.Title(),
+		Run:     func() error { return h.LaunchSetting(s) },
+		Consume: true,
+	}, true, nil
+}
+
+// Reserved returns what pressing hotkey does when every listener handles it
+// itself rather than looking it up in the settings, which is only the pause
+// toggle. Its Desc can be read from a zero Hotkeys.
+func (h *Hotkeys) Reserved(hotkey string) (HotkeyAction, bool) {
+	if hotkey != PauseToggleHotkey {
+		return HotkeyAction{}, false
+	}
+	return HotkeyAction{
+		Desc:    "pause or resume hotkeys",
+		Run:     h.togglePause,
+		Consume: true,
+	}, true
+}
+
+func (h *Hotkeys) togglePause() error {
+	paused, err := h.pause.Toggle(h.DB, time.Now())
+	if err != nil {
+		return err
+	}
+	if paused {
+		h.log("Hotkeys paused, press "+PauseToggleHotkey+" to resume", nil)
+	} else {
+		h.log("Hotkeys resumed", nil)
+	}
+	return nil
+}
+
+// LaunchSetting opens the app of setting in its mode and records the launch.
+func (h *Hotkeys) LaunchSetting(setting Setting) error {
+	if err := h.Launch(setting); err != nil {
+		return err
+	}
+	if err := h.DB.RecordLaunch(setting.Id, time.Now()); err != nil {
+		return fmt.Errorf("recording launch: %w", err)
+	}
+	return nil
+}
+
+// WatchPause reads whether hotkeys are paused now and every few seconds
+// until done is closed, so pauses made by another process, e.g. yay pause,
+// are picked up without querying the database on every key press.
+func (h *Hotkeys) WatchPause(done <-chan struct{}) {
+	if err := h.RefreshPause(time.Now()); err != nil {
+		h.log("Couldn't check whether hotkeys are paused", err)
+	}
+	go func() {
+		ticker := time.NewTicker(pauseRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				if err := h.RefreshPause(now); err != nil {
+					h.log("Couldn't check whether hotkeys are paused", err)
+				}
+			}
+		}
+	}()
+}
+
+// RefreshPause reads whether hotkeys are paused, for SuspendReason. Listeners
+// call WatchPause instead.
+func (h *Hotkeys) RefreshPause(now time.Time) error {
+	return h.pause.Refresh(h.DB, now)
+}
+
+// SuspendReason reports why a bound hotkey passes through right now: hotkeys
+// are paused or the frontmost app, whose executable path frontmost returns,
+// is excluded. frontmost is only called when hotkeys aren't paused, and is nil
+// on platforms that can't tell the frontmost app. It is empty when the hotkey
+// is handled.
+func (h *Hotkeys) SuspendReason(now time.Time, frontmost func() string) (string, error) {
+	until, paused := h.pause.PausedUntil(now)
+	if paused && until.IsZero() {
+		return "paused", nil
+	}
+	if paused {
+		return "paused until " + until.Format("15:04"), nil
+	}
+
+	if frontmost == nil {
+		return "", nil
+	}
+	path := frontmost()
+	if path == "" {
+		return "", nil
+	}
+	excluded, err := h.DB.IsExcludedPath(filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+	if excluded {
+		return filepath.Base(path) + " is excluded", nil
+	}
+	return "", nil
+}
+
+// PassThrough reports whether hotkeys pass through for reason, a
+// SuspendReason or a platform specific one, logging when it changes.
+func (h *Hotkeys) PassThrough(reason string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if reason != h.suspended && reason != "" {
+		h.log("Hotkeys pass through, "+reason, nil)
+	}
+	h.suspended = reason
+	return reason != ""
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHotkeysPauseToggle(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	var logged []string
+	h := &Hotkeys{DB: db, Log: func(msg string, err error) { logged = append(logged, msg) }}
+
+	action, ok, err := h.Resolve(PauseToggleHotkey, nil)
+	if err != nil || !ok || !action.Consume {
+		t.Fatalf("Expected the pause toggle to be bound, got %v, %v, %v", ok, action.Consume, err)
+	}
+	if err := action.Run(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, paused, _ := db.PausedUntil(time.Now()); !paused {
+		t.Error("Expected the toggle to pause hotkeys")
+	}
+	if reason, _ := h.SuspendReason(time.Now(), nil); reason != "paused" {
+		t.Errorf("Expected hotkeys to be suspended, got %q", reason)
+	}
+	if err := action.Run(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, paused, _ := db.PausedUntil(time.Now()); paused {
+		t.Error("Expected the toggle to resume hotkeys")
+	}
+	if len(logged) != 2 || logged[1] != "Hotkeys resumed" {
+		t.Errorf("Expected the toggles to be logged, got %q", logged)
+	}
+}
+
+func TestHotkeysLaunchRecordsUsage(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{{Name: "Firefox", Path: "/usr/bin/firefox"}})
+	if err := db.UpdateHotkey(settings[0].Id, sql.NullString{String: "command+1", Valid: true}); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+
+	var launched []string
+	h := &Hotkeys{DB: db, Launch: func(s Setting) error {
+		launched = append(launched, s.Name)
+		return nil
+	}}
+
+	action, ok, err := h.Resolve("command+1", nil)
+	if err != nil || !ok || action.Desc != "open Firefox" {
+		t.Fatalf("Resolve = %q, %v, %v, want open Firefox", action.Desc, ok, err)
+	}
+	if err := action.Run(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(launched) != 1 {
+		t.Errorf("Expected Firefox to be launched, got %v", launched)
+	}
+	usage, _ := db.GetUsage()
+	if usage[settings[0].Id].LaunchCount != 1 {
+		t.Errorf("Expected the launch to be recorded, got %v", usage[settings[0].Id])
+	}
+
+	// A failed launch isn't recorded
+	h.Launch = func(Setting) error { return errors.New("no display") }
+	if err := action.Run(); err == nil {
+		t.Error("Expected the launch error")
+	}
+	usage, _ = db.GetUsage()
+	if usage[settings[0].Id].LaunchCount != 1 {
+		t.Errorf("Expected the failed launch not to be recorded, got %v", usage[settings[0].Id])
+	}
+}
+
+func TestHotkeysScopedWithoutFrontmost(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{{Name: "Notes", Path: "/usr/bin/notes"}})
+	if err := db.SetScope(settings[0].Id, Scope{Only: []string{"com.apple.Safari"}}); err != nil {
+		t.Fatalf("Failed to set scope: %v", err)
+	}
+	if err := db.UpdateHotkey(settings[0].Id, sql.NullString{String: "command+1", Valid: true}); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+
+	h := &Hotkeys{DB: db}
+	if _, _, err := h.Resolve("command+1", nil); !errors.Is(err, ErrFrontmostUnknown) {
+		t.Errorf("Expected ErrFrontmostUnknown, got %v", err)
+	}
+	action, ok, err := h.Resolve("command+1", func() string { return "com.apple.Safari" })
+	if err != nil || !ok || action.Desc != "open Notes" {
+		t.Errorf("Resolve in Safari = %q, %v, %v, want open Notes", action.Desc, ok, err)
+	}
+}
+
+func TestHotkeysSuspendReason(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{{Name: "Terminal", Path: "/System/Applications/Utilities/Terminal.app/Contents/MacOS"}})
+	h := &Hotkeys{DB: db}
+
+	frontmost := "/Applications/Safari.app/Contents/MacOS/Safari"
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	check := func(expected string) {
+		t.Helper()
+		if err := h.pause.Refresh(db, now); err != nil {
+			t.Fatalf("Failed to refresh the pause: %v", err)
+		}
+		reason, err := h.SuspendReason(now, func() string { return frontmost })
+		if err != nil {
+			t.Fatalf("SuspendReason returned error: %v", err)
+		}
+		if reason != expected {
+			t.Errorf("SuspendReason = %q, want %q", reason, expected)
+		}
+	}
+
+	check("")
+
+	if err := db.Exclude(settings[0].Id); err != nil {
+		t.Fatalf("Failed to exclude: %v", err)
+	}
+	check("")
+	frontmost = "/System/Applications/Utilities/Terminal.app/Contents/MacOS/Terminal"
+	check("Terminal is excluded")
+
+	if err := db.Pause(now.Add(30 * time.Minute)); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+	check("paused until 12:30")
+	if err := db.Pause(time.Time{}); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+	check("paused")
+}
+
+func TestHotkeysPassThroughLogsChanges(t *testing.T) {
+	var logged []string
+	h := &Hotkeys{Log: func(msg string, err error) { logged = append(logged, msg) }}
+
+	for _, reason := range []string{"", "paused", "paused", "", "Terminal is excluded"} {
+		if got := h.PassThrough(reason); got != (reason != "") {
+			t.Errorf("PassThrough(%q) = %v", reason, got)
+		}
+	}
+	expected := []string{"Hotkeys pass through, paused", "Hotkeys pass through, Terminal is excluded"}
+	if len(logged) != len(expected) || logged[0] != expected[0] || logged[1] != expected[1] {
+		t.Errorf("Expected changes to be logged once, got %q", logged)
+	}
+}
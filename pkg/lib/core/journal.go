@@ -0,0 +1,346 @@
+package core
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Setting fields recorded in the change journal
+const (
+	FieldHotkey  = "hotkey"
+	FieldMode    = "mode"
+	FieldEnabled = "enabled"
+	FieldScope   = "scope"
+)
+
+// Number of edits kept in the journal
+const maxJournalBatches = 500
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// Change is a single field edit of a setting. Values are stored as text, a
+// missing hotkey is an invalid NullString and enabled is "true" or "false".
+type Change struct {
+	SettingId int
+	Name      string // title of the app, filled in when read from the journal
+	Field     string
+	Old       sql.NullString
+	New       sql.NullString
+	Time      time.Time // when the edit was made, filled in when read from the journal
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s %s %s → %s", c.Name, c.Field, formatChangeValue(c.Old), formatChangeValue(c.New))
+}
+
+func formatChangeValue(v sql.NullString) string {
+	if !v.Valid || v.String == "" {
+		return "none"
+	}
+	return v.String
+}
+
+func enabledValue(enabled bool) sql.NullString {
+	if enabled {
+		return sql.NullString{String: "true", Valid: true}
+	}
+	return sql.NullString{String: "false", Valid: true}
+}
+
+// Columns of the changes table
+const changesTableColumns = `
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		batch INTEGER NOT NULL,
+		setting_id INTEGER NOT NULL REFERENCES settings(id) ON DELETE CASCADE,
+		field TEXT NOT NULL CHECK(field IN ('hotkey', 'mode', 'enabled', 'scope')),
+		old_value TEXT,
+		new_value TEXT,
+		undone BOOLEAN NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP`
+
+func (d *Database) createJournal() error {
+	// Changes sharing a batch were made by one edit and are undone together
+	_, err := d.conn.Exec("CREATE TABLE IF NOT EXISTS changes (" + changesTableColumns + ");")
+	if err != nil {
+		return err
+	}
+
+	// Journals created before scopes were journaled only accept the other
+	// fields
+	var schema string
+	if err := d.conn.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'changes'").Scan(&schema); err != nil {
+		return err
+	}
+	if strings.Contains(schema, "'"+FieldScope+"'") {
+		return nil
+	}
+	return d.rebuildJournal()
+}
+
+// rebuildJournal copies the journal into a table with the current schema.
+func (d *Database) rebuildJournal() error {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		"CREATE TABLE changes_rebuild (" + changesTableColumns + ")",
+		"INSERT INTO changes_rebuild SELECT * FROM changes",
+		"DROP TABLE changes",
+		"ALTER TABLE changes_rebuild RENAME TO changes",
+	} {
+		if _, err := tx.Exec(query); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// edit applies the changes returned by build in a single transaction and
+// records them in the journal as one undoable batch. Any undone edits are
+// dropped, as with any undo history.
+func (d *Database) edit(build func(tx *sql.Tx) ([]Change, error)) error {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	changes, err := build(tx)
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+
+	for i := range changes {
+		old, err := readField(tx, changes[i].SettingId, changes[i].Field)
+		if err != nil {
+			return err
+		}
+		changes[i].Old = old
+	}
+
+	if err := writeChanges(tx, changes, func(c Change) sql.NullString { return c.New }); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM changes WHERE undone = 1"); err != nil {
+		return err
+	}
+
+	var batch int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(batch), 0) + 1 FROM changes").Scan(&batch); err != nil {
+		return err
+	}
+	for _, c := range changes {
+		_, err := tx.Exec(
+			"INSERT INTO changes (batch, setting_id, field, old_value, new_value) VALUES (?, ?, ?, ?, ?)",
+			batch, c.SettingId, c.Field, c.Old, c.New,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM changes WHERE batch <= ?", batch-maxJournalBatches); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// editAll sets field to value on every setting in ids as one edit.
+func (d *Database) editAll(ids []int, field string, value sql.NullString) error {
+	return d.edit(func(tx *sql.Tx) ([]Change, error) {
+		changes := make([]Change, 0, len(ids))
+		for _, id := range ids {
+			changes = append(changes, Change{SettingId: id, Field: field, New: value})
+		}
+		return changes, nil
+	})
+}
+
+// Undo reverts the most recent edit and returns its changes.
+func (d *Database) Undo() ([]Change, error) {
+	return d.replay("SELECT MAX(batch) FROM changes WHERE undone = 0", true)
+}
+
+// Redo reapplies the most recently undone edit and returns its changes.
+func (d *Database) Redo() ([]Change, error) {
+	return d.replay("SELECT MIN(batch) FROM changes WHERE undone = 1", false)
+}
+
+// replay undoes or redoes the journal batch selected by batchQuery.
+func (d *Database) replay(batchQuery string, undo bool) ([]Change, error) {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var batch sql.NullInt64
+	if err := tx.QueryRow(batchQuery).Scan(&batch); err != nil {
+		return nil, err
+	}
+	if !batch.Valid {
+		if undo {
+			return nil, ErrNothingToUndo
+		}
+		return nil, ErrNothingToRedo
+	}
+
+	changes, err := readBatch(tx, batch.Int64)
+	if err != nil {
+		return nil, err
+	}
+
+	if undo {
+		// Restore in reverse so a setting edited twice ends at its first value
+		reversed := make([]Change, len(changes))
+		for i, c := range changes {
+			reversed[len(changes)-1-i] = c
+		}
+		err = writeChanges(tx, reversed, func(c Change) sql.NullString { return c.Old })
+	} else {
+		err = writeChanges(tx, changes, func(c Change) sql.NullString { return c.New })
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("UPDATE changes SET undone = ? WHERE batch = ?", undo, batch.Int64); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// HistoryRevision returns the id of the latest edit History returns for one
+// field of a setting and how many there are. Every edit, undo and redo of the
+// field changes them, so History can be cached while they stay the same.
+func (d *Database) HistoryRevision(settingId int, field string) (latest int64, count int, err error) {
+	err = d.conn.QueryRow(
+		"SELECT COALESCE(MAX(id), 0), COUNT(*) FROM changes WHERE setting_id = ? AND field = ? AND undone = 0",
+		settingId, field,
+	).Scan(&latest, &count)
+	return latest, count, err
+}
+
+// History returns the edits of one field of a setting, newest first. Undone
+// edits are left out.
+func (d *Database) History(settingId int, field string, limit int) ([]Change, error) {
+	rows, err := d.conn.Query(
+		`SELECT c.setting_id, COALESCE(NULLIF(s.display_name, ''), s.name, ''), c.field, c.old_value, c.new_value, c.created_at
+		FROM changes c LEFT JOIN settings s ON s.id = c.setting_id
+		WHERE c.setting_id = ? AND c.field = ? AND c.undone = 0
+		ORDER BY c.id DESC LIMIT ?`,
+		settingId, field, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []Change
+	for rows.Next() {
+		var c Change
+		if err := rows.Scan(&c.SettingId, &c.Name, &c.Field, &c.Old, &c.New, &c.Time); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+func readBatch(tx *sql.Tx, batch int64) ([]Change, error) {
+	rows, err := tx.Query(
+		`SELECT c.setting_id, COALESCE(NULLIF(s.display_name, ''), s.name, ''), c.field, c.old_value, c.new_value
+		FROM changes c LEFT JOIN settings s ON s.id = c.setting_id
+		WHERE c.batch = ? ORDER BY c.id`,
+		batch,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []Change
+	for rows.Next() {
+		var c Change
+		if err := rows.Scan(&c.SettingId, &c.Name, &c.Field, &c.Old, &c.New); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+func readField(tx *sql.Tx, id int, field string) (sql.NullString, error) {
+	var value sql.NullString
+	switch field {
+	case FieldHotkey:
+		err := tx.QueryRow("SELECT hotkey FROM settings WHERE id = ?", id).Scan(&value)
+		return value, err
+	case FieldMode:
+		err := tx.QueryRow("SELECT mode FROM settings WHERE id = ?", id).Scan(&value)
+		return value, err
+	case FieldEnabled:
+		var enabled bool
+		err := tx.QueryRow("SELECT enabled FROM settings WHERE id = ?", id).Scan(&enabled)
+		return enabledValue(enabled), err
+	case FieldScope:
+		err := tx.QueryRow("SELECT scope FROM settings WHERE id = ?", id).Scan(&value)
+		return value, err
+	}
+	return value, fmt.Errorf("unknown field %q", field)
+}
+
+// writeChanges stores the value picked from each change. Hotkeys are cleared
+// first and set last so the unique indexes hold while hotkeys, and the scopes
+// they're unique in, move between settings.
+func writeChanges(tx *sql.Tx, changes []Change, value func(Change) sql.NullString) error {
+	for _, c := range changes {
+		if c.Field != FieldHotkey {
+			continue
+		}
+		if _, err := tx.Exec("UPDATE settings SET hotkey = NULL WHERE id = ?", c.SettingId); err != nil {
+			return err
+		}
+	}
+
+	for _, hotkeys := range []bool{false, true} {
+		for _, c := range changes {
+			if (c.Field == FieldHotkey) != hotkeys {
+				continue
+			}
+			v := value(c)
+			var err error
+			switch c.Field {
+			case FieldHotkey:
+				_, err = tx.Exec("UPDATE settings SET hotkey = ? WHERE id = ?", v, c.SettingId)
+			case FieldMode:
+				_, err = tx.Exec("UPDATE settings SET mode = ? WHERE id = ?", v.String, c.SettingId)
+			case FieldEnabled:
+				_, err = tx.Exec("UPDATE settings SET enabled = ? WHERE id = ?", v.String == "true", c.SettingId)
+			case FieldScope:
+				_, err = tx.Exec("UPDATE settings SET scope = ? WHERE id = ?", v.String, c.SettingId)
+			default:
+				err = fmt.Errorf("unknown field %q", c.Field)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,472 @@
+package core
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func hotkey(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: true}
+}
+
+func settingById(t *testing.T, db *Database, id int) Setting {
+	t.Helper()
+	settings, err := db.GetAllSettings()
+	if err != nil {
+		t.Fatalf("Failed to get settings: %v", err)
+	}
+	for _, s := range settings {
+		if s.Id == id {
+			return s
+		}
+	}
+	t.Fatalf("No setting with id %d", id)
+	return Setting{}
+}
+
+func TestUndoNothing(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	if _, err := db.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+	if _, err := db.Redo(); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("Expected ErrNothingToRedo, got %v", err)
+	}
+}
+
+func TestUndoRedoEdits(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	id := seedApps(t, db, []App{{Name: "App1", Path: "/usr/bin/app1"}})[0].Id
+
+	if err := db.UpdateHotkey(id, hotkey("command+a")); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+	if err := db.UpdateMode(id, "desktop"); err != nil {
+		t.Fatalf("Failed to update mode: %v", err)
+	}
+	if err := db.UpdateEnabled(id, false); err != nil {
+		t.Fatalf("Failed to update enabled: %v", err)
+	}
+
+	changes, err := db.Undo()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(changes) != 1 || changes[0].Field != FieldEnabled || changes[0].Name != "App1" {
+		t.Fatalf("Expected the enabled change, got %v", changes)
+	}
+	if s := settingById(t, db, id); !s.Enabled || s.Mode != "desktop" {
+		t.Errorf("Expected enabled desktop app, got enabled=%v mode=%s", s.Enabled, s.Mode)
+	}
+
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if s := settingById(t, db, id); s.HotKey.Valid || s.Mode != "default" {
+		t.Errorf("Expected original settings, got hotkey=%v mode=%s", s.HotKey, s.Mode)
+	}
+	if _, err := db.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+
+	// Redo replays in the original order
+	changes, err = db.Redo()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if changes[0].Field != FieldHotkey {
+		t.Errorf("Expected the hotkey change to be redone first, got %s", changes[0].Field)
+	}
+	if s := settingById(t, db, id); s.HotKey.String != "command+a" {
+		t.Errorf("Expected command+a, got %q", s.HotKey.String)
+	}
+}
+
+func TestEditDropsRedoHistory(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	id := seedApps(t, db, []App{{Name: "App1", Path: "/usr/bin/app1"}})[0].Id
+
+	if err := db.UpdateMode(id, "desktop"); err != nil {
+		t.Fatalf("Failed to update mode: %v", err)
+	}
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := db.UpdateEnabled(id, false); err != nil {
+		t.Fatalf("Failed to update enabled: %v", err)
+	}
+
+	if _, err := db.Redo(); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("Expected ErrNothingToRedo, got %v", err)
+	}
+}
+
+func TestUndoSwapHotkeys(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "App1", Path: "/usr/bin/app1"},
+		{Name: "App2", Path: "/usr/bin/app2"},
+	})
+	a, b := settings[0].Id, settings[1].Id
+
+	if err := db.UpdateHotkey(a, hotkey("command+a")); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+	if err := db.UpdateHotkey(b, hotkey("command+b")); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+	if err := db.SwapHotkeys(a, b); err != nil {
+		t.Fatalf("Failed to swap hotkeys: %v", err)
+	}
+
+	changes, err := db.Undo()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(changes) != 2 {
+		t.Errorf("Expected the swap to be undone as one edit, got %d changes", len(changes))
+	}
+	if s := settingById(t, db, a); s.HotKey.String != "command+a" {
+		t.Errorf("Expected App1 to have command+a back, got %q", s.HotKey.String)
+	}
+	if s := settingById(t, db, b); s.HotKey.String != "command+b" {
+		t.Errorf("Expected App2 to have command+b back, got %q", s.HotKey.String)
+	}
+}
+
+func TestSwapHotkeysSwapsScopes(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "App1", Path: "/usr/bin/app1"},
+		{Name: "App2", Path: "/usr/bin/app2"},
+		{Name: "App3", Path: "/usr/bin/app3"},
+	})
+	a, b, c := settings[0].Id, settings[1].Id, settings[2].Id
+
+	// App1 has command+a in Safari only, App3 everywhere else
+	if err := db.SetScope(a, Scope{Only: []string{"com.apple.Safari"}}); err != nil {
+		t.Fatalf("Failed to set scope: %v", err)
+	}
+	for id, h := range map[int]string{a: "command+a", b: "command+b", c: "command+a"} {
+		if err := db.UpdateHotkey(id, hotkey(h)); err != nil {
+			t.Fatalf("Failed to update hotkey: %v", err)
+		}
+	}
+
+	if err := db.SwapHotkeys(a, b); err != nil {
+		t.Fatalf("Failed to swap hotkeys: %v", err)
+	}
+	if s := settingById(t, db, b); s.HotKey.String != "command+a" || s.Scope != "only:com.apple.Safari" {
+		t.Errorf("Expected App2 to have command+a in Safari, got %q %q", s.HotKey.String, s.Scope)
+	}
+	if s := settingById(t, db, a); s.HotKey.String != "command+b" || s.Scope != "" {
+		t.Errorf("Expected App1 to have command+b everywhere, got %q %q", s.HotKey.String, s.Scope)
+	}
+
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if s := settingById(t, db, a); s.HotKey.String != "command+a" || s.Scope != "only:com.apple.Safari" {
+		t.Errorf("Expected App1 to have command+a in Safari back, got %q %q", s.HotKey.String, s.Scope)
+	}
+}
+
+func TestInitMigratesJournal(t *testing.T) {
+	db, err := NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Journal as created by versions that didn't journal scopes
+	_, err = db.conn.Exec(`
+	CREATE TABLE changes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		batch INTEGER NOT NULL,
+		setting_id INTEGER NOT NULL REFERENCES settings(id) ON DELETE CASCADE,
+		field TEXT NOT NULL CHECK(field IN ('hotkey', 'mode', 'enabled')),
+		old_value TEXT,
+		new_value TEXT,
+		undone BOOLEAN NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	INSERT INTO changes (batch, setting_id, field, old_value, new_value) VALUES (1, 1, 'mode', 'default', 'desktop');`)
+	if err != nil {
+		t.Fatalf("Failed to create legacy journal: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to init: %v", err)
+	}
+
+	if _, err := db.conn.Exec("INSERT INTO changes (batch, setting_id, field) VALUES (2, 1, 'scope')"); err != nil {
+		t.Errorf("Expected scopes to be journaled, got %v", err)
+	}
+	var count int
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM changes").Scan(&count); err != nil || count != 2 {
+		t.Errorf("Expected the journal to be kept, got %d entries, %v", count, err)
+	}
+}
+
+func TestUndoReassignHotkey(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "App1", Path: "/usr/bin/app1"},
+		{Name: "App2", Path: "/usr/bin/app2"},
+	})
+	a, b := settings[0].Id, settings[1].Id
+
+	if err := db.UpdateHotkey(a, hotkey("command+a")); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+	if err := db.ReassignHotkey(a, b, "command+a"); err != nil {
+		t.Fatalf("Failed to reassign hotkey: %v", err)
+	}
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	s, err := db.FindByHotkey("command+a")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if s == nil || s.Id != a {
+		t.Errorf("Expected command+a to be back on App1, got %v", s)
+	}
+	if s := settingById(t, db, b); s.HotKey.Valid {
+		t.Errorf("Expected App2 without hotkey, got %q", s.HotKey.String)
+	}
+}
+
+func TestJournalIsBounded(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	id := seedApps(t, db, []App{{Name: "App1", Path: "/usr/bin/app1"}})[0].Id
+
+	for i := range maxJournalBatches + 10 {
+		if err := db.UpdateEnabled(id, i%2 == 0); err != nil {
+			t.Fatalf("Failed to update enabled: %v", err)
+		}
+	}
+
+	var count int
+	if err := db.conn.QueryRow("SELECT COUNT(DISTINCT batch) FROM changes").Scan(&count); err != nil {
+		t.Fatalf("Failed to count journal: %v", err)
+	}
+	if count != maxJournalBatches {
+		t.Errorf("Expected %d journal entries, got %d", maxJournalBatches, count)
+	}
+}
+
+func TestRefreshRemovesJournalOfStaleApps(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	id := seedApps(t, db, []App{{Name: "App1", Path: "/usr/bin/app1"}})[0].Id
+	if err := db.UpdateMode(id, "desktop"); err != nil {
+		t.Fatalf("Failed to update mode: %v", err)
+	}
+
+	if _, err := db.Refresh(nil); err != nil {
+		t.Fatalf("Failed to refresh: %v", err)
+	}
+
+	if _, err := db.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+}
+
+func TestRefreshDropsBulkEditsOfStaleApps(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	apps := []App{{Name: "App1", Path: "/usr/bin/app1"}, {Name: "App2", Path: "/usr/bin/app2"}}
+	settings := seedApps(t, db, apps)
+	if err := db.UpdateModeAll([]int{settings[0].Id, settings[1].Id}, "desktop"); err != nil {
+		t.Fatalf("Failed to update modes: %v", err)
+	}
+
+	seedApps(t, db, apps[1:])
+
+	// Undoing only the part of the edit for App2 would be a surprise
+	if _, err := db.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+	if s := settingById(t, db, settings[1].Id); s.Mode != "desktop" {
+		t.Errorf("Expected App2 to stay in desktop mode, got %q", s.Mode)
+	}
+}
+
+func TestChangeString(t *testing.T) {
+	c := Change{Name: "Safari", Field: FieldHotkey, New: hotkey("command+s")}
+	if c.String() != "Safari hotkey none → command+s" {
+		t.Errorf("Unexpected description %q", c.String())
+	}
+}
+
+func TestBulkEditsUndoTogether(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "App1", Path: "/usr/bin/app1"},
+		{Name: "App2", Path: "/usr/bin/app2"},
+		{Name: "App3", Path: "/usr/bin/app3"},
+	})
+	ids := []int{settings[0].Id, settings[1].Id}
+
+	if err := db.UpdateEnabledAll(ids, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, id := range ids {
+		if settingById(t, db, id).Enabled {
+			t.Errorf("Expected setting %d to be disabled", id)
+		}
+	}
+	if !settingById(t, db, settings[2].Id).Enabled {
+		t.Error("Expected unselected setting to stay enabled")
+	}
+
+	changes, err := db.Undo()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(changes) != 2 {
+		t.Errorf("Expected 2 changes undone together, got %d", len(changes))
+	}
+	for _, id := range ids {
+		if !settingById(t, db, id).Enabled {
+			t.Errorf("Expected setting %d to be enabled again", id)
+		}
+	}
+}
+
+func TestBulkModeAndClearHotkeys(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "App1", Path: "/usr/bin/app1"},
+		{Name: "App2", Path: "/usr/bin/app2"},
+	})
+	ids := []int{settings[0].Id, settings[1].Id}
+
+	if err := db.UpdateHotkey(ids[0], hotkey("command+a")); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+	if err := db.UpdateHotkey(ids[1], hotkey("command+b")); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+
+	if err := db.UpdateModeAll(ids, "desktop"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := db.ClearHotkeys(ids); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, id := range ids {
+		s := settingById(t, db, id)
+		if s.Mode != "desktop" || s.HotKey.Valid {
+			t.Errorf("Expected desktop mode without hotkey, got mode=%s hotkey=%v", s.Mode, s.HotKey)
+		}
+	}
+}
+
+func TestBulkEditRollsBackOnError(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{{Name: "App1", Path: "/usr/bin/app1"}})
+
+	// The second id doesn't exist, so nothing may be written
+	if err := db.UpdateEnabledAll([]int{settings[0].Id, 9999}, false); err == nil {
+		t.Fatal("Expected an error for a missing setting")
+	}
+	if !settingById(t, db, settings[0].Id).Enabled {
+		t.Error("Expected the edit to be rolled back")
+	}
+	if _, err := db.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+}
+
+func TestBulkEditEmpty(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	id := seedApps(t, db, []App{{Name: "App1", Path: "/usr/bin/app1"}})[0].Id
+	if err := db.UpdateMode(id, "desktop"); err != nil {
+		t.Fatalf("Failed to update mode: %v", err)
+	}
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := db.UpdateEnabledAll(nil, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := db.Redo(); err != nil {
+		t.Errorf("Expected an empty edit to keep the redo history, got %v", err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	id := seedApps(t, db, []App{{Name: "App1", Path: "/usr/bin/app1"}})[0].Id
+
+	for _, h := range []string{"command+a", "command+b", "command+c"} {
+		if err := db.UpdateHotkey(id, hotkey(h)); err != nil {
+			t.Fatalf("Failed to update hotkey: %v", err)
+		}
+	}
+	if err := db.UpdateMode(id, "desktop"); err != nil {
+		t.Fatalf("Failed to update mode: %v", err)
+	}
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+
+	history, err := db.History(id, FieldHotkey, 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 hotkey edits, got %d", len(history))
+	}
+	if history[0].New.String != "command+b" || history[1].New.String != "command+a" {
+		t.Errorf("Expected newest first, got %v", history)
+	}
+	if history[0].Time.IsZero() {
+		t.Error("Expected the edit time to be set")
+	}
+
+	history, err = db.History(id, FieldHotkey, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(history) != 1 {
+		t.Errorf("Expected the limit to apply, got %d", len(history))
+	}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Preference holding when paused hotkeys resume, as a unix timestamp or 0
+// until resumed by hand
+const pausedUntilKey = "paused_until"
+
+// Pause suspends hotkeys until the given time, or until Resume is called
+// when until is zero.
+func (d *Database) Pause(until time.Time) error {
+	value := "0"
+	if !until.IsZero() {
+		value = strconv.FormatInt(until.Unix(), 10)
+	}
+	return d.SetPreference(pausedUntilKey, value)
+}
+
+// Resume turns paused hotkeys back on.
+func (d *Database) Resume() error {
+	return d.DeletePreference(pausedUntilKey)
+}
+
+// PausedUntil reports whether hotkeys are paused at now, and until when. until
+// is zero when they stay paused until resumed.
+func (d *Database) PausedUntil(now time.Time) (until time.Time, paused bool, err error) {
+	value, ok, err := d.GetPreference(pausedUntilKey)
+	if err != nil || !ok {
+		return time.Time{}, false, err
+	}
+
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		// Written by something else, treat it as not paused
+		return time.Time{}, false, nil
+	}
+	if unix == 0 {
+		return time.Time{}, true, nil
+	}
+
+	until = time.Unix(unix, 0)
+	if !now.Before(until) {
+		return time.Time{}, false, nil
+	}
+	return until, true, nil
+}
+
+// TogglePause pauses hotkeys until resumed, or resumes them when they are
+// paused, and reports whether they are paused now.
+func (d *Database) TogglePause(now time.Time) (bool, error) {
+	_, paused, err := d.PausedUntil(now)
+	if err != nil {
+		return false, err
+	}
+	if paused {
+		return false, d.Resume()
+	}
+	return true, d.Pause(time.Time{})
+}
+
+// PauseState keeps whether hotkeys are paused in memory, so listeners don't
+// query the database on every key press. Refresh picks up pauses made by
+// another process, e.g. yay pause.
+type PauseState struct {
+	mu     sync.Mutex
+	until  time.Time
+	paused bool
+}
+
+// Refresh reads whether hotkeys are paused from the database.
+func (p *PauseState) Refresh(d *Database, now time.Time) error {
+	until, paused, err := d.PausedUntil(now)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.until, p.paused = until, paused
+	return nil
+}
+
+// Toggle pauses hotkeys until resumed, or resumes them, see
+// Database.TogglePause, and reports whether they are paused now.
+func (p *PauseState) Toggle(d *Database, now time.Time) (bool, error) {
+	paused, err := d.TogglePause(now)
+	if err != nil {
+		return false, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.until, p.paused = time.Time{}, paused
+	return paused, nil
+}
+
+// PausedUntil reports whether hotkeys are paused at now as last read, and
+// until when. until is zero when they stay paused until resumed.
+func (p *PauseState) PausedUntil(now time.Time) (until time.Time, paused bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.paused || (!p.until.IsZero() && !now.Before(p.until)) {
+		return time.Time{}, false
+	}
+	return p.until, true
+}
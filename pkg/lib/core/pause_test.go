@@ -0,0 +1,118 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPauseUntilResumed(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	now := time.Unix(1700000000, 0)
+	if _, paused, _ := db.PausedUntil(now); paused {
+		t.Fatal("Expected hotkeys not to be paused initially")
+	}
+
+	if err := db.Pause(time.Time{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	until, paused, err := db.PausedUntil(now.Add(24 * time.Hour))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !paused || !until.IsZero() {
+		t.Errorf("Expected to be paused until resumed, got %v, %v", until, paused)
+	}
+
+	if err := db.Resume(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, paused, _ := db.PausedUntil(now); paused {
+		t.Error("Expected hotkeys to be resumed")
+	}
+}
+
+func TestPauseForDuration(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	now := time.Unix(1700000000, 0)
+	if err := db.Pause(now.Add(30 * time.Minute)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	until, paused, err := db.PausedUntil(now.Add(10 * time.Minute))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !paused || !until.Equal(now.Add(30*time.Minute)) {
+		t.Errorf("Expected to be paused until %v, got %v, %v", now.Add(30*time.Minute), until, paused)
+	}
+
+	if _, paused, _ := db.PausedUntil(now.Add(30 * time.Minute)); paused {
+		t.Error("Expected the pause to be over")
+	}
+}
+
+func TestTogglePause(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	now := time.Unix(1700000000, 0)
+	paused, err := db.TogglePause(now)
+	if err != nil || !paused {
+		t.Fatalf("Expected the toggle to pause, got %v, %v", paused, err)
+	}
+	paused, err = db.TogglePause(now)
+	if err != nil || paused {
+		t.Fatalf("Expected the toggle to resume, got %v, %v", paused, err)
+	}
+
+	// An expired pause toggles to paused
+	if err := db.Pause(now.Add(-time.Minute)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if paused, _ := db.TogglePause(now); !paused {
+		t.Error("Expected an expired pause to toggle to paused")
+	}
+}
+
+func TestPauseStateFollowsDatabase(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	now := time.Unix(1700000000, 0)
+	var state PauseState
+	if _, paused := state.PausedUntil(now); paused {
+		t.Fatal("Expected hotkeys not to be paused initially")
+	}
+
+	// Paused by another process, seen once refreshed
+	if err := db.Pause(now.Add(time.Hour)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, paused := state.PausedUntil(now); paused {
+		t.Error("Expected the state to keep the last read value")
+	}
+	if err := state.Refresh(db, now); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if until, paused := state.PausedUntil(now); !paused || !until.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected to be paused for an hour, got %v, %v", until, paused)
+	}
+	if _, paused := state.PausedUntil(now.Add(2 * time.Hour)); paused {
+		t.Error("Expected the pause to expire without a refresh")
+	}
+
+	paused, err := state.Toggle(db, now)
+	if err != nil || paused {
+		t.Fatalf("Expected the toggle to resume, got %v, %v", paused, err)
+	}
+	if _, paused := state.PausedUntil(now); paused {
+		t.Error("Expected the state to be resumed")
+	}
+	if _, paused, _ := db.PausedUntil(now); paused {
+		t.Error("Expected the database to be resumed")
+	}
+}
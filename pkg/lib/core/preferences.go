@@ -0,0 +1,39 @@
+package core
+
+import "database/sql"
+
+func (d *Database) createPreferences() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS preferences (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);`
+	_, err := d.conn.Exec(query)
+	return err
+}
+
+// GetPreference returns the stored value of a preference. ok is false when
+// it was never set.
+func (d *Database) GetPreference(key string) (string, bool, error) {
+	var value string
+	err := d.conn.QueryRow("SELECT value FROM preferences WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+func (d *Database) SetPreference(key string, value string) error {
+	query := "INSERT INTO preferences (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value"
+	_, err := d.conn.Exec(query, key, value)
+	return err
+}
+
+// DeletePreference removes a preference, doing nothing when it isn't set.
+func (d *Database) DeletePreference(key string) error {
+	_, err := d.conn.Exec("DELETE FROM preferences WHERE key = ?", key)
+	return err
+}
@@ -0,0 +1,27 @@
+package core
+
+import "testing"
+
+func TestPreferences(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	if _, ok, err := db.GetPreference("columns"); err != nil || ok {
+		t.Fatalf("Expected unset preference, got ok=%v err=%v", ok, err)
+	}
+
+	if err := db.SetPreference("columns", "name,hotkey"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := db.SetPreference("columns", "name,version"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	value, ok, err := db.GetPreference("columns")
+	if err != nil || !ok {
+		t.Fatalf("Expected stored preference, got ok=%v err=%v", ok, err)
+	}
+	if value != "name,version" {
+		t.Errorf("Expected name,version, got %q", value)
+	}
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Prefixes of a stored scope, followed by comma separated bundle ids
+const (
+	scopeOnly   = "only:"
+	scopeExcept = "except:"
+)
+
+// Scope limits where a hotkey applies, by the bundle id of the frontmost app.
+// The zero Scope applies everywhere.
+type Scope struct {
+	Only   []string // applies only while one of these apps is frontmost
+	Except []string // applies except while one of these apps is frontmost
+}
+
+// ParseScope reads a scope stored as "", "only:<bundle ids>" or
+// "except:<bundle ids>".
+func ParseScope(s string) (Scope, error) {
+	if s == "" {
+		return Scope{}, nil
+	}
+
+	var scope Scope
+	var list string
+	switch {
+	case strings.HasPrefix(s, scopeOnly):
+		list = strings.TrimPrefix(s, scopeOnly)
+	case strings.HasPrefix(s, scopeExcept):
+		list = strings.TrimPrefix(s, scopeExcept)
+	default:
+		return Scope{}, fmt.Errorf("invalid scope %q, expected only:<bundle ids> or except:<bundle ids>", s)
+	}
+
+	var ids []string
+	for _, id := range strings.Split(list, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return Scope{}, fmt.Errorf("invalid scope %q, no bundle ids", s)
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	if strings.HasPrefix(s, scopeOnly) {
+		scope.Only = ids
+	} else {
+		scope.Except = ids
+	}
+	return scope, nil
+}
+
+// String returns the scope as stored, ParseScope reads it back.
+func (s Scope) String() string {
+	switch {
+	case len(s.Only) > 0:
+		return scopeOnly + strings.Join(s.Only, ",")
+	case len(s.Except) > 0:
+		return scopeExcept + strings.Join(s.Except, ",")
+	default:
+		return ""
+	}
+}
+
+// Describe explains the scope to the user.
+func (s Scope) Describe() string {
+	switch {
+	case len(s.Only) > 0:
+		return "only in " + strings.Join(s.Only, ", ")
+	case len(s.Except) > 0:
+		return "everywhere except " + strings.Join(s.Except, ", ")
+	default:
+		return "everywhere"
+	}
+}
+
+// Specific reports whether the scope names the apps it applies in. Specific
+// bindings take precedence over the others.
+func (s Scope) Specific() bool {
+	return len(s.Only) > 0
+}
+
+// Applies reports whether a hotkey with this scope applies while the app with
+// bundleId is frontmost. bundleId is empty when the app isn't known.
+func (s Scope) Applies(bundleId string) bool {
+	switch {
+	case len(s.Only) > 0:
+		return slices.Contains(s.Only, bundleId)
+	case len(s.Except) > 0:
+		return !slices.Contains(s.Except, bundleId)
+	default:
+		return true
+	}
+}
+
+// Overlaps reports whether two bindings of the same hotkey with these scopes
+// would compete for a key press. A specific binding doesn't compete with the
+// others as it takes precedence.
+func (s Scope) Overlaps(o Scope) bool {
+	if s.Specific() != o.Specific() {
+		return false
+	}
+	if !s.Specific() {
+		// Both apply in every app not excluded by either
+		return true
+	}
+	for _, id := range s.Only {
+		if slices.Contains(o.Only, id) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseScope(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Scope
+		stored   string
+	}{
+		{"", Scope{}, ""},
+		{"only:com.apple.Safari", Scope{Only: []string{"com.apple.Safari"}}, "only:com.apple.Safari"},
+		{"only: com.b, com.a ,com.b", Scope{Only: []string{"com.a", "com.b"}}, "only:com.a,com.b"},
+		{"except:com.apple.Terminal", Scope{Except: []string{"com.apple.Terminal"}}, "except:com.apple.Terminal"},
+	}
+	for _, tc := range tests {
+		scope, err := ParseScope(tc.input)
+		if err != nil {
+			t.Fatalf("ParseScope(%q) returned error: %v", tc.input, err)
+		}
+		if !slices.Equal(scope.Only, tc.expected.Only) || !slices.Equal(scope.Except, tc.expected.Except) {
+			t.Errorf("ParseScope(%q) = %+v; want %+v", tc.input, scope, tc.expected)
+		}
+		if scope.String() != tc.stored {
+			t.Errorf("ParseScope(%q).String() = %q; want %q", tc.input, scope.String(), tc.stored)
+		}
+	}
+
+	for _, invalid := range []string{"com.apple.Safari", "only:", "except: , "} {
+		if _, err := ParseScope(invalid); err == nil {
+			t.Errorf("Expected ParseScope(%q) to fail", invalid)
+		}
+	}
+}
+
+func TestScopeApplies(t *testing.T) {
+	only := Scope{Only: []string{"com.apple.Safari"}}
+	except := Scope{Except: []string{"com.apple.Terminal"}}
+
+	tests := []struct {
+		scope    Scope
+		bundleId string
+		expected bool
+	}{
+		{Scope{}, "com.apple.Safari", true},
+		{Scope{}, "", true},
+		{only, "com.apple.Safari", true},
+		{only, "com.apple.Terminal", false},
+		{only, "", false},
+		{except, "com.apple.Safari", true},
+		{except, "com.apple.Terminal", false},
+		{except, "", true},
+	}
+	for _, tc := range tests {
+		if got := tc.scope.Applies(tc.bundleId); got != tc.expected {
+			t.Errorf("%q.Applies(%q) = %v; want %v", tc.scope, tc.bundleId, got, tc.expected)
+		}
+	}
+}
+
+func TestScopeOverlaps(t *testing.T) {
+	global := Scope{}
+	except := Scope{Except: []string{"com.a"}}
+	onlyA := Scope{Only: []string{"com.a"}}
+	onlyAB := Scope{Only: []string{"com.a", "com.b"}}
+	onlyC := Scope{Only: []string{"com.c"}}
+
+	tests := []struct {
+		a, b     Scope
+		expected bool
+	}{
+		{global, global, true},
+		{global, except, true},
+		{global, onlyA, false},
+		{except, onlyA, false},
+		{onlyA, onlyAB, true},
+		{onlyA, onlyC, false},
+	}
+	for _, tc := range tests {
+		if got := tc.a.Overlaps(tc.b); got != tc.expected {
+			t.Errorf("%q.Overlaps(%q) = %v; want %v", tc.a, tc.b, got, tc.expected)
+		}
+		if got := tc.b.Overlaps(tc.a); got != tc.expected {
+			t.Errorf("%q.Overlaps(%q) = %v; want %v", tc.b, tc.a, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+//go:build cgo
+
+package core
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// isUniqueViolation reports whether err is SQLite refusing a row that breaks
+// a UNIQUE constraint or index.
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
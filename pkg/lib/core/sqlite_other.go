@@ -0,0 +1,10 @@
+//go:build !cgo
+
+package core
+
+// Without cgo the SQLite driver is a stub that can't open a database, so no
+// statement gets far enough to break a constraint. The pure Go listeners still
+// build against this package.
+func isUniqueViolation(err error) bool {
+	return false
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Prefix of the LSApplicationCategoryType values defined by Apple
+const categoryPrefix = "public.app-category."
+
+// NormalizeTag lowercases a tag and replaces whitespace with dashes so
+// "Dev Tools" and "dev-tools" refer to the same tag.
+func NormalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+}
+
+// CategoryTag converts an LSApplicationCategoryType such as
+// "public.app-category.developer-tools" into the tag "developer-tools".
+func CategoryTag(category string) string {
+	return NormalizeTag(strings.TrimPrefix(category, categoryPrefix))
+}
+
+func (d *Database) AddTag(settingId int, tag string) error {
+	tag = NormalizeTag(tag)
+	if tag == "" {
+		return fmt.Errorf("tag cannot be empty")
+	}
+
+	query := "INSERT OR IGNORE INTO tags (setting_id, tag, source) VALUES (?, ?, 'user')"
+	_, err := d.conn.Exec(query, settingId, tag)
+	return err
+}
+
+// AddTagAll adds tag to several apps in a single transaction.
+func (d *Database) AddTagAll(settingIds []int, tag string) error {
+	tag = NormalizeTag(tag)
+	if tag == "" {
+		return fmt.Errorf("tag cannot be empty")
+	}
+
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "INSERT OR IGNORE INTO tags (setting_id, tag, source) VALUES (?, ?, 'user')"
+	for _, id := range settingIds {
+		if _, err := tx.Exec(query, id, tag); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (d *Database) RemoveTag(settingId int, tag string) error {
+	query := "DELETE FROM tags WHERE setting_id = ? AND tag = ?"
+	_, err := d.conn.Exec(query, settingId, NormalizeTag(tag))
+	return err
+}
+
+// GetTags returns the tags of every app keyed by setting id.
+func (d *Database) GetTags() (map[int][]string, error) {
+	rows, err := d.conn.Query("SELECT setting_id, tag FROM tags ORDER BY tag ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[int][]string)
+	for rows.Next() {
+		var id int
+		var tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, err
+		}
+		tags[id] = append(tags[id], tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// FindApp looks an app up by name, display name or bundle id, ignoring case.
+func (d *Database) FindApp(query string) (*Setting, error) {
+	q := "SELECT " + settingColumns + ` FROM settings
+	WHERE name = ? COLLATE NOCASE OR display_name = ? COLLATE NOCASE OR bundle_id = ? COLLATE NOCASE
+	ORDER BY name ASC LIMIT 1`
+	row := d.conn.QueryRow(q, query, query, query)
+
+	s, err := scanSetting(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// syncCategoryTag replaces the category derived tag of an app. Nothing is
+// written when the tag is already there.
+func syncCategoryTag(tx *sql.Tx, settingId int, category string) error {
+	tag := CategoryTag(category)
+	_, err := tx.Exec("DELETE FROM tags WHERE setting_id = ? AND source = 'category' AND tag != ?", settingId, tag)
+	if err != nil || tag == "" {
+		return err
+	}
+
+	query := "INSERT OR IGNORE INTO tags (setting_id, tag, source) VALUES (?, ?, 'category')"
+	_, err = tx.Exec(query, settingId, tag)
+	return err
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"dev", "dev"},
+		{"Dev Tools", "dev-tools"},
+		{"  spaced   out ", "spaced-out"},
+		{"", ""},
+	}
+	for _, tc := range tests {
+		if got := NormalizeTag(tc.input); got != tc.expected {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", tc.input, got, tc.expected)
+		}
+	}
+}
+
+func TestCategoryTag(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"public.app-category.developer-tools", "developer-tools"},
+		{"public.app-category.games", "games"},
+		{"com.example.custom", "com.example.custom"},
+		{"", ""},
+	}
+	for _, tc := range tests {
+		if got := CategoryTag(tc.input); got != tc.expected {
+			t.Errorf("CategoryTag(%q) = %q, want %q", tc.input, got, tc.expected)
+		}
+	}
+}
+
+func TestAddAndRemoveTag(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{{Name: "App1", Path: "/usr/bin/app1"}})
+	id := settings[0].Id
+
+	if err := db.AddTag(id, "Dev"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Adding the same tag twice is a no-op
+	if err := db.AddTag(id, "dev"); err != nil {
+		t.Fatalf("Expected no error on duplicate tag, got %v", err)
+	}
+	if err := db.AddTag(id, "work"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tags, err := db.GetTags()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !slices.Equal(tags[id], []string{"dev", "work"}) {
+		t.Errorf("Expected tags [dev work], got %v", tags[id])
+	}
+
+	if err := db.RemoveTag(id, "dev"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	refreshed, err := db.GetAllSettings()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !slices.Equal(refreshed[0].Tags, []string{"work"}) {
+		t.Errorf("Expected tags [work], got %v", refreshed[0].Tags)
+	}
+}
+
+func TestAddTagEmpty(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{{Name: "App1", Path: "/usr/bin/app1"}})
+	if err := db.AddTag(settings[0].Id, "   "); err == nil {
+		t.Fatal("Expected error for empty tag, got nil")
+	}
+}
+
+func TestRefreshSyncsCategoryTag(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	apps := []App{
+		{Name: "Editor", Path: "/usr/bin/editor", Category: "public.app-category.developer-tools"},
+	}
+	settings := seedApps(t, db, apps)
+	if !slices.Equal(settings[0].Tags, []string{"developer-tools"}) {
+		t.Fatalf("Expected category tag, got %v", settings[0].Tags)
+	}
+
+	if err := db.AddTag(settings[0].Id, "work"); err != nil {
+		t.Fatalf("Failed to add tag: %v", err)
+	}
+
+	// Category change replaces the category tag but keeps user tags
+	apps[0].Category = "public.app-category.productivity"
+	settings = seedApps(t, db, apps)
+	if !slices.Equal(settings[0].Tags, []string{"productivity", "work"}) {
+		t.Errorf("Expected tags [productivity work], got %v", settings[0].Tags)
+	}
+}
+
+func TestRefreshRemovesTagsOfStaleApps(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{{Name: "App1", Path: "/usr/bin/app1"}})
+	if err := db.AddTag(settings[0].Id, "dev"); err != nil {
+		t.Fatalf("Failed to add tag: %v", err)
+	}
+
+	seedApps(t, db, []App{})
+
+	tags, err := db.GetTags()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tags) != 0 {
+		t.Errorf("Expected no tags left, got %v", tags)
+	}
+}
+
+func TestFindApp(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	seedApps(t, db, []App{
+		{Name: "Editor", Path: "/usr/bin/editor", DisplayName: "Code Editor", BundleId: "com.example.editor"},
+		{Name: "Notes", Path: "/usr/bin/notes"},
+	})
+
+	for _, query := range []string{"editor", "Code Editor", "COM.EXAMPLE.EDITOR"} {
+		s, err := db.FindApp(query)
+		if err != nil {
+			t.Fatalf("FindApp(%q) returned error: %v", query, err)
+		}
+		if s == nil || s.Name != "Editor" {
+			t.Errorf("FindApp(%q) = %v, want Editor", query, s)
+		}
+	}
+
+	s, err := db.FindApp("missing")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if s != nil {
+		t.Errorf("Expected nil for unknown app, got %v", s)
+	}
+}
+
+func TestAddTagAll(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "App1", Path: "/usr/bin/app1"},
+		{Name: "App2", Path: "/usr/bin/app2"},
+	})
+
+	if err := db.AddTagAll([]int{settings[0].Id, settings[1].Id}, "Dev Tools"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tags, err := db.GetTags()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, s := range settings {
+		if len(tags[s.Id]) != 1 || tags[s.Id][0] != "dev-tools" {
+			t.Errorf("Expected %s to be tagged dev-tools, got %v", s.Name, tags[s.Id])
+		}
+	}
+
+	if err := db.AddTagAll([]int{settings[0].Id}, "  "); err == nil {
+		t.Error("Expected an error for an empty tag")
+	}
+}
@@ -0,0 +1,57 @@
+package core
+
+import "time"
+
+// Usage counts how often an app was launched through its hotkey
+type Usage struct {
+	LaunchCount int
+	LastUsed    time.Time // zero if never launched
+}
+
+func (d *Database) createUsage() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS usage (
+		setting_id INTEGER PRIMARY KEY REFERENCES settings(id) ON DELETE CASCADE,
+		launch_count INTEGER NOT NULL DEFAULT 0,
+		last_used INTEGER NOT NULL DEFAULT 0
+	);`
+	_, err := d.conn.Exec(query)
+	return err
+}
+
+// RecordLaunch counts a launch of the app at the given time.
+func (d *Database) RecordLaunch(settingId int, at time.Time) error {
+	query := `
+	INSERT INTO usage (setting_id, launch_count, last_used) VALUES (?, 1, ?)
+	ON CONFLICT(setting_id) DO UPDATE SET launch_count = launch_count + 1, last_used = excluded.last_used`
+	_, err := d.conn.Exec(query, settingId, at.Unix())
+	return err
+}
+
+// GetUsage returns the usage of every launched app keyed by setting id.
+func (d *Database) GetUsage() (map[int]Usage, error) {
+	rows, err := d.conn.Query("SELECT setting_id, launch_count, last_used FROM usage")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := make(map[int]Usage)
+	for rows.Next() {
+		var id, count int
+		var lastUsed int64
+		if err := rows.Scan(&id, &count, &lastUsed); err != nil {
+			return nil, err
+		}
+		u := Usage{LaunchCount: count}
+		if lastUsed > 0 {
+			u.LastUsed = time.Unix(lastUsed, 0)
+		}
+		usage[id] = u
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordLaunch(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := seedApps(t, db, []App{
+		{Name: "App1", Path: "/usr/bin/app1"},
+		{Name: "App2", Path: "/usr/bin/app2"},
+	})
+	id := settings[0].Id
+
+	first := time.Unix(1700000000, 0)
+	second := first.Add(time.Hour)
+	if err := db.RecordLaunch(id, first); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := db.RecordLaunch(id, second); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	all, err := db.GetAllSettings()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if all[0].Usage.LaunchCount != 2 {
+		t.Errorf("Expected 2 launches, got %d", all[0].Usage.LaunchCount)
+	}
+	if !all[0].Usage.LastUsed.Equal(second) {
+		t.Errorf("Expected last used %v, got %v", second, all[0].Usage.LastUsed)
+	}
+	if all[1].Usage.LaunchCount != 0 || !all[1].Usage.LastUsed.IsZero() {
+		t.Errorf("Expected no usage for App2, got %+v", all[1].Usage)
+	}
+}
+
+func TestRefreshRemovesUsageOfStaleApps(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	id := seedApps(t, db, []App{{Name: "App1", Path: "/usr/bin/app1"}})[0].Id
+	if err := db.RecordLaunch(id, time.Now()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := db.Refresh(nil); err != nil {
+		t.Fatalf("Failed to refresh: %v", err)
+	}
+
+	usage, err := db.GetUsage()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(usage) != 0 {
+		t.Errorf("Expected usage to be removed, got %v", usage)
+	}
+}
@@ -1,8 +1,10 @@
 package darwin
 
 import (
+	"bytes"
 	"fmt"
 	"os/exec"
+	"strings"
 )
 
 /*
@@ -40,9 +42,14 @@ func Launch(app string, mode string) error {
 			activate
 		end tell
 		`, app)
-	err := exec.Command("osascript", "-e", script).Run()
-	if err != nil {
-		fmt.Println(err.Error())
+	var stderr bytes.Buffer
+	cmd := exec.Command("osascript", "-e", script)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
 	}
 	return nil
 }
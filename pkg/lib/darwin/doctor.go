@@ -0,0 +1,89 @@
+package darwin
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+	"howett.net/plist"
+)
+
+const privacySettings = "System Settings > Privacy & Security"
+
+// Doctor checks the permissions and settings Yay needs. With request set
+// macOS asks the user for missing permissions.
+func Doctor(request bool) []core.Check {
+	return []core.Check{
+		checkAccessibility(request),
+		checkInputMonitoring(request),
+		checkMissionControl(),
+	}
+}
+
+func checkAccessibility(request bool) core.Check {
+	check := core.Check{Name: "Accessibility"}
+	if AccessibilityTrusted(request) {
+		check.Detail = "granted"
+		return check
+	}
+
+	check.Status = core.CheckFailed
+	check.Detail = "not granted, hotkeys are ignored and Dock apps can't be opened"
+	check.Fix = "Add your terminal, or yay when run on its own, in " + privacySettings +
+		" > Accessibility, or run yay doctor --request to be asked"
+	return check
+}
+
+func checkInputMonitoring(request bool) core.Check {
+	check := core.Check{Name: "Input Monitoring"}
+	if InputMonitoringAllowed(request) {
+		check.Detail = "allowed"
+		return check
+	}
+
+	check.Status = core.CheckFailed
+	check.Detail = "not allowed, key presses can't be read"
+	check.Fix = "Add your terminal, or yay when run on its own, in " + privacySettings +
+		" > Input Monitoring, or run yay doctor --request to be asked"
+	return check
+}
+
+func checkMissionControl() core.Check {
+	check := core.Check{Name: "Mission Control"}
+
+	var data []byte
+	if usr, err := user.Current(); err == nil {
+		// An unreadable file leaves the default
+		data, _ = os.ReadFile(filepath.Join(usr.HomeDir, "Library", "Preferences", "com.apple.dock.plist"))
+	}
+	if switchesSpaces(data) {
+		check.Detail = "switching to an app switches to a Space with its windows"
+		return check
+	}
+
+	check.Status = core.CheckWarning
+	check.Detail = "switching to an app stays on the current Space, desktop mode can't switch Spaces"
+	check.Fix = `Enable "When switching to an application, switch to a Space with open windows for the application" in ` +
+		"System Settings > Desktop & Dock > Mission Control, or run: " +
+		"defaults write com.apple.dock workspaces-auto-swoosh -bool true && killall Dock"
+	return check
+}
+
+type dockPlist struct {
+	// Missing on a fresh install, where it is on
+	AutoSwoosh *bool `plist:"workspaces-auto-swoosh"`
+}
+
+// switchesSpaces reports whether the Dock preferences in data have switching
+// to a Space with open windows of an application turned on.
+func switchesSpaces(data []byte) bool {
+	if data == nil {
+		return true
+	}
+	var parsed dockPlist
+	if _, err := plist.Unmarshal(data, &parsed); err != nil || parsed.AutoSwoosh == nil {
+		return true
+	}
+	return *parsed.AutoSwoosh
+}
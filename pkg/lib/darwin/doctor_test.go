@@ -0,0 +1,28 @@
+package darwin
+
+import "testing"
+
+func TestSwitchesSpaces(t *testing.T) {
+	plistWith := func(body string) []byte {
+		return []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0"><dict>` + body + `</dict></plist>`)
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected bool
+	}{
+		{"unreadable", nil, true},
+		{"invalid", []byte("garbage"), true},
+		{"unset", plistWith(`<key>tilesize</key><integer>48</integer>`), true},
+		{"enabled", plistWith(`<key>workspaces-auto-swoosh</key><true/>`), true},
+		{"disabled", plistWith(`<key>workspaces-auto-swoosh</key><false/>`), false},
+	}
+	for _, tc := range tests {
+		if got := switchesSpaces(tc.data); got != tc.expected {
+			t.Errorf("%s: switchesSpaces = %v; want %v", tc.name, got, tc.expected)
+		}
+	}
+}
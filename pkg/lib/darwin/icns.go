@@ -0,0 +1,345 @@
+package darwin
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Icon size (in pixels) cached for the TUI
+const IconSize = 32
+
+var errNoIcon = errors.New("no usable icon representation")
+
+var pngMagic = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+// Pixel sizes of the PNG backed icns element types
+var icnsPNGTypes = map[string]int{
+	"icp4": 16,
+	"icp5": 32,
+	"icp6": 64,
+	"ic07": 128,
+	"ic08": 256,
+	"ic09": 512,
+	"ic10": 1024,
+	"ic11": 32,
+	"ic12": 64,
+	"ic13": 256,
+	"ic14": 512,
+}
+
+// Legacy RLE encoded RGB element types and their matching alpha masks
+var icnsRLETypes = map[string]struct {
+	size int
+	mask string
+}{
+	"is32": {16, "s8mk"},
+	"il32": {32, "l8mk"},
+	"ih32": {48, "h8mk"},
+	"it32": {128, "t8mk"},
+}
+
+// ARGB element types, RLE encoded with a leading "ARGB" marker
+var icnsARGBTypes = map[string]int{
+	"ic04": 16,
+	"ic05": 32,
+}
+
+// Element types in the order they are considered, so ties between
+// representations of the same size always go to the first one listed
+var icnsTypeOrder = []string{
+	"icp4", "icp5", "icp6", "ic07", "ic08", "ic09", "ic10", "ic11", "ic12", "ic13", "ic14",
+	"ic04", "ic05",
+	"is32", "il32", "ih32", "it32",
+}
+
+type icnsEntry struct {
+	size   int
+	decode func() (image.Image, error)
+}
+
+// DecodeIcns reads an Apple icon image and returns the representation closest
+// to size, scaled to size x size.
+func DecodeIcns(r io.Reader, size int) (image.Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 8 || string(data[:4]) != "icns" {
+		return nil, fmt.Errorf("not an icns file")
+	}
+
+	elements := make(map[string][]byte)
+	for offset := 8; offset+8 <= len(data); {
+		kind := string(data[offset : offset+4])
+		length := int(binary.BigEndian.Uint32(data[offset+4 : offset+8]))
+		if length < 8 || offset+length > len(data) {
+			return nil, fmt.Errorf("malformed icns element %q", kind)
+		}
+		elements[kind] = data[offset+8 : offset+length]
+		offset += length
+	}
+
+	var entries []icnsEntry
+	for _, kind := range icnsTypeOrder {
+		body, ok := elements[kind]
+		if !ok {
+			continue
+		}
+		if px, ok := icnsPNGTypes[kind]; ok && bytes.HasPrefix(body, pngMagic) {
+			entries = append(entries, icnsEntry{px, func() (image.Image, error) {
+				return png.Decode(bytes.NewReader(body))
+			}})
+		}
+		if t, ok := icnsRLETypes[kind]; ok {
+			mask := elements[t.mask]
+			entries = append(entries, icnsEntry{t.size, func() (image.Image, error) {
+				return decodeRLEIcon(body, mask, t.size, kind == "it32")
+			}})
+		}
+		if px, ok := icnsARGBTypes[kind]; ok && bytes.HasPrefix(body, []byte("ARGB")) {
+			entries = append(entries, icnsEntry{px, func() (image.Image, error) {
+				return decodeARGBIcon(body[4:], px)
+			}})
+		}
+	}
+
+	best := pickIcnsEntry(entries, size)
+	if best == nil {
+		return nil, errNoIcon
+	}
+
+	img, err := best.decode()
+	if err != nil {
+		return nil, err
+	}
+
+	return scaleImage(img, size), nil
+}
+
+// pickIcnsEntry returns the smallest representation at least size pixels
+// wide, or the largest one available if none is big enough.
+func pickIcnsEntry(entries []icnsEntry, size int) *icnsEntry {
+	var best *icnsEntry
+	for i := range entries {
+		e := &entries[i]
+		switch {
+		case best == nil:
+			best = e
+		case best.size < size && e.size > best.size:
+			best = e
+		case e.size >= size && e.size < best.size:
+			best = e
+		}
+	}
+	return best
+}
+
+// unpackRLE decodes the PackBits style run length encoding used by icns.
+func unpackRLE(data []byte, want int) ([]byte, int, error) {
+	out := make([]byte, 0, want)
+	i := 0
+	for len(out) < want && i < len(data) {
+		n := int(data[i])
+		i++
+		if n < 0x80 {
+			count := n + 1
+			if i+count > len(data) {
+				return nil, i, fmt.Errorf("truncated rle literal")
+			}
+			out = append(out, data[i:i+count]...)
+			i += count
+		} else {
+			if i >= len(data) {
+				return nil, i, fmt.Errorf("truncated rle run")
+			}
+			for range n - 125 {
+				out = append(out, data[i])
+			}
+			i++
+		}
+	}
+	if len(out) < want {
+		return nil, i, fmt.Errorf("short rle data")
+	}
+	return out[:want], i, nil
+}
+
+// unpackChannels decodes the given number of planar channels, each either
+// raw (when the element holds exactly size*size*channels bytes) or RLE.
+func unpackChannels(data []byte, size int, channels int) ([][]byte, error) {
+	pixels := size * size
+	planes := make([][]byte, channels)
+
+	if len(data) == pixels*channels {
+		for c := range channels {
+			planes[c] = data[c*pixels : (c+1)*pixels]
+		}
+		return planes, nil
+	}
+
+	for c := range channels {
+		plane, n, err := unpackRLE(data, pixels)
+		if err != nil {
+			return nil, err
+		}
+		planes[c] = plane
+		data = data[n:]
+	}
+	return planes, nil
+}
+
+func decodeRLEIcon(data []byte, mask []byte, size int, padded bool) (image.Image, error) {
+	if padded {
+		// it32 data starts with four zero bytes
+		if len(data) < 4 {
+			return nil, fmt.Errorf("truncated it32 element")
+		}
+		data = data[4:]
+	}
+
+	planes, err := unpackChannels(data, size, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for i := range size * size {
+		alpha := uint8(0xff)
+		if len(mask) == size*size {
+			alpha = mask[i]
+		}
+		img.Pix[i*4+0] = planes[0][i]
+		img.Pix[i*4+1] = planes[1][i]
+		img.Pix[i*4+2] = planes[2][i]
+		img.Pix[i*4+3] = alpha
+	}
+	return img, nil
+}
+
+func decodeARGBIcon(data []byte, size int) (image.Image, error) {
+	planes, err := unpackChannels(data, size, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for i := range size * size {
+		img.Pix[i*4+0] = planes[1][i]
+		img.Pix[i*4+1] = planes[2][i]
+		img.Pix[i*4+2] = planes[3][i]
+		img.Pix[i*4+3] = planes[0][i]
+	}
+	return img, nil
+}
+
+// scaleImage resizes src to size x size by averaging the source pixels that
+// fall into each destination pixel.
+func scaleImage(src image.Image, size int) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return dst
+	}
+
+	for y := range size {
+		y0 := b.Min.Y + y*b.Dy()/size
+		y1 := max(b.Min.Y+(y+1)*b.Dy()/size, y0+1)
+		for x := range size {
+			x0 := b.Min.X + x*b.Dx()/size
+			x1 := max(b.Min.X+(x+1)*b.Dx()/size, x0+1)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(src.At(sx, sy)).(color.NRGBA64)
+					// Weight colors by alpha so transparent pixels don't darken edges
+					r += uint64(c.R) * uint64(c.A)
+					g += uint64(c.G) * uint64(c.A)
+					bl += uint64(c.B) * uint64(c.A)
+					a += uint64(c.A)
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			if a > 0 {
+				dst.Pix[i+0] = uint8(r / a >> 8)
+				dst.Pix[i+1] = uint8(g / a >> 8)
+				dst.Pix[i+2] = uint8(bl / a >> 8)
+			}
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return dst
+}
+
+// CacheIcon converts the .icns file at iconPath into a size x size PNG inside
+// cacheDir and returns its path. Previously converted icons are reused until
+// the source file changes.
+func CacheIcon(cacheDir string, iconPath string, size int) (string, error) {
+	if iconPath == "" {
+		return "", errNoIcon
+	}
+
+	info, err := os.Stat(iconPath)
+	if err != nil {
+		return "", err
+	}
+
+	key := fmt.Sprintf("%s:%d:%d:%d", iconPath, info.ModTime().UnixNano(), info.Size(), size)
+	sum := sha1.Sum([]byte(key))
+	pngPath := filepath.Join(cacheDir, hex.EncodeToString(sum[:])+".png")
+
+	if _, err := os.Stat(pngPath); err == nil {
+		return pngPath, nil
+	}
+
+	f, err := os.Open(iconPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	img, err := DecodeIcns(f, size)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file first so a concurrent reader never sees a
+	// partially written PNG
+	tmp, err := os.CreateTemp(cacheDir, "icon-*.png")
+	if err != nil {
+		return "", err
+	}
+	if err := png.Encode(tmp, img); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	if err := os.Rename(tmp.Name(), pngPath); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	return pngPath, nil
+}
@@ -0,0 +1,231 @@
+package darwin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// buildIcns assembles an icns file from element type/body pairs.
+func buildIcns(t *testing.T, elements ...any) []byte {
+	t.Helper()
+	var body bytes.Buffer
+	for i := 0; i < len(elements); i += 2 {
+		kind := elements[i].(string)
+		data := elements[i+1].([]byte)
+		body.WriteString(kind)
+		binary.Write(&body, binary.BigEndian, uint32(len(data)+8))
+		body.Write(data)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("icns")
+	binary.Write(&out, binary.BigEndian, uint32(body.Len()+8))
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+func solidPNG(t *testing.T, size int, c color.NRGBA) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := range size {
+		for x := range size {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// ---------------------------------------------------------------------------
+// DecodeIcns tests
+// ---------------------------------------------------------------------------
+
+func TestDecodeIcnsRejectsInvalidHeader(t *testing.T) {
+	_, err := DecodeIcns(bytes.NewReader([]byte("not an icon")), 32)
+	if err == nil {
+		t.Fatal("Expected error for invalid header, got nil")
+	}
+}
+
+func TestDecodeIcnsRejectsTruncatedElement(t *testing.T) {
+	data := buildIcns(t, "ic07", solidPNG(t, 128, color.NRGBA{255, 0, 0, 255}))
+	_, err := DecodeIcns(bytes.NewReader(data[:len(data)-10]), 32)
+	if err == nil {
+		t.Fatal("Expected error for truncated icns, got nil")
+	}
+}
+
+func TestDecodeIcnsNoUsableRepresentation(t *testing.T) {
+	data := buildIcns(t, "TOC ", []byte{0, 0, 0, 0})
+	_, err := DecodeIcns(bytes.NewReader(data), 32)
+	if err != errNoIcon {
+		t.Fatalf("Expected errNoIcon, got %v", err)
+	}
+}
+
+func TestDecodeIcnsPNGElement(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	data := buildIcns(t, "ic07", solidPNG(t, 128, red))
+
+	img, err := DecodeIcns(bytes.NewReader(data), 32)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if img.Bounds().Dx() != 32 || img.Bounds().Dy() != 32 {
+		t.Fatalf("Expected 32x32 image, got %v", img.Bounds())
+	}
+	if got := color.NRGBAModel.Convert(img.At(16, 16)); got != red {
+		t.Errorf("Expected %v, got %v", red, got)
+	}
+}
+
+func TestDecodeIcnsPrefersClosestLargerSize(t *testing.T) {
+	data := buildIcns(t,
+		"icp4", solidPNG(t, 16, color.NRGBA{255, 0, 0, 255}),
+		"icp6", solidPNG(t, 64, color.NRGBA{0, 255, 0, 255}),
+		"ic08", solidPNG(t, 256, color.NRGBA{0, 0, 255, 255}),
+	)
+
+	img, err := DecodeIcns(bytes.NewReader(data), 32)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := color.NRGBA{0, 255, 0, 255}
+	if got := color.NRGBAModel.Convert(img.At(0, 0)); got != want {
+		t.Errorf("Expected the 64px representation %v, got %v", want, got)
+	}
+}
+
+func TestDecodeIcnsSameSizeTieIsDeterministic(t *testing.T) {
+	// A 16px RLE element listed before a 16px PNG one, the PNG always wins
+	rgb := []byte{
+		0xff, 10, 0xff, 10,
+		0xff, 20, 0xff, 20,
+		0xff, 30, 0xff, 30,
+	}
+	red := color.NRGBA{255, 0, 0, 255}
+	data := buildIcns(t, "is32", rgb, "icp4", solidPNG(t, 16, red))
+
+	for range 20 {
+		img, err := DecodeIcns(bytes.NewReader(data), 16)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := color.NRGBAModel.Convert(img.At(5, 5)); got != red {
+			t.Fatalf("Expected the PNG representation %v, got %v", red, got)
+		}
+	}
+}
+
+func TestDecodeIcnsRLEElementWithMask(t *testing.T) {
+	// Each channel is a single run of 256 bytes, encoded as two 128 byte runs
+	// (0xfd = 128 repetitions)
+	rgb := []byte{
+		0xff, 10, 0xff, 10, // red
+		0xff, 20, 0xff, 20, // green
+		0xff, 30, 0xff, 30, // blue
+	}
+	mask := bytes.Repeat([]byte{0x80}, 16*16)
+	data := buildIcns(t, "is32", rgb, "s8mk", mask)
+
+	img, err := DecodeIcns(bytes.NewReader(data), 16)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := color.NRGBA{10, 20, 30, 0x80}
+	if got := color.NRGBAModel.Convert(img.At(5, 5)); got != want {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestUnpackRLELiteralAndRun(t *testing.T) {
+	out, n, err := unpackRLE([]byte{0x01, 'a', 'b', 0x80, 'c'}, 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(out) != "abccc" {
+		t.Errorf("Expected %q, got %q", "abccc", out)
+	}
+	if n != 5 {
+		t.Errorf("Expected 5 bytes consumed, got %d", n)
+	}
+}
+
+func TestUnpackRLEShortData(t *testing.T) {
+	_, _, err := unpackRLE([]byte{0x01, 'a', 'b'}, 5)
+	if err == nil {
+		t.Fatal("Expected error for short rle data, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// CacheIcon tests
+// ---------------------------------------------------------------------------
+
+func TestCacheIconWritesPNG(t *testing.T) {
+	tmpDir := t.TempDir()
+	iconPath := filepath.Join(tmpDir, "App.icns")
+	data := buildIcns(t, "ic07", solidPNG(t, 128, color.NRGBA{1, 2, 3, 255}))
+	if err := os.WriteFile(iconPath, data, 0644); err != nil {
+		t.Fatalf("Failed to write icon: %v", err)
+	}
+
+	cacheDir := filepath.Join(tmpDir, "icons")
+	pngPath, err := CacheIcon(cacheDir, iconPath, IconSize)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if filepath.Dir(pngPath) != cacheDir {
+		t.Errorf("Expected icon inside %q, got %q", cacheDir, pngPath)
+	}
+
+	f, err := os.Open(pngPath)
+	if err != nil {
+		t.Fatalf("Failed to open cached icon: %v", err)
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("Cached icon is not a PNG: %v", err)
+	}
+	if img.Bounds().Dx() != IconSize {
+		t.Errorf("Expected width %d, got %d", IconSize, img.Bounds().Dx())
+	}
+
+	// A second call should hit the cache and return the same file
+	again, err := CacheIcon(cacheDir, iconPath, IconSize)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if again != pngPath {
+		t.Errorf("Expected cached path %q, got %q", pngPath, again)
+	}
+}
+
+func TestCacheIconMissingSource(t *testing.T) {
+	_, err := CacheIcon(t.TempDir(), "/nonexistent/App.icns", IconSize)
+	if err == nil {
+		t.Fatal("Expected error for missing icon, got nil")
+	}
+}
+
+func TestCacheIconEmptyPath(t *testing.T) {
+	_, err := CacheIcon(t.TempDir(), "", IconSize)
+	if err == nil {
+		t.Fatal("Expected error for empty icon path, got nil")
+	}
+}
@@ -1,9 +1,29 @@
 #include "keyevent.h"
 
 
+// Drain the objects NSWorkspace autoreleases, the tap thread has no pool
+extern void *objc_autoreleasePoolPush(void);
+extern void objc_autoreleasePoolPop(void *pool);
+
 extern int keyEventCallback(long long keycode, long long flags, long long eventType);
+extern void eventTapDisabledCallback(long long reason);
+
+static CFMachPortRef tap = NULL;
+static CFRunLoopSourceRef runLoopSource = NULL;
+static CFRunLoopRef runLoop = NULL;
+static volatile int stopRequested = 0;
 
 static CGEventRef eventCallback(CGEventTapProxy proxy, CGEventType type, CGEventRef event, void *refcon) {
+    // macOS disables taps that take too long to handle an event, turn it
+    // back on or no more events arrive
+    if (type == kCGEventTapDisabledByTimeout || type == kCGEventTapDisabledByUserInput) {
+        if (tap) {
+            CGEventTapEnable(tap, true);
+        }
+        eventTapDisabledCallback((long long)type);
+        return event;
+    }
+
     if (type == kCGEventKeyDown || type == kCGEventKeyUp || type == kCGEventFlagsChanged) {
         long long keycode = (long long)CGEventGetIntegerValueField(event, kCGKeyboardEventKeycode);
         long long flags = (long long)CGEventGetFlags(event);
@@ -15,10 +35,10 @@ static CGEventRef eventCallback(CGEventTapProxy proxy, CGEventType type, CGEvent
     return event;
 }
 
-void startEventTap() {
+int createEventTap() {
     CGEventMask mask = (1 << kCGEventKeyDown) | ( 1 << kCGEventKeyUp) | (1 << kCGEventFlagsChanged);
 
-    CFMachPortRef tap = CGEventTapCreate(
+    tap = CGEventTapCreate(
         kCGSessionEventTap,
         kCGHeadInsertEventTap,
         kCGEventTapOptionDefault,
@@ -27,13 +47,91 @@ void startEventTap() {
         NULL
     );
 
+    // Fails without Accessibility or Input Monitoring access
     if (!tap) {
-        return;
+        return 0;
     }
 
-    CFRunLoopSourceRef runLoopSource = CFMachPortCreateRunLoopSource(kCFAllocatorDefault, tap, 0);
-    CFRunLoopAddSource(CFRunLoopGetCurrent(), runLoopSource, kCFRunLoopCommonModes);
+    stopRequested = 0;
+    runLoop = CFRunLoopGetCurrent();
+    runLoopSource = CFMachPortCreateRunLoopSource(kCFAllocatorDefault, tap, 0);
+    CFRunLoopAddSource(runLoop, runLoopSource, kCFRunLoopCommonModes);
     CGEventTapEnable(tap, true);
 
-    CFRunLoopRun();
+    return 1;
+}
+
+void runEventTap() {
+    // Runs in slices so a stop requested before the run loop started isn't
+    // missed
+    while (!stopRequested) {
+        CFRunLoopRunInMode(kCFRunLoopDefaultMode, 1.0, false);
+    }
+
+    CGEventTapEnable(tap, false);
+    CFRunLoopRemoveSource(runLoop, runLoopSource, kCFRunLoopCommonModes);
+    CFMachPortInvalidate(tap);
+    CFRelease(runLoopSource);
+    CFRelease(tap);
+    runLoopSource = NULL;
+    tap = NULL;
+    runLoop = NULL;
+}
+
+void stopEventTap() {
+    stopRequested = 1;
+    if (runLoop) {
+        CFRunLoopStop(runLoop);
+    }
+}
+
+int isProcessTrusted(int prompt) {
+    const void *keys[] = { kAXTrustedCheckOptionPrompt };
+    const void *values[] = { prompt ? kCFBooleanTrue : kCFBooleanFalse };
+    CFDictionaryRef options = CFDictionaryCreate(
+        kCFAllocatorDefault, keys, values, 1,
+        &kCFTypeDictionaryKeyCallBacks, &kCFTypeDictionaryValueCallBacks
+    );
+
+    Boolean trusted = AXIsProcessTrustedWithOptions(options);
+    CFRelease(options);
+    return trusted;
+}
+
+int canListenToEvents(int request) {
+    if (request) {
+        return CGRequestListenEventAccess();
+    }
+    return CGPreflightListenEventAccess();
+}
+
+int secureInputEnabled() {
+    return IsSecureEventInputEnabled();
+}
+
+int frontmostApp(char *path, int pathSize, char *bundleId, int idSize) {
+    // NSWorkspace answers from the process' own state, unlike the
+    // Accessibility API which asks the focused app and blocks while it hangs
+    void *pool = objc_autoreleasePoolPush();
+    id workspace = ((id (*)(Class, SEL))objc_msgSend)(objc_getClass("NSWorkspace"), sel_registerName("sharedWorkspace"));
+    id app = ((id (*)(id, SEL))objc_msgSend)(workspace, sel_registerName("frontmostApplication"));
+    pid_t pid = -1;
+    bundleId[0] = '\0';
+    if (app) {
+        pid = ((pid_t (*)(id, SEL))objc_msgSend)(app, sel_registerName("processIdentifier"));
+        id identifier = ((id (*)(id, SEL))objc_msgSend)(app, sel_registerName("bundleIdentifier"));
+        if (identifier) {
+            const char *utf8 = ((const char *(*)(id, SEL))objc_msgSend)(identifier, sel_registerName("UTF8String"));
+            if (utf8) {
+                strncpy(bundleId, utf8, idSize - 1);
+                bundleId[idSize - 1] = '\0';
+            }
+        }
+    }
+    objc_autoreleasePoolPop(pool);
+
+    if (pid <= 0) {
+        return 0;
+    }
+    return proc_pidpath(pid, path, pathSize) > 0;
 }
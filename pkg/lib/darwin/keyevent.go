@@ -1,30 +1,61 @@
 package darwin
 
 /*
-#cgo LDFLAGS: -framework ApplicationServices
+#cgo LDFLAGS: -framework ApplicationServices -framework Carbon -framework AppKit -lobjc
 #include <keyevent.h>
 */
 import "C"
 
 import (
+	"errors"
+	"runtime"
 	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/Builtbyjb/yay/pkg/lib/chord"
 )
 
-type KeyEvent struct {
-	Keycode   uint16
-	Flags     uint64
-	EventType int
-}
+type KeyEvent = chord.KeyEvent
 
 const (
-	EventKeyDown      = 10
-	EventKeyUp        = 11
-	EventFlagsChanged = 12
+	EventKeyDown      = chord.EventKeyDown
+	EventKeyUp        = chord.EventKeyUp
+	EventFlagsChanged = chord.EventFlagsChanged
 )
 
+// Why macOS disabled the event tap, the values of kCGEventTapDisabledByTimeout
+// and kCGEventTapDisabledByUserInput
+type TapDisabledReason int64
+
+const (
+	TapDisabledByTimeout   TapDisabledReason = 0xFFFFFFFE
+	TapDisabledByUserInput TapDisabledReason = 0xFFFFFFFF
+)
+
+func (r TapDisabledReason) String() string {
+	switch r {
+	case TapDisabledByTimeout:
+		return "timeout"
+	case TapDisabledByUserInput:
+		return "user input"
+	default:
+		return "unknown"
+	}
+}
+
 var (
 	keyHandler   func(KeyEvent) bool // returns true to consume the event
 	keyHandlerMu sync.RWMutex
+
+	tapDisabledHandler func(TapDisabledReason)
+	tapDisabledCount   atomic.Int64
+
+	// Whether a tap is running, so StopEventTap knows there is one to stop,
+	// and whether StopEventTap was called before the tap started
+	tapRunning   bool
+	tapStopped   bool
+	tapRunningMu sync.Mutex
 )
 
 // SetKeyHandler registers a function that is called synchronously for every
@@ -36,6 +67,39 @@ func SetKeyHandler(handler func(KeyEvent) bool) {
 	keyHandler = handler
 }
 
+// SetTapDisabledHandler registers a function that is called when macOS
+// disabled the event tap, after it has been turned back on.
+func SetTapDisabledHandler(handler func(TapDisabledReason)) {
+	keyHandlerMu.Lock()
+	defer keyHandlerMu.Unlock()
+	tapDisabledHandler = handler
+}
+
+// TapDisabledCount returns how often macOS disabled the event tap since the
+// process started.
+func TapDisabledCount() int64 {
+	return tapDisabledCount.Load()
+}
+
+// cgo directive
+//
+//export eventTapDisabledCallback
+func eventTapDisabledCallback(reason C.longlong) {
+	tapDisabled(TapDisabledReason(reason))
+}
+
+func tapDisabled(reason TapDisabledReason) {
+	tapDisabledCount.Add(1)
+
+	keyHandlerMu.RLock()
+	handler := tapDisabledHandler
+	keyHandlerMu.RUnlock()
+
+	if handler != nil {
+		handler(reason)
+	}
+}
+
 // cgo directive
 //
 //export keyEventCallback
@@ -56,6 +120,101 @@ func keyEventCallback(keycode C.longlong, flags C.longlong, eventType C.longlong
 	return 0 // pass through
 }
 
-func StartEventTap() {
-	C.startEventTap()
+// ErrEventTapFailed is returned when macOS refuses to create the event tap,
+// which happens when Yay lacks Accessibility or Input Monitoring access.
+var ErrEventTapFailed = errors.New("couldn't create the key event tap, Accessibility or Input Monitoring access is missing")
+
+// StartEventTap listens for key events until StopEventTap is called, calling
+// started once the tap is in place. It returns right away with
+// ErrEventTapFailed when the tap can't be created, and without starting the
+// tap when StopEventTap was already called.
+func StartEventTap(started func()) error {
+	// The tap is added to the run loop of the creating thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	tapRunningMu.Lock()
+	if tapRunning {
+		tapRunningMu.Unlock()
+		return errors.New("the key event tap is already running")
+	}
+	if tapStopped {
+		tapStopped = false
+		tapRunningMu.Unlock()
+		return nil
+	}
+	if C.createEventTap() == 0 {
+		tapRunningMu.Unlock()
+		return ErrEventTapFailed
+	}
+	tapRunning = true
+	tapRunningMu.Unlock()
+
+	if started != nil {
+		started()
+	}
+	C.runEventTap()
+
+	tapRunningMu.Lock()
+	tapRunning = false
+	tapRunningMu.Unlock()
+	return nil
+}
+
+// StopEventTap stops the running event tap, making StartEventTap return. When
+// no tap is running yet, the next StartEventTap returns right away instead,
+// so a stop requested while the tap is being set up isn't lost.
+func StopEventTap() {
+	tapRunningMu.Lock()
+	defer tapRunningMu.Unlock()
+	if tapRunning {
+		C.stopEventTap()
+	} else {
+		tapStopped = true
+	}
+}
+
+// AccessibilityTrusted reports whether Yay, or the terminal running it, has
+// Accessibility access. With prompt set macOS asks the user to grant it.
+func AccessibilityTrusted(prompt bool) bool {
+	p := 0
+	if prompt {
+		p = 1
+	}
+	return C.isProcessTrusted(C.int(p)) != 0
+}
+
+// SecureInputEnabled reports whether Secure Event Input is on, e.g. while a
+// password is typed. Apps don't see key events then.
+func SecureInputEnabled() bool {
+	return C.secureInputEnabled() != 0
+}
+
+// Longest bundle id FrontmostApp returns, CFBundleIdentifier is limited to
+// far less
+const maxBundleIdSize = 1024
+
+// FrontmostApp returns the path of the frontmost app's executable, e.g.
+// "/Applications/Safari.app/Contents/MacOS/Safari", and its bundle id.
+func FrontmostApp() (path string, bundleId string, ok bool) {
+	pathBuf := make([]byte, C.PROC_PIDPATHINFO_MAXSIZE)
+	idBuf := make([]byte, maxBundleIdSize)
+	found := C.frontmostApp(
+		(*C.char)(unsafe.Pointer(&pathBuf[0])), C.int(len(pathBuf)),
+		(*C.char)(unsafe.Pointer(&idBuf[0])), C.int(len(idBuf)),
+	)
+	if found == 0 {
+		return "", "", false
+	}
+	return C.GoString((*C.char)(unsafe.Pointer(&pathBuf[0]))), C.GoString((*C.char)(unsafe.Pointer(&idBuf[0]))), true
+}
+
+// InputMonitoringAllowed reports whether Yay may listen to key events. With
+// request set macOS asks the user to allow it.
+func InputMonitoringAllowed(request bool) bool {
+	r := 0
+	if request {
+		r = 1
+	}
+	return C.canListenToEvents(C.int(r)) != 0
 }
@@ -2,8 +2,32 @@
 #define KEYEVENT_H
 
 #include <ApplicationServices/ApplicationServices.h>
+#include <Carbon/Carbon.h>
+#include <libproc.h>
+#include <string.h>
+#include <objc/runtime.h>
+#include <objc/message.h>
 
 
-void startEventTap();
+// Creates the event tap on the current thread's run loop, returns 0 when
+// it can't be created
+int createEventTap();
+
+// Runs the event tap until stopEventTap is called, then releases it
+void runEventTap();
+void stopEventTap();
+
+// Accessibility access, prompting the user to grant it when prompt is set
+int isProcessTrusted(int prompt);
+
+// Input Monitoring access, asking the user for it when request is set
+int canListenToEvents(int request);
+
+// Whether a password field or similar turned on Secure Event Input
+int secureInputEnabled();
+
+// Writes the executable path and bundle id of the frontmost app to path and
+// bundleId, returns 0 when it can't be found
+int frontmostApp(char *path, int pathSize, char *bundleId, int idSize);
 
 #endif // KEYEVENT_H
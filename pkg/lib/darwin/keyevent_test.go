@@ -0,0 +1,55 @@
+package darwin
+
+import "testing"
+
+func TestTapDisabledNotifiesHandler(t *testing.T) {
+	var reasons []TapDisabledReason
+	SetTapDisabledHandler(func(reason TapDisabledReason) {
+		reasons = append(reasons, reason)
+	})
+	defer SetTapDisabledHandler(nil)
+
+	before := TapDisabledCount()
+	tapDisabled(TapDisabledByTimeout)
+	tapDisabled(TapDisabledByUserInput)
+
+	if got := TapDisabledCount() - before; got != 2 {
+		t.Errorf("Expected 2 more disabled taps, got %d", got)
+	}
+	if len(reasons) != 2 || reasons[0] != TapDisabledByTimeout || reasons[1] != TapDisabledByUserInput {
+		t.Errorf("Unexpected reasons %v", reasons)
+	}
+}
+
+func TestTapDisabledWithoutHandler(t *testing.T) {
+	before := TapDisabledCount()
+	tapDisabled(TapDisabledByTimeout)
+	if TapDisabledCount() != before+1 {
+		t.Error("Expected the disabled tap to be counted")
+	}
+}
+
+func TestTapDisabledReasonString(t *testing.T) {
+	tests := map[TapDisabledReason]string{
+		TapDisabledByTimeout:   "timeout",
+		TapDisabledByUserInput: "user input",
+		TapDisabledReason(1):   "unknown",
+	}
+	for reason, expected := range tests {
+		if got := reason.String(); got != expected {
+			t.Errorf("%d.String() = %q; want %q", int64(reason), got, expected)
+		}
+	}
+}
+
+func TestStopEventTapBeforeStart(t *testing.T) {
+	// A stop arriving before the tap runs, e.g. Ctrl+C during startup, makes
+	// the next start return right away
+	StopEventTap()
+	err := StartEventTap(func() {
+		t.Error("Expected the tap not to start")
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
@@ -2,18 +2,157 @@ package darwin
 
 import (
 	"fmt"
-	"slices"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/Builtbyjb/yay/pkg/lib/chord"
 	"github.com/Builtbyjb/yay/pkg/lib/core"
 )
 
+// ReservedHotkey reports whether hotkey is handled by the listener itself
+// rather than looked up in the settings, and what it does.
+func ReservedHotkey(hotkey string) (string, bool) {
+	action, ok := reservedAction(&core.Hotkeys{}, hotkey)
+	return action.Desc, ok
+}
+
+// reservedAction returns what pressing hotkey does when the listener handles
+// it itself: the macOS actions, or the ones shared with the other platforms.
+// Both ReservedHotkey and resolveHotkey go through it so conflict checks
+// see the chords the listener takes.
+func reservedAction(h *core.Hotkeys, hotkey string) (core.HotkeyAction, bool) {
+	if k, ok := strings.CutPrefix(hotkey, "command+shift+"); ok {
+		if pos, err := strconv.ParseUint(k, 10, 16); err == nil {
+			return core.HotkeyAction{
+				Desc:    fmt.Sprintf("open Dock app %d", pos),
+				Run:     func() error { return LaunchDockApps(uint16(pos)) },
+				Consume: true,
+			}, true
+		}
+	}
+
+	if hotkey == "command+esc" {
+		// Other apps still see the key press
+		return core.HotkeyAction{
+			Desc: "switch to the default desktop",
+			Run: func() error {
+				SwitchToDefaultDesktop()
+				return nil
+			},
+		}, true
+	}
+
+	return h.Reserved(hotkey)
+}
+
+// newHotkeys returns the hotkey handling shared with the other platforms,
+// opening apps with Launch.
+func newHotkeys(db *core.Database, log func(msg string, err error)) *core.Hotkeys {
+	return &core.Hotkeys{
+		DB:     db,
+		Launch: func(s core.Setting) error { return Launch(s.BinName, s.Mode) },
+		Log:    log,
+	}
+}
+
+// resolveHotkey looks up what pressing hotkey does while the app whose bundle
+// id frontmost returns is frontmost: one of the macOS actions of the listener,
+// or what the shared hotkeys do. frontmost is only called for scoped bindings.
+func resolveHotkey(h *core.Hotkeys, hotkey string, frontmost func() string) (core.HotkeyAction, bool, error) {
+	if action, ok := reservedAction(h, hotkey); ok {
+		return action, true, nil
+	}
+	return h.Resolve(hotkey, frontmost)
+}
+
+// Checked before every bound hotkey, replaced in tests
+var secureInputEnabled = SecureInputEnabled
+
+// suspendReason reports why a bound hotkey passes through right now: Secure
+// Event Input is on, or a reason shared with the other platforms. frontmost
+// returns the executable path of the frontmost app. It is empty when the
+// hotkey is handled.
+func suspendReason(h *core.Hotkeys, now time.Time, frontmost func() string) (string, error) {
+	if secureInputEnabled() {
+		return "secure input is on", nil
+	}
+	return h.SuspendReason(now, frontmost)
+}
+
+// LaunchSetting opens the app of setting in its mode and records the launch.
+func LaunchSetting(db *core.Database, setting core.Setting) error {
+	return newHotkeys(db, nil).LaunchSetting(setting)
+}
+
+// TriggerHotkey does what pressing hotkey does while the listener runs and
+// the app with bundleId is frontmost, and describes what was done. Like a key
+// press it does nothing but return a *core.SuspendedError while the hotkey
+// passes through, e.g. when hotkeys are paused or the app is excluded.
+func TriggerHotkey(db *core.Database, hotkey string, bundleId string) (string, error) {
+	h := newHotkeys(db, nil)
+	action, ok, err := resolveHotkey(h, hotkey, func() string { return bundleId })
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", core.ErrUnboundHotkey
+	}
+
+	if hotkey != core.PauseToggleHotkey {
+		now := time.Now()
+		if err := h.RefreshPause(now); err != nil {
+			return "", err
+		}
+		reason, err := suspendReason(h, now, func() string { return appPath(db, bundleId) })
+		if err != nil {
+			return "", err
+		}
+		if reason != "" {
+			return action.Desc, &core.SuspendedError{Reason: reason}
+		}
+	}
+	return action.Desc, action.Run()
+}
+
+// appPath returns the executable path of the app with bundleId, or of the
+// frontmost app when bundleId is empty.
+func appPath(db *core.Database, bundleId string) string {
+	if bundleId == "" {
+		path, _, _ := FrontmostApp()
+		return path
+	}
+	settings, err := db.GetAllSettings()
+	if err != nil {
+		return ""
+	}
+	for _, s := range settings {
+		if s.BundleId == bundleId {
+			return filepath.Join(s.Path, s.BinName)
+		}
+	}
+	return ""
+}
+
 // Listener starts the global key event tap. An optional onEvent callback
-// is called for every event (e.g. to forward to a tea.Program).
-// This function blocks forever.
-func Listener(db *core.Database, onEvent func(KeyEvent)) {
-	var mod string
+// is called for every event (e.g. to forward to a tea.Program). What the
+// listener has to report, such as hotkeys being paused, goes to log with err
+// set when something went wrong; log may be nil.
+// This function blocks until StopEventTap is called or returns right away
+// when the tap can't be created.
+func Listener(db *core.Database, onEvent func(KeyEvent), log func(msg string, err error)) error {
+	if log == nil {
+		log = func(string, error) {}
+	}
+
+	h := newHotkeys(db, log)
+	done := make(chan struct{})
+	defer close(done)
+	h.WatchPause(done)
+
+	var matcher chord.Matcher
 	var mu sync.Mutex
 
 	SetKeyHandler(func(event KeyEvent) bool {
@@ -25,72 +164,65 @@ func Listener(db *core.Database, onEvent func(KeyEvent)) {
 		mu.Lock()
 		defer mu.Unlock()
 
-		k, ok := RawToKeyDarwin[event.Keycode]
+		hotkey, ok := matcher.Feed(event)
 		if !ok {
-			return false // unknown key, pass through
+			return false // modifier change or no hotkey, pass through
 		}
 
-		switch event.EventType {
-		case EventFlagsChanged:
-			if slices.Contains(ModifiersMacos, k) {
-				if IsModifierPressed(event.Flags, event.Keycode) {
-					if mod == "" {
-						mod = k
-					} else if (mod == "command") && (k == "shift") {
-						mod = "command+shift"
-					}
-				} else {
-					mod = ""
-				}
+		// Only scoped bindings and exclusions need the frontmost app, find
+		// it at most once and only when they come up
+		var frontmost, bundleId string
+		looked := false
+		lookup := func() {
+			if !looked {
+				frontmost, bundleId, _ = FrontmostApp()
+				looked = true
 			}
+		}
+
+		// Most chords, e.g. shift+a while typing, aren't bound, leave them
+		// before doing anything else
+		action, ok, err := resolveHotkey(h, hotkey, func() string {
+			lookup()
+			return bundleId
+		})
+		if err != nil {
+			log("Couldn't look up "+hotkey, err)
+			return false
+		}
+		if !ok {
 			return false
+		}
 
-		case EventKeyDown:
-			if mod == "" {
+		// The toggle works while paused
+		if hotkey != core.PauseToggleHotkey {
+			reason, err := suspendReason(h, time.Now(), func() string {
+				lookup()
+				return frontmost
+			})
+			if err != nil {
+				log("Couldn't check whether hotkeys are suspended", err)
 				return false
 			}
-
-			if mod == "command+shift" {
-				pos, err := strconv.ParseUint(k, 10, 16)
-				if err == nil {
-					go func() {
-						if err := LaunchDockApps(uint16(pos)); err != nil {
-							fmt.Println("Error launching dock app:", err)
-						}
-					}()
-					return true
-				}
-			}
-
-			hotkey := fmt.Sprintf("%s+%s", mod, k)
-
-			if hotkey == "command+esc" {
-				go func() {
-					SwitchToDefaultDesktop()
-				}()
-			}
-
-			setting, err := db.FindByHotkey(hotkey)
-			if err != nil {
-				fmt.Println("Error fetching setting:", err)
+			if h.PassThrough(reason) {
 				return false
 			}
+		}
 
-			if setting != nil && setting.Enabled {
-				go func() {
-					if err := Launch(setting.BinName, setting.Mode); err != nil {
-						fmt.Println("Error launching application:", err)
-					}
-				}()
-				return true
+		go func() {
+			if err := action.Run(); err != nil {
+				log("Couldn't "+action.Desc, err)
 			}
+		}()
+		return action.Consume
+	})
 
-			return false
-		}
-
-		return false
+	SetTapDisabledHandler(func(reason TapDisabledReason) {
+		log("Key presses were missed while the event tap was off",
+			fmt.Errorf("macOS disabled it by %s, %d times so far", reason, TapDisabledCount()))
 	})
 
-	fmt.Println("Listening for global keyboard events... (Ctrl+C to quit)")
-	StartEventTap()
+	return StartEventTap(func() {
+		log("Listening for global keyboard events", nil)
+	})
 }
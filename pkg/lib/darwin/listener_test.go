@@ -0,0 +1,176 @@
+package darwin
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
+func TestReservedHotkey(t *testing.T) {
+	tests := []struct {
+		hotkey   string
+		reserved bool
+	}{
+		{"command+esc", true},
+		{"command+shift+1", true},
+		{"command+shift+9", true},
+		{"command+shift+a", false},
+		{"command+1", false},
+		{"control+esc", false},
+		{"command+shift+esc", true},
+	}
+	for _, tc := range tests {
+		if _, got := ReservedHotkey(tc.hotkey); got != tc.reserved {
+			t.Errorf("ReservedHotkey(%q) = %v, want %v", tc.hotkey, got, tc.reserved)
+		}
+	}
+}
+
+func TestReservedHotkeyMatchesListener(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	h := newHotkeys(db, nil)
+	for _, hotkey := range []string{"command+esc", "command+shift+3", "command+shift+esc", "command+shift+a"} {
+		desc, reserved := ReservedHotkey(hotkey)
+		action, ok, err := resolveHotkey(h, hotkey, nil)
+		if err != nil {
+			t.Fatalf("resolveHotkey(%q) returned error: %v", hotkey, err)
+		}
+		if ok != reserved || action.Desc != desc {
+			t.Errorf("%s: ReservedHotkey = %q, %v but the listener does %q, %v", hotkey, desc, reserved, action.Desc, ok)
+		}
+	}
+}
+
+func TestResolveHotkey(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	if err := db.Insert("Firefox", "/Applications/Firefox.app", "", sql.NullString{String: "command+1", Valid: true}, "default", true); err != nil {
+		t.Fatalf("Failed to insert setting: %v", err)
+	}
+	if err := db.Insert("Notes", "/Applications/Notes.app", "", sql.NullString{String: "command+2", Valid: true}, "default", false); err != nil {
+		t.Fatalf("Failed to insert setting: %v", err)
+	}
+
+	tests := []struct {
+		hotkey  string
+		bound   bool
+		desc    string
+		consume bool
+	}{
+		{"command+1", true, "open Firefox", true},
+		{"command+2", false, "", false}, // disabled
+		{"command+3", false, "", false},
+		{"command+shift+4", true, "open Dock app 4", true},
+		{"command+esc", true, "switch to the default desktop", false},
+	}
+	for _, tc := range tests {
+		action, ok, err := resolveHotkey(newHotkeys(db, nil), tc.hotkey, func() string { return "" })
+		if err != nil {
+			t.Fatalf("resolveHotkey(%q) returned error: %v", tc.hotkey, err)
+		}
+		if ok != tc.bound || action.Desc != tc.desc || action.Consume != tc.consume {
+			t.Errorf("resolveHotkey(%q) = %q, %v, consume %v, want %q, %v, consume %v",
+				tc.hotkey, action.Desc, ok, action.Consume, tc.desc, tc.bound, tc.consume)
+		}
+	}
+}
+
+func TestTriggerHotkeyUnbound(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	if _, err := TriggerHotkey(db, "command+9", ""); err != core.ErrUnboundHotkey {
+		t.Errorf("Expected ErrUnboundHotkey, got %v", err)
+	}
+}
+
+func TestTriggerHotkeyInExcludedApp(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+	secureInputEnabled = func() bool { return false }
+	defer func() { secureInputEnabled = SecureInputEnabled }()
+
+	settings, err := db.Refresh([]core.App{
+		{Name: "Notes", Path: "/Applications/Notes.app/Contents/MacOS", BinName: "Notes"},
+		{Name: "Safari", Path: "/Applications/Safari.app/Contents/MacOS", BinName: "Safari", BundleId: "com.apple.Safari"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to refresh: %v", err)
+	}
+	notes, safari := settings[0], settings[1]
+	if err := db.SetScope(notes.Id, core.Scope{Only: []string{"com.apple.Safari"}}); err != nil {
+		t.Fatalf("Failed to set scope: %v", err)
+	}
+	if err := db.UpdateHotkey(notes.Id, sql.NullString{String: "command+1", Valid: true}); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+	if err := db.Exclude(safari.Id); err != nil {
+		t.Fatalf("Failed to exclude: %v", err)
+	}
+
+	// Testing the binding goes through the same checks as a key press
+	desc, err := TriggerHotkey(db, "command+1", "com.apple.Safari")
+	var suspended *core.SuspendedError
+	if !errors.As(err, &suspended) || suspended.Reason != "Safari is excluded" {
+		t.Errorf("Expected the hotkey to pass through in Safari, got %q, %v", desc, err)
+	}
+}
+
+func TestSuspendReasonSecureInput(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	secure := false
+	secureInputEnabled = func() bool { return secure }
+	defer func() { secureInputEnabled = SecureInputEnabled }()
+
+	h := newHotkeys(db, nil)
+	frontmost := func() string { return "/Applications/Safari.app/Contents/MacOS/Safari" }
+	if reason, err := suspendReason(h, time.Now(), frontmost); err != nil || reason != "" {
+		t.Errorf("suspendReason = %q, %v, want no reason", reason, err)
+	}
+
+	secure = true
+	if reason, err := suspendReason(h, time.Now(), frontmost); err != nil || reason != "secure input is on" {
+		t.Errorf("suspendReason = %q, %v, want %q", reason, err, "secure input is on")
+	}
+}
+
+func TestResolveScopedHotkey(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	for _, name := range []string{"Firefox", "Notes"} {
+		if err := db.Insert(name, "/Applications/"+name+".app", "", sql.NullString{}, "default", true); err != nil {
+			t.Fatalf("Failed to insert setting: %v", err)
+		}
+	}
+	firefox, _ := db.FindApp("Firefox")
+	notes, _ := db.FindApp("Notes")
+	if err := db.UpdateHotkey(firefox.Id, sql.NullString{String: "command+1", Valid: true}); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+	if err := db.SetScope(notes.Id, core.Scope{Only: []string{"com.apple.Safari"}}); err != nil {
+		t.Fatalf("Failed to set scope: %v", err)
+	}
+	if err := db.UpdateHotkey(notes.Id, sql.NullString{String: "command+1", Valid: true}); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+
+	for bundleId, expected := range map[string]string{
+		"":                 "open Firefox",
+		"com.apple.Safari": "open Notes",
+		"com.apple.Mail":   "open Firefox",
+	} {
+		action, ok, err := resolveHotkey(newHotkeys(db, nil), "command+1", func() string { return bundleId })
+		if err != nil || !ok || action.Desc != expected {
+			t.Errorf("resolveHotkey in %q = %q, %v, %v, want %q", bundleId, action.Desc, ok, err, expected)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package darwin
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+
+	"github.com/Builtbyjb/yay/pkg/lib/chord"
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+	"howett.net/plist"
+)
+
+// Keycode used by the symbolic hotkeys plist for unassigned shortcuts
+const unassignedKeycode = 65535
+
+type symbolicHotkey struct {
+	Enabled bool `plist:"enabled"`
+	Value   struct {
+		Parameters []int  `plist:"parameters"`
+		Type       string `plist:"type"`
+	} `plist:"value"`
+}
+
+type symbolicHotkeysPlist struct {
+	AppleSymbolicHotKeys map[string]symbolicHotkey `plist:"AppleSymbolicHotKeys"`
+}
+
+// Names of the well-known entries of com.apple.symbolichotkeys.plist
+var symbolicHotkeyNames = map[int]string{
+	7:   "Move focus to the menu bar",
+	8:   "Move focus to the Dock",
+	9:   "Move focus to active or next window",
+	10:  "Move focus to the window toolbar",
+	11:  "Move focus to the floating window",
+	27:  "Move focus to next window",
+	28:  "Save picture of screen as a file",
+	29:  "Copy picture of screen to the clipboard",
+	30:  "Save picture of selected area as a file",
+	31:  "Copy picture of selected area to the clipboard",
+	32:  "Mission Control",
+	33:  "Application windows",
+	36:  "Show Desktop",
+	60:  "Select the previous input source",
+	61:  "Select next source in Input menu",
+	64:  "Show Spotlight search",
+	65:  "Show Finder search window",
+	79:  "Move left a space",
+	81:  "Move right a space",
+	118: "Switch to Desktop 1",
+	119: "Switch to Desktop 2",
+	120: "Switch to Desktop 3",
+	121: "Switch to Desktop 4",
+	122: "Switch to Desktop 5",
+	123: "Switch to Desktop 6",
+	124: "Switch to Desktop 7",
+	125: "Switch to Desktop 8",
+	126: "Switch to Desktop 9",
+	160: "Show Launchpad",
+	162: "Turn Dock hiding on/off",
+	163: "Show Notification Center",
+	175: "Turn Do Not Disturb on/off",
+	181: "Save picture of the Touch Bar as a file",
+	184: "Screenshot and recording options",
+}
+
+// Shortcuts enabled on a fresh install, as [keycode, modifier flags]. Entries
+// in the user's plist take precedence.
+var defaultSymbolicHotkeys = map[int][2]int{
+	27:  {50, 0x100000},                       // command+`
+	28:  {20, 0x100000 | 0x020000},            // command+shift+3
+	29:  {20, 0x100000 | 0x040000 | 0x020000}, // command+control+shift+3
+	30:  {21, 0x100000 | 0x020000},            // command+shift+4
+	31:  {21, 0x100000 | 0x040000 | 0x020000}, // command+control+shift+4
+	32:  {126, 0x040000},                      // control+up arrow
+	33:  {125, 0x040000},                      // control+down arrow
+	60:  {49, 0x040000},                       // control+space
+	61:  {49, 0x040000 | 0x080000},            // control+option+space
+	64:  {49, 0x100000},                       // command+space
+	65:  {49, 0x100000 | 0x080000},            // command+option+space
+	79:  {123, 0x040000},                      // control+left arrow
+	81:  {124, 0x040000},                      // control+right arrow
+	184: {23, 0x100000 | 0x020000},            // command+shift+5
+}
+
+// SystemShortcuts returns the enabled macOS system shortcuts. When the
+// user's symbolic hotkeys can't be read, the defaults are returned.
+func SystemShortcuts() []core.Shortcut {
+	usr, err := user.Current()
+	if err != nil {
+		return parseSymbolicHotkeys(nil)
+	}
+
+	path := filepath.Join(usr.HomeDir, "Library", "Preferences", "com.apple.symbolichotkeys.plist")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return parseSymbolicHotkeys(nil)
+	}
+
+	return parseSymbolicHotkeys(data)
+}
+
+// parseSymbolicHotkeys merges the shortcuts in a symbolic hotkeys plist with
+// the defaults. Only shortcuts with a known name are reported.
+func parseSymbolicHotkeys(data []byte) []core.Shortcut {
+	entries := make(map[int]symbolicHotkey)
+	for id, def := range defaultSymbolicHotkeys {
+		var entry symbolicHotkey
+		entry.Enabled = true
+		entry.Value.Type = "standard"
+		entry.Value.Parameters = []int{unassignedKeycode, def[0], def[1]}
+		entries[id] = entry
+	}
+
+	if data != nil {
+		var parsed symbolicHotkeysPlist
+		if _, err := plist.Unmarshal(data, &parsed); err == nil {
+			for key, entry := range parsed.AppleSymbolicHotKeys {
+				id, err := strconv.Atoi(key)
+				if err != nil {
+					continue
+				}
+				entries[id] = entry
+			}
+		}
+	}
+
+	shortcuts := []core.Shortcut{}
+	for id, entry := range entries {
+		name, known := symbolicHotkeyNames[id]
+		if !known || !entry.Enabled || len(entry.Value.Parameters) < 3 {
+			continue
+		}
+
+		hotkey, ok := hotkeyFromParameters(entry.Value.Parameters[1], entry.Value.Parameters[2])
+		if !ok {
+			continue
+		}
+		shortcuts = append(shortcuts, core.Shortcut{Hotkey: hotkey, Name: name})
+	}
+
+	return shortcuts
+}
+
+// hotkeyFromParameters converts a keycode and modifier flags, as stored in
+// the symbolic hotkeys plist, into Yay's hotkey notation.
+func hotkeyFromParameters(keycode int, flags int) (string, bool) {
+	if keycode == unassignedKeycode {
+		return "", false
+	}
+	key, ok := chord.RawToKeyDarwin[uint16(keycode)]
+	if !ok {
+		return "", false
+	}
+
+	var mods []string
+	if flags&0x100000 != 0 {
+		mods = append(mods, "command")
+	}
+	if flags&0x040000 != 0 {
+		mods = append(mods, "control")
+	}
+	if flags&0x080000 != 0 {
+		mods = append(mods, "option")
+	}
+	if flags&0x020000 != 0 {
+		mods = append(mods, "shift")
+	}
+
+	return chord.CanonicalHotkey(mods, key), true
+}
@@ -0,0 +1,115 @@
+package darwin
+
+import (
+	"testing"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
+func shortcutMap(shortcuts []core.Shortcut) map[string]string {
+	m := make(map[string]string)
+	for _, s := range shortcuts {
+		m[s.Hotkey] = s.Name
+	}
+	return m
+}
+
+func TestHotkeyFromParameters(t *testing.T) {
+	tests := []struct {
+		keycode  int
+		flags    int
+		expected string
+		ok       bool
+	}{
+		{49, 0x100000, "command+space", true},
+		{20, 0x100000 | 0x020000, "command+shift+3", true},
+		{126, 0x040000 | 0x800000, "control+up arrow", true},
+		{49, 0x040000 | 0x080000, "control+option+space", true},
+		{unassignedKeycode, 0x100000, "", false},
+		{10, 0x100000, "", false}, // no key for keycode 10
+	}
+	for _, tc := range tests {
+		got, ok := hotkeyFromParameters(tc.keycode, tc.flags)
+		if got != tc.expected || ok != tc.ok {
+			t.Errorf("hotkeyFromParameters(%d, %#x) = %q, %v; want %q, %v", tc.keycode, tc.flags, got, ok, tc.expected, tc.ok)
+		}
+	}
+}
+
+func TestParseSymbolicHotkeysDefaults(t *testing.T) {
+	shortcuts := shortcutMap(parseSymbolicHotkeys(nil))
+
+	if shortcuts["command+space"] != "Show Spotlight search" {
+		t.Errorf("Expected Spotlight default, got %q", shortcuts["command+space"])
+	}
+	if shortcuts["command+shift+4"] == "" {
+		t.Error("Expected screenshot default")
+	}
+}
+
+func TestParseSymbolicHotkeysOverridesDefaults(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>AppleSymbolicHotKeys</key>
+	<dict>
+		<key>64</key>
+		<dict>
+			<key>enabled</key>
+			<false/>
+			<key>value</key>
+			<dict>
+				<key>parameters</key>
+				<array><integer>32</integer><integer>49</integer><integer>1048576</integer></array>
+				<key>type</key>
+				<string>standard</string>
+			</dict>
+		</dict>
+		<key>118</key>
+		<dict>
+			<key>enabled</key>
+			<true/>
+			<key>value</key>
+			<dict>
+				<key>parameters</key>
+				<array><integer>65535</integer><integer>18</integer><integer>262144</integer></array>
+				<key>type</key>
+				<string>standard</string>
+			</dict>
+		</dict>
+		<key>9999</key>
+		<dict>
+			<key>enabled</key>
+			<true/>
+			<key>value</key>
+			<dict>
+				<key>parameters</key>
+				<array><integer>65535</integer><integer>0</integer><integer>1048576</integer></array>
+				<key>type</key>
+				<string>standard</string>
+			</dict>
+		</dict>
+	</dict>
+</dict>
+</plist>`)
+
+	shortcuts := shortcutMap(parseSymbolicHotkeys(data))
+
+	if _, ok := shortcuts["command+space"]; ok {
+		t.Error("Expected disabled Spotlight shortcut to be dropped")
+	}
+	if shortcuts["control+1"] != "Switch to Desktop 1" {
+		t.Errorf("Expected Switch to Desktop 1, got %q", shortcuts["control+1"])
+	}
+	if _, ok := shortcuts["command+a"]; ok {
+		t.Error("Expected unknown shortcut ids to be ignored")
+	}
+}
+
+func TestParseSymbolicHotkeysInvalidPlist(t *testing.T) {
+	shortcuts := parseSymbolicHotkeys([]byte("not a plist"))
+	if len(shortcuts) != len(parseSymbolicHotkeys(nil)) {
+		t.Error("Expected defaults for an unreadable plist")
+	}
+}
@@ -12,7 +12,21 @@ import (
 )
 
 type infoPlist struct {
-	CFBundleExecutable string `plist:"CFBundleExecutable"`
+	CFBundleExecutable         string `plist:"CFBundleExecutable"`
+	CFBundleDisplayName        string `plist:"CFBundleDisplayName"`
+	CFBundleName               string `plist:"CFBundleName"`
+	CFBundleShortVersionString string `plist:"CFBundleShortVersionString"`
+	CFBundleIdentifier         string `plist:"CFBundleIdentifier"`
+	CFBundleIconFile           string `plist:"CFBundleIconFile"`
+	LSApplicationCategoryType  string `plist:"LSApplicationCategoryType"`
+}
+
+// displayName prefers the localized display name over the short bundle name.
+func (i infoPlist) displayName() string {
+	if i.CFBundleDisplayName != "" {
+		return i.CFBundleDisplayName
+	}
+	return i.CFBundleName
 }
 
 func GetSettings(database core.Database, dirs []string) ([]core.Setting, error) {
@@ -28,25 +42,35 @@ func getBinaryPath(dir string, appName string) string {
 	return filepath.Join(dir, appName, "Contents", "MacOS")
 }
 
-func getIconPath(dir string, appName string) string {
-	files, err := os.ReadDir(dir)
+// getIconPath resolves the bundle's icon inside Contents/Resources. iconFile
+// is the CFBundleIconFile value, which may omit the .icns extension. When it
+// is empty the first .icns file in the resources directory is used instead.
+func getIconPath(dir string, appName string, iconFile string) string {
+	resourcesDir := filepath.Join(dir, appName, "Contents", "Resources")
+
+	if iconFile != "" {
+		if filepath.Ext(iconFile) == "" {
+			iconFile += ".icns"
+		}
+		iconPath := filepath.Join(resourcesDir, iconFile)
+		if _, err := os.Stat(iconPath); err != nil {
+			return ""
+		}
+		return iconPath
+	}
+
+	files, err := os.ReadDir(resourcesDir)
 	if err != nil {
-		fmt.Printf("Error reading directory %s: %v\n", dir, err)
 		return ""
 	}
 
-	var iconName string
 	for _, file := range files {
 		if strings.HasSuffix(file.Name(), ".icns") {
-			iconName = file.Name()
+			return filepath.Join(resourcesDir, file.Name())
 		}
 	}
 
-	if iconName == "" {
-		return ""
-	}
-
-	return filepath.Join(dir, appName, "Contents", "Resources", iconName)
+	return ""
 }
 
 func getApps(dirs []string) []core.App {
@@ -61,10 +85,16 @@ func getApps(dirs []string) []core.App {
 		for _, entry := range entries {
 			appName, ok := strings.CutSuffix(entry.Name(), ".app")
 			if ok {
+				info := getInfoPlist(dir, entry.Name())
 				apps = append(apps, core.App{
-					Name:    appName,
-					BinName: getBinaryName(dir, entry.Name()),
-					Path:    getBinaryPath(dir, entry.Name()),
+					Name:        appName,
+					BinName:     info.CFBundleExecutable,
+					Path:        getBinaryPath(dir, entry.Name()),
+					DisplayName: info.displayName(),
+					Version:     info.CFBundleShortVersionString,
+					BundleId:    info.CFBundleIdentifier,
+					IconPath:    getIconPath(dir, entry.Name(), info.CFBundleIconFile),
+					Category:    info.LSApplicationCategoryType,
 				})
 			}
 		}
@@ -72,58 +102,94 @@ func getApps(dirs []string) []core.App {
 	return apps
 }
 
-func GetDatabasePath() (string, error) {
+// GetSupportDir returns Yay's directory inside Application Support, creating
+// it if needed.
+func GetSupportDir() (string, error) {
 	usr, err := user.Current()
 	if err != nil {
 		return "", err
 	}
-	dbPath := filepath.Join(usr.HomeDir, "Library", "Application Support", "Yay", "db.sqlite3")
+	supportDir := filepath.Join(usr.HomeDir, "Library", "Application Support", "Yay")
 
-	dbDir := filepath.Dir(dbPath)
 	// 0755
 	// │││└─ Others: 5 (read + execute)
 	// ││└── Group: 5 (read + execute)
 	// │└─── Owner: 7 (read + write + execute)
 	// └──── Octal prefix: 0
-	err = os.MkdirAll(dbDir, 0755)
+	err = os.MkdirAll(supportDir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	return supportDir, nil
+}
+
+func GetDatabasePath() (string, error) {
+	supportDir, err := GetSupportDir()
 	if err != nil {
 		return "", err
 	}
 
-	return dbPath, nil
+	return filepath.Join(supportDir, "db.sqlite3"), nil
 }
 
-func getBinaryName(dir string, appName string) string {
+// GetLogPath returns the path of the log file errors are written to.
+func GetLogPath() (string, error) {
+	supportDir, err := GetSupportDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(supportDir, "yay.log"), nil
+}
+
+// GetThemePath returns the path of the user theme file.
+func GetThemePath() (string, error) {
+	supportDir, err := GetSupportDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(supportDir, "theme.json"), nil
+}
+
+// GetKeysPath returns the path of the file overriding the TUI key bindings.
+func GetKeysPath() (string, error) {
+	supportDir, err := GetSupportDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(supportDir, "keys.json"), nil
+}
+
+// GetIconCacheDir returns the directory converted app icons are cached in.
+func GetIconCacheDir() (string, error) {
+	supportDir, err := GetSupportDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(supportDir, "icons"), nil
+}
+
+// getInfoPlist reads the bundle's Info.plist. A missing or malformed plist
+// yields an empty infoPlist so the app is still listed.
+func getInfoPlist(dir string, appName string) infoPlist {
+	var info infoPlist
+
 	filePath := filepath.Join(dir, appName, "Contents", "Info.plist")
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		fmt.Printf("Error reading file: %v\n", err)
-		return ""
+		return info
 	}
 
-	var info infoPlist
 	_, err = plist.Unmarshal(data, &info)
 	if err != nil {
 		fmt.Printf("Error unmarshaling plist: %v\n", err)
-		return ""
+		return infoPlist{}
 	}
 
-	return info.CFBundleExecutable
-}
-
-// isModifierPressed checks whether the modifier flag corresponding to the
-// given keycode is currently set in the CGEvent flags bitmask.
-func IsModifierPressed(flags uint64, keycode uint16) bool {
-	switch keycode {
-	case 55, 54: // l-command, r-command
-		return flags&0x100000 != 0
-	case 56, 60: // l-shift, r-shift
-		return flags&0x020000 != 0
-	case 58, 61: // l-option, r-option
-		return flags&0x080000 != 0
-	case 59: // control
-		return flags&0x040000 != 0
-	default:
-		return false
-	}
+	return info
 }
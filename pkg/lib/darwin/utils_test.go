@@ -81,62 +81,63 @@ func TestGetBinaryPathNestedDir(t *testing.T) {
 // ---------------------------------------------------------------------------
 
 func TestGetIconPathNonExistentDir(t *testing.T) {
-	result := getIconPath("/nonexistent/path/that/does/not/exist", "SomeApp")
+	result := getIconPath("/nonexistent/path/that/does/not/exist", "SomeApp.app", "")
 	if result != "" {
 		t.Errorf("Expected empty string for non-existent dir, got %q", result)
 	}
 }
 
 func TestGetIconPathDirWithNoIcnsFiles(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	// Create some non-icns files in the dir
-	os.WriteFile(filepath.Join(tmpDir, "readme.txt"), []byte("hi"), 0644)
-	os.WriteFile(filepath.Join(tmpDir, "icon.png"), []byte("png"), 0644)
+	tmpDir := createTempAppDir(t, map[string]string{
+		"SomeApp": "icon.png",
+	})
 
-	result := getIconPath(tmpDir, "SomeApp")
+	result := getIconPath(tmpDir, "SomeApp.app", "")
 	if result != "" {
 		t.Errorf("Expected empty string when no .icns files, got %q", result)
 	}
 }
 
 func TestGetIconPathFindsIcnsFile(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	// Place an .icns file directly in tmpDir (this is what the function reads)
-	os.WriteFile(filepath.Join(tmpDir, "AppIcon.icns"), []byte("icon"), 0644)
+	tmpDir := createTempAppDir(t, map[string]string{
+		"MyApp": "AppIcon.icns",
+	})
 
-	result := getIconPath(tmpDir, "MyApp")
-	expected := tmpDir + "/MyApp/Contents/Resources/AppIcon.icns"
+	result := getIconPath(tmpDir, "MyApp.app", "")
+	expected := tmpDir + "/MyApp.app/Contents/Resources/AppIcon.icns"
 	if result != expected {
 		t.Errorf("Expected %q, got %q", expected, result)
 	}
 }
 
-func TestGetIconPathMultipleIcnsFilesReturnsLast(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	// The function iterates all files and keeps overwriting iconName,
-	// so the last .icns file alphabetically by ReadDir order wins.
-	os.WriteFile(filepath.Join(tmpDir, "Alpha.icns"), []byte("icon"), 0644)
-	os.WriteFile(filepath.Join(tmpDir, "Beta.icns"), []byte("icon"), 0644)
-	os.WriteFile(filepath.Join(tmpDir, "Zeta.icns"), []byte("icon"), 0644)
+func TestGetIconPathUsesBundleIconFile(t *testing.T) {
+	tmpDir := createTempAppDir(t, map[string]string{
+		"MyApp": "Other.icns",
+	})
+	resourcesDir := filepath.Join(tmpDir, "MyApp.app", "Contents", "Resources")
+	os.WriteFile(filepath.Join(resourcesDir, "AppIcon.icns"), []byte("icon"), 0644)
 
-	result := getIconPath(tmpDir, "TestApp")
+	// CFBundleIconFile frequently omits the extension
+	result := getIconPath(tmpDir, "MyApp.app", "AppIcon")
+	expected := resourcesDir + "/AppIcon.icns"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
 
-	// os.ReadDir returns entries sorted by name, so "Zeta.icns" is last
-	expected := tmpDir + "/TestApp/Contents/Resources/Zeta.icns"
+	result = getIconPath(tmpDir, "MyApp.app", "AppIcon.icns")
 	if result != expected {
 		t.Errorf("Expected %q, got %q", expected, result)
 	}
 }
 
-func TestGetIconPathEmptyDir(t *testing.T) {
-	tmpDir := t.TempDir()
+func TestGetIconPathMissingBundleIconFile(t *testing.T) {
+	tmpDir := createTempAppDir(t, map[string]string{
+		"MyApp": "Other.icns",
+	})
 
-	result := getIconPath(tmpDir, "SomeApp")
+	result := getIconPath(tmpDir, "MyApp.app", "Missing")
 	if result != "" {
-		t.Errorf("Expected empty string for empty dir, got %q", result)
+		t.Errorf("Expected empty string for missing icon file, got %q", result)
 	}
 }
 
@@ -282,6 +283,66 @@ func TestGetAppsMixedAppAndNonAppEntries(t *testing.T) {
 	}
 }
 
+func TestGetAppsReadsInfoPlist(t *testing.T) {
+	tmpDir := createTempAppDir(t, map[string]string{
+		"Editor": "Editor.icns",
+	})
+
+	info := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleExecutable</key>
+	<string>editor</string>
+	<key>CFBundleName</key>
+	<string>Editor</string>
+	<key>CFBundleDisplayName</key>
+	<string>Code Editor</string>
+	<key>CFBundleShortVersionString</key>
+	<string>1.2.3</string>
+	<key>CFBundleIdentifier</key>
+	<string>com.example.editor</string>
+	<key>CFBundleIconFile</key>
+	<string>Editor</string>
+	<key>LSApplicationCategoryType</key>
+	<string>public.app-category.developer-tools</string>
+</dict>
+</plist>`
+	plistPath := filepath.Join(tmpDir, "Editor.app", "Contents", "Info.plist")
+	if err := os.WriteFile(plistPath, []byte(info), 0644); err != nil {
+		t.Fatalf("Failed to write Info.plist: %v", err)
+	}
+
+	apps := getApps([]string{tmpDir})
+	if len(apps) != 1 {
+		t.Fatalf("Expected 1 app, got %d", len(apps))
+	}
+
+	app := apps[0]
+	if app.Name != "Editor" {
+		t.Errorf("Expected name %q, got %q", "Editor", app.Name)
+	}
+	if app.BinName != "editor" {
+		t.Errorf("Expected bin name %q, got %q", "editor", app.BinName)
+	}
+	if app.DisplayName != "Code Editor" {
+		t.Errorf("Expected display name %q, got %q", "Code Editor", app.DisplayName)
+	}
+	if app.Version != "1.2.3" {
+		t.Errorf("Expected version %q, got %q", "1.2.3", app.Version)
+	}
+	if app.BundleId != "com.example.editor" {
+		t.Errorf("Expected bundle id %q, got %q", "com.example.editor", app.BundleId)
+	}
+	if app.Category != "public.app-category.developer-tools" {
+		t.Errorf("Expected category %q, got %q", "public.app-category.developer-tools", app.Category)
+	}
+	expectedIcon := tmpDir + "/Editor.app/Contents/Resources/Editor.icns"
+	if app.IconPath != expectedIcon {
+		t.Errorf("Expected icon path %q, got %q", expectedIcon, app.IconPath)
+	}
+}
+
 func TestGetAppsNilDirs(t *testing.T) {
 	apps := getApps(nil)
 	if len(apps) != 0 {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"slices"
 
+	"github.com/Builtbyjb/yay/pkg/lib/chord"
 	"github.com/Builtbyjb/yay/pkg/lib/core"
 	"github.com/Builtbyjb/yay/pkg/lib/darwin"
 )
@@ -43,16 +44,87 @@ func Fetch() (*core.Database, []core.Setting, error) {
 	return db, settings, nil
 }
 
+// GetLogPath returns the path of Yay's error log.
+func GetLogPath() (string, error) {
+	return darwin.GetLogPath()
+}
+
+// GetThemePath returns the path of the user theme file.
+func GetThemePath() (string, error) {
+	return darwin.GetThemePath()
+}
+
+// GetKeysPath returns the path of the file overriding the TUI key bindings.
+func GetKeysPath() (string, error) {
+	return darwin.GetKeysPath()
+}
+
+// CachedIcon returns the path of a small PNG rendition of the app icon at
+// iconPath, converting and caching it on first use.
+func CachedIcon(iconPath string) (string, error) {
+	cacheDir, err := darwin.GetIconCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return darwin.CacheIcon(cacheDir, iconPath, darwin.IconSize)
+}
+
+// NewConflictChecker returns a checker aware of the chords the listener
+// reserves and of the enabled macOS system shortcuts.
+func NewConflictChecker() core.ConflictChecker {
+	return core.ConflictChecker{
+		Reserved: darwin.ReservedHotkey,
+		System:   darwin.SystemShortcuts(),
+	}
+}
+
+// LaunchSetting opens the app of setting the way the listener does and
+// records the launch.
+func LaunchSetting(db *core.Database, setting core.Setting) error {
+	return darwin.LaunchSetting(db, setting)
+}
+
+// TriggerHotkey does what pressing hotkey does while the listener runs and
+// the app with bundleId is frontmost, and describes what was done.
+func TriggerHotkey(db *core.Database, hotkey string, bundleId string) (string, error) {
+	return darwin.TriggerHotkey(db, hotkey, bundleId)
+}
+
 func RawcodeToString(rawcode uint16) (string, error) {
-	key, ok := darwin.RawToKeyDarwin[rawcode]
+	key, ok := chord.RawToKeyDarwin[rawcode]
 	if !ok {
 		return "", fmt.Errorf("unknown rawcode: %d", rawcode)
 	}
 	return key, nil
 }
 
-func KeyEventListener(db *core.Database, onEvent func(KeyEvent)) {
-	darwin.Listener(db, func(de darwin.KeyEvent) {
+// ErrEventTapFailed is returned by KeyEventListener when key events can't be
+// listened to, see Doctor.
+var ErrEventTapFailed = darwin.ErrEventTapFailed
+
+// Doctor checks the permissions and settings Yay needs and its database.
+// With request set the user is asked for missing permissions.
+func Doctor(request bool) []core.Check {
+	checks := darwin.Doctor(request)
+
+	dbPath, err := darwin.GetDatabasePath()
+	if err != nil {
+		return append(checks, core.Check{
+			Name:   "Database",
+			Status: core.CheckFailed,
+			Detail: err.Error(),
+			Fix:    "Make sure ~/Library/Application Support/Yay can be created",
+		})
+	}
+	return append(checks, core.CheckDatabase(dbPath))
+}
+
+// KeyEventListener listens for hotkeys until StopEventTap is called. It
+// returns ErrEventTapFailed right away when key events can't be listened to.
+// What the listener reports goes to log, with err set for problems.
+func KeyEventListener(db *core.Database, onEvent func(KeyEvent), log func(msg string, err error)) error {
+	return darwin.Listener(db, func(de darwin.KeyEvent) {
 		if onEvent != nil {
 			onEvent(KeyEvent{
 				Keycode:   de.Keycode,
@@ -60,9 +132,14 @@ func KeyEventListener(db *core.Database, onEvent func(KeyEvent)) {
 				EventType: de.EventType,
 			})
 		}
-	})
+	}, log)
+}
+
+// StopEventTap makes a running KeyEventListener return.
+func StopEventTap() {
+	darwin.StopEventTap()
 }
 
 func VerifiedModifier(key string) bool {
-	return slices.Contains(darwin.ModifiersMacos, key)
+	return slices.Contains(chord.ModifiersMacos, key)
 }
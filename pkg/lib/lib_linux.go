@@ -0,0 +1,173 @@
+//go:build linux
+
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+	"github.com/Builtbyjb/yay/pkg/lib/linux"
+)
+
+func GetDatabase() (*core.Database, error) {
+
+	dbPath, err := linux.GetDatabasePath()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := core.NewDatabase(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Init(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func Fetch() (*core.Database, []core.Setting, error) {
+	db, err := GetDatabase()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dirs := linux.AppDirectories()
+	settings, err := linux.GetSettings(*db, dirs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, settings, nil
+}
+
+// GetLogPath returns the path of Yay's error log.
+func GetLogPath() (string, error) {
+	return linux.GetLogPath()
+}
+
+// GetThemePath returns the path of the user theme file.
+func GetThemePath() (string, error) {
+	return linux.GetThemePath()
+}
+
+// GetKeysPath returns the path of the file overriding the TUI key bindings.
+func GetKeysPath() (string, error) {
+	return linux.GetKeysPath()
+}
+
+// CachedIcon fails on Linux, desktop entries name their icons by theme and
+// the TUI shows none.
+func CachedIcon(iconPath string) (string, error) {
+	return "", errors.New("app icons aren't supported on Linux")
+}
+
+// NewConflictChecker returns a checker aware of the chords the listener
+// reserves. Desktop shortcuts aren't known.
+func NewConflictChecker() core.ConflictChecker {
+	return core.ConflictChecker{
+		Reserved: linux.ReservedHotkey,
+	}
+}
+
+// LaunchSetting opens the app of setting the way the listener does and
+// records the launch.
+func LaunchSetting(db *core.Database, setting core.Setting) error {
+	return linux.LaunchSetting(db, setting)
+}
+
+// TriggerHotkey does what pressing hotkey does while the listener runs, and
+// describes what was done. Linux can't tell the frontmost app, bundleId is
+// ignored.
+func TriggerHotkey(db *core.Database, hotkey string, bundleId string) (string, error) {
+	return linux.TriggerHotkey(db, hotkey)
+}
+
+// RawcodeToString names an evdev key code.
+func RawcodeToString(rawcode uint16) (string, error) {
+	key, ok := linux.RawToKeyLinux[rawcode]
+	if !ok {
+		return "", fmt.Errorf("unknown rawcode: %d", rawcode)
+	}
+	return key, nil
+}
+
+// ErrEventTapFailed is returned by KeyEventListener when none of the
+// listeners can run, see Doctor.
+var ErrEventTapFailed = errors.New("couldn't listen for hotkeys through evdev, X11 or the GlobalShortcuts portal")
+
+// Doctor checks the listeners Yay can use and its database.
+// Linux has no permissions to request, request is ignored.
+func Doctor(request bool) []core.Check {
+	checks := linux.Doctor()
+
+	dbPath, err := linux.GetDatabasePath()
+	if err != nil {
+		return append(checks, core.Check{
+			Name:   "Database",
+			Status: core.CheckFailed,
+			Detail: err.Error(),
+			Fix:    "Make sure ~/.local/share/yay can be created",
+		})
+	}
+	return append(checks, core.CheckDatabase(dbPath))
+}
+
+// KeyEventListener listens for hotkeys until StopEventTap is called. It reads
+// the keyboards through evdev, falls back to X11 grabs without access to
+// them, then to the GlobalShortcuts portal outside of X11. Only evdev reports
+// key events to onEvent, with evdev key codes. It returns ErrEventTapFailed
+// right away when no listener can run.
+// What the listener reports goes to log, with err set for problems.
+func KeyEventListener(db *core.Database, onEvent func(KeyEvent), log func(msg string, err error)) error {
+	if log == nil {
+		log = func(string, error) {}
+	}
+	err := linux.Listener(db, func(le linux.KeyEvent) {
+		if onEvent != nil {
+			onEvent(keyEvent(le))
+		}
+	}, log)
+	if !errors.Is(err, linux.ErrNoKeyboards) {
+		return err
+	}
+
+	log("Keyboards can't be read, grabbing hotkeys through X11", err)
+	err = linux.X11Listener(db, nil, log)
+	if !errors.Is(err, linux.ErrNoDisplay) && !errors.Is(err, linux.ErrNoX11) {
+		return err
+	}
+
+	log("Hotkeys can't be grabbed through X11, binding them through the GlobalShortcuts portal", err)
+	err = linux.PortalListener(db, log)
+	if errors.Is(err, linux.ErrNoPortal) {
+		return fmt.Errorf("%w: %v", ErrEventTapFailed, err)
+	}
+	return err
+}
+
+// keyEvent turns an evdev event into the events of the macOS event tap the
+// TUI records hotkeys from: modifiers change the flags, other keys go down
+// and up.
+func keyEvent(le linux.KeyEvent) KeyEvent {
+	event := KeyEvent{Keycode: le.Code, EventType: EventKeyDown}
+	switch {
+	case slices.Contains(linux.ModifiersLinux, linux.RawToKeyLinux[le.Code]):
+		event.EventType = EventFlagsChanged
+	case le.Value == linux.KeyReleased:
+		event.EventType = EventKeyUp
+	}
+	return event
+}
+
+// StopEventTap makes a running KeyEventListener return.
+func StopEventTap() {
+	linux.StopListener()
+}
+
+func VerifiedModifier(key string) bool {
+	return slices.Contains(linux.ModifiersLinux, key)
+}
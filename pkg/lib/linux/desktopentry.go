@@ -0,0 +1,222 @@
+package linux
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DesktopEntry is the [Desktop Entry] group of a .desktop file, see
+// https://specifications.freedesktop.org/desktop-entry-spec/latest/
+type DesktopEntry struct {
+	File           string // path of the .desktop file
+	Name           string
+	Exec           string
+	TryExec        string
+	Path           string // working directory
+	Icon           string
+	Terminal       bool
+	StartupWMClass string
+}
+
+// Id returns the desktop file id, e.g. "org.mozilla.firefox" for
+// /usr/share/applications/org.mozilla.firefox.desktop.
+func (e DesktopEntry) Id() string {
+	return strings.TrimSuffix(filepath.Base(e.File), ".desktop")
+}
+
+// ReadDesktopEntry parses the .desktop file at path. Localized keys, other
+// groups and unknown keys are ignored.
+func ReadDesktopEntry(path string) (DesktopEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return DesktopEntry{}, err
+	}
+	defer f.Close()
+
+	entry := DesktopEntry{File: path}
+	group := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			group = line[1 : len(line)-1]
+			continue
+		}
+		if group != "Desktop Entry" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = unescapeDesktopValue(strings.TrimSpace(value))
+		switch key {
+		case "Name":
+			entry.Name = value
+		case "Exec":
+			entry.Exec = value
+		case "TryExec":
+			entry.TryExec = value
+		case "Path":
+			entry.Path = value
+		case "Icon":
+			entry.Icon = value
+		case "Terminal":
+			entry.Terminal = value == "true"
+		case "StartupWMClass":
+			entry.StartupWMClass = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return DesktopEntry{}, err
+	}
+	if entry.Exec == "" {
+		return DesktopEntry{}, fmt.Errorf("%s has no Exec key", path)
+	}
+	return entry, nil
+}
+
+// unescapeDesktopValue undoes the escapes of string values: \s, \n, \t, \r
+// and \\. Others are kept for the Exec quoting rules.
+func unescapeDesktopValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 's':
+			b.WriteByte(' ')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// ErrInvalidExec is returned for an Exec key that breaks the quoting rules.
+var ErrInvalidExec = errors.New("invalid Exec key")
+
+// splitExec splits an Exec key into arguments. Arguments may be quoted with
+// double quotes, inside which ", `, $ and \ are escaped with a backslash.
+func splitExec(exec string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg, quoted := false, false
+
+	for i := 0; i < len(exec); i++ {
+		c := exec[i]
+		switch {
+		case quoted && c == '\\':
+			if i+1 == len(exec) || !strings.ContainsRune("\"`$\\", rune(exec[i+1])) {
+				return nil, fmt.Errorf("%w: stray backslash in %q", ErrInvalidExec, exec)
+			}
+			i++
+			arg.WriteByte(exec[i])
+		case c == '"':
+			quoted = !quoted
+			inArg = true
+		case !quoted && (c == ' ' || c == '\t'):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteByte(c)
+			inArg = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("%w: unterminated quote in %q", ErrInvalidExec, exec)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%w: empty", ErrInvalidExec)
+	}
+	return args, nil
+}
+
+// ExecArgs returns the command line of entry, opening files, which may be
+// paths or URLs. %f and %u take the first file, %F and %U take them all,
+// %i, %c and %k expand to the icon, name and .desktop file and deprecated
+// field codes are dropped.
+func (e DesktopEntry) ExecArgs(files []string) ([]string, error) {
+	args, err := splitExec(e.Exec)
+	if err != nil {
+		return nil, err
+	}
+
+	var expanded []string
+	for _, arg := range args {
+		// The list field codes have to be arguments of their own
+		switch arg {
+		case "%F", "%U":
+			expanded = append(expanded, files...)
+			continue
+		case "%i":
+			if e.Icon != "" {
+				expanded = append(expanded, "--icon", e.Icon)
+			}
+			continue
+		}
+
+		var b strings.Builder
+		empty := false // the argument was only a field code with no value
+		for i := 0; i < len(arg); i++ {
+			if arg[i] != '%' {
+				b.WriteByte(arg[i])
+				continue
+			}
+			if i+1 == len(arg) {
+				return nil, fmt.Errorf("%w: trailing %% in %q", ErrInvalidExec, e.Exec)
+			}
+			i++
+			switch arg[i] {
+			case '%':
+				b.WriteByte('%')
+			case 'f', 'u':
+				if len(files) > 0 {
+					b.WriteString(files[0])
+				} else if arg == "%"+string(arg[i]) {
+					empty = true
+				}
+			case 'c':
+				b.WriteString(e.Name)
+			case 'k':
+				b.WriteString(e.File)
+			case 'd', 'D', 'n', 'N', 'v', 'm':
+				// Deprecated
+				empty = arg == "%"+string(arg[i])
+			default:
+				return nil, fmt.Errorf("%w: unknown field code %%%c in %q", ErrInvalidExec, arg[i], e.Exec)
+			}
+		}
+		if !empty {
+			expanded = append(expanded, b.String())
+		}
+	}
+	return expanded, nil
+}
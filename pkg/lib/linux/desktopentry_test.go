@@ -0,0 +1,108 @@
+package linux
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeDesktopFile(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestReadDesktopEntry(t *testing.T) {
+	path := writeDesktopFile(t, t.TempDir(), "org.mozilla.firefox.desktop", `# Firefox
+[Desktop Entry]
+Version=1.0
+Name=Firefox
+Name[fr]=Navigateur Firefox
+Exec=/usr/lib/firefox/firefox %u
+Icon=firefox
+Terminal=false
+StartupWMClass=firefox
+Path=/tmp\sdir
+
+[Desktop Action new-window]
+Name=New Window
+Exec=/usr/lib/firefox/firefox --new-window %u
+`)
+
+	entry, err := ReadDesktopEntry(path)
+	if err != nil {
+		t.Fatalf("ReadDesktopEntry returned error: %v", err)
+	}
+	expected := DesktopEntry{
+		File:           path,
+		Name:           "Firefox",
+		Exec:           "/usr/lib/firefox/firefox %u",
+		Icon:           "firefox",
+		StartupWMClass: "firefox",
+		Path:           "/tmp dir",
+	}
+	if entry != expected {
+		t.Errorf("Expected %+v, got %+v", expected, entry)
+	}
+	if entry.Id() != "org.mozilla.firefox" {
+		t.Errorf("Expected id org.mozilla.firefox, got %q", entry.Id())
+	}
+
+	noExec := writeDesktopFile(t, t.TempDir(), "broken.desktop", "[Desktop Entry]\nName=Broken\n")
+	if _, err := ReadDesktopEntry(noExec); err == nil {
+		t.Error("Expected an error for an entry without Exec")
+	}
+}
+
+func TestExecArgs(t *testing.T) {
+	entry := DesktopEntry{File: "/usr/share/applications/editor.desktop", Name: "Editor", Icon: "editor"}
+	tests := []struct {
+		exec     string
+		files    []string
+		expected []string
+	}{
+		{"editor", nil, []string{"editor"}},
+		{"editor %f", nil, []string{"editor"}},
+		{"editor %f", []string{"/a b.txt", "/c.txt"}, []string{"editor", "/a b.txt"}},
+		{"editor %U", []string{"file:///a", "https://b"}, []string{"editor", "file:///a", "https://b"}},
+		{"editor %F --flag", nil, []string{"editor", "--flag"}},
+		{"editor --file=%u", nil, []string{"editor", "--file="}},
+		{"editor %i %c %k", nil, []string{"editor", "--icon", "editor", "Editor", "/usr/share/applications/editor.desktop"}},
+		{"editor 100%%", nil, []string{"editor", "100%"}},
+		{"editor %d %m", nil, []string{"editor"}},
+		{`"/opt/My Editor/editor" --title "a \"quoted\" \$name"`, nil, []string{"/opt/My Editor/editor", "--title", `a "quoted" $name`}},
+		{`sh -c "echo \\\\ done"`, nil, []string{"sh", "-c", `echo \\ done`}},
+		{"  editor   -n  ", nil, []string{"editor", "-n"}},
+	}
+	for _, tc := range tests {
+		entry.Exec = tc.exec
+		args, err := entry.ExecArgs(tc.files)
+		if err != nil {
+			t.Errorf("ExecArgs(%q) returned error: %v", tc.exec, err)
+			continue
+		}
+		if !slices.Equal(args, tc.expected) {
+			t.Errorf("ExecArgs(%q) = %q, want %q", tc.exec, args, tc.expected)
+		}
+	}
+}
+
+func TestExecArgsInvalid(t *testing.T) {
+	for _, exec := range []string{
+		`editor "unterminated`,
+		`editor "stray \a"`,
+		"editor %x",
+		"editor 100%",
+		"   ",
+	} {
+		entry := DesktopEntry{Exec: exec}
+		if _, err := entry.ExecArgs(nil); !errors.Is(err, ErrInvalidExec) {
+			t.Errorf("ExecArgs(%q) = %v, want ErrInvalidExec", exec, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package linux
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
+// Doctor checks which of the listeners can run. Only one is needed, so a
+// missing one is a warning.
+func Doctor() []core.Check {
+	return []core.Check{
+		checkKeyboards(sysInputDir),
+		checkX11(os.Getenv("DISPLAY")),
+	}
+}
+
+func checkKeyboards(sysDir string) core.Check {
+	check := core.Check{Name: "Keyboards"}
+
+	paths, err := FindKeyboards(sysDir)
+	readable := 0
+	for _, path := range paths {
+		if f, err := os.Open(path); err == nil {
+			f.Close()
+			readable++
+		}
+	}
+	if err == nil && readable > 0 {
+		check.Detail = fmt.Sprintf("%d of %d readable", readable, len(paths))
+		return check
+	}
+
+	check.Status = core.CheckWarning
+	check.Detail = "none readable, hotkeys fall back to X11 grabs or the GlobalShortcuts portal"
+	check.Fix = "Run sudo usermod -aG input $USER, then log out and back in"
+	return check
+}
+
+func checkX11(display string) core.Check {
+	check := core.Check{Name: "X11"}
+	switch {
+	case !x11Supported:
+		check.Status = core.CheckWarning
+		check.Detail = "not built in"
+		check.Fix = "Rebuild yay with -tags x11 to grab hotkeys under X11"
+	case display == "":
+		check.Status = core.CheckWarning
+		check.Detail = "DISPLAY isn't set, hotkeys can't be grabbed and windows can't be focused"
+	default:
+		check.Detail = "grabs hotkeys on " + display
+	}
+	return check
+}
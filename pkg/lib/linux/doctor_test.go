@@ -0,0 +1,26 @@
+package linux
+
+import (
+	"testing"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
+func TestCheckKeyboards(t *testing.T) {
+	check := checkKeyboards(t.TempDir())
+	if check.Status != core.CheckWarning || check.Fix == "" {
+		t.Errorf("Expected a warning without keyboards, got %+v", check)
+	}
+}
+
+func TestCheckX11(t *testing.T) {
+	check := checkX11("")
+	if check.Status != core.CheckWarning {
+		t.Errorf("Expected a warning without DISPLAY, got %+v", check)
+	}
+
+	check = checkX11(":0")
+	if x11Supported != (check.Status == core.CheckOK) {
+		t.Errorf("Expected X11 to pass only in x11 builds, got %+v", check)
+	}
+}
@@ -0,0 +1,130 @@
+package linux
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InputEvent is a struct input_event read from an evdev device. Only 64-bit
+// platforms are supported, where it is 24 bytes.
+type InputEvent struct {
+	Time  time.Time
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+// Size of a struct input_event on 64-bit platforms
+const inputEventSize = 24
+
+// ReadEvent reads the next event from an evdev device or a recorded stream.
+func ReadEvent(r io.Reader) (InputEvent, error) {
+	var buf [inputEventSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return InputEvent{}, err
+	}
+
+	sec := int64(binary.LittleEndian.Uint64(buf[0:8]))
+	usec := int64(binary.LittleEndian.Uint64(buf[8:16]))
+	return InputEvent{
+		Time:  time.Unix(sec, usec*1000),
+		Type:  binary.LittleEndian.Uint16(buf[16:18]),
+		Code:  binary.LittleEndian.Uint16(buf[18:20]),
+		Value: int32(binary.LittleEndian.Uint32(buf[20:24])),
+	}, nil
+}
+
+// WriteEvent writes an event in the format ReadEvent reads, to record a
+// stream or feed a uinput device.
+func WriteEvent(w io.Writer, ev InputEvent) error {
+	var buf [inputEventSize]byte
+	if !ev.Time.IsZero() {
+		binary.LittleEndian.PutUint64(buf[0:8], uint64(ev.Time.Unix()))
+		binary.LittleEndian.PutUint64(buf[8:16], uint64(ev.Time.Nanosecond()/1000))
+	}
+	binary.LittleEndian.PutUint16(buf[16:18], ev.Type)
+	binary.LittleEndian.PutUint16(buf[18:20], ev.Code)
+	binary.LittleEndian.PutUint32(buf[20:24], uint32(ev.Value))
+	_, err := w.Write(buf[:])
+	return err
+}
+
+// Where the kernel lists input devices
+const sysInputDir = "/sys/class/input"
+
+// ErrNoKeyboards is returned when no keyboard can be read.
+var ErrNoKeyboards = errors.New("no keyboard found in /dev/input, Yay needs to run as a member of the input group")
+
+// FindKeyboards returns the evdev devices that have letter keys, e.g.
+// /dev/input/event3, read from the sysfs input class at sysDir.
+func FindKeyboards(sysDir string) ([]string, error) {
+	entries, err := filepath.Glob(filepath.Join(sysDir, "event*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var keyboards []string
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(entry, "device", "capabilities", "key"))
+		if err != nil {
+			continue
+		}
+		keys, err := parseCapabilities(string(data))
+		if err != nil {
+			continue
+		}
+		if keys.has(KEY_A) && keys.has(KEY_Z) && keys.has(KEY_SPACE) && keys.has(KEY_ENTER) {
+			keyboards = append(keyboards, filepath.Join("/dev/input", filepath.Base(entry)))
+		}
+	}
+	slices.Sort(keyboards)
+	return keyboards, nil
+}
+
+// capabilities is a bitmap of the codes a device reports
+type capabilities []uint64
+
+// parseCapabilities reads a bitmap as found in sysfs: hex words separated by
+// spaces, the most significant first.
+func parseCapabilities(s string) (capabilities, error) {
+	words := strings.Fields(s)
+	caps := make(capabilities, len(words))
+	for i, w := range words {
+		n, err := strconv.ParseUint(w, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid capabilities %q: %w", s, err)
+		}
+		caps[len(words)-1-i] = n
+	}
+	return caps, nil
+}
+
+func (c capabilities) has(code int) bool {
+	word := code / 64
+	return word < len(c) && c[word]&(1<<(code%64)) != 0
+}
+
+// deviceName returns the name of an evdev device, e.g. "AT Translated Set 2
+// keyboard", or the path when it can't be read.
+func deviceName(sysDir string, device string) string {
+	f, err := os.Open(filepath.Join(sysDir, filepath.Base(device), "device", "name"))
+	if err != nil {
+		return device
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if scanner.Scan() && scanner.Text() != "" {
+		return scanner.Text()
+	}
+	return device
+}
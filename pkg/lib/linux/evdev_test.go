@@ -0,0 +1,111 @@
+package linux
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEventRoundTrip(t *testing.T) {
+	events := []InputEvent{
+		{Time: time.Unix(1700000000, 250000000), Type: EV_KEY, Code: 29, Value: KeyPressed},
+		{Time: time.Unix(1700000000, 250000000), Type: EV_SYN},
+		{Time: time.Unix(1700000001, 0), Type: EV_KEY, Code: 29, Value: KeyReleased},
+		{Type: EV_MSC, Code: 4, Value: -1},
+	}
+
+	var buf bytes.Buffer
+	for _, ev := range events {
+		if err := WriteEvent(&buf, ev); err != nil {
+			t.Fatalf("WriteEvent returned error: %v", err)
+		}
+	}
+	if buf.Len() != len(events)*inputEventSize {
+		t.Fatalf("Expected %d bytes, got %d", len(events)*inputEventSize, buf.Len())
+	}
+
+	for _, want := range events {
+		got, err := ReadEvent(&buf)
+		if err != nil {
+			t.Fatalf("ReadEvent returned error: %v", err)
+		}
+		if !got.Time.Equal(want.Time) && !want.Time.IsZero() {
+			t.Errorf("Expected time %v, got %v", want.Time, got.Time)
+		}
+		if got.Type != want.Type || got.Code != want.Code || got.Value != want.Value {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	}
+	if _, err := ReadEvent(&buf); err != io.EOF {
+		t.Errorf("Expected io.EOF at the end of the stream, got %v", err)
+	}
+}
+
+func TestReadEventTruncated(t *testing.T) {
+	if _, err := ReadEvent(bytes.NewReader(make([]byte, 10))); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestParseCapabilities(t *testing.T) {
+	// A typical keyboard, KEY_ESC through KEY_MICMUTE
+	caps, err := parseCapabilities("1000000000007 ff9f207ac14057ff febeffdfffefffff fffffffffffffffe\n")
+	if err != nil {
+		t.Fatalf("parseCapabilities returned error: %v", err)
+	}
+	for _, code := range []int{KEY_ESC, KEY_A, KEY_Z, KEY_SPACE, KEY_ENTER, 125} {
+		if !caps.has(code) {
+			t.Errorf("Expected key %d", code)
+		}
+	}
+	if caps.has(0) || caps.has(1000) {
+		t.Error("Expected no key 0 or 1000")
+	}
+
+	if _, err := parseCapabilities("zz"); err == nil {
+		t.Error("Expected an error for invalid capabilities")
+	}
+}
+
+func TestFindKeyboards(t *testing.T) {
+	sysDir := t.TempDir()
+	devices := map[string]string{
+		"event0": "fffffffffffffffe", // keyboard
+		"event3": "0",                // power button
+		"event4": "1000000000007 ff9f207ac14057ff febeffdfffefffff fffffffffffffffe",
+		"event5": "30000 0 0 0 0", // mouse buttons
+	}
+	for name, caps := range devices {
+		dir := filepath.Join(sysDir, name, "device", "capabilities")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create device: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "key"), []byte(caps+"\n"), 0644); err != nil {
+			t.Fatalf("Failed to write capabilities: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(sysDir, "event4", "device", "name"), []byte("AT Translated Set 2 keyboard\n"), 0644); err != nil {
+		t.Fatalf("Failed to write name: %v", err)
+	}
+
+	keyboards, err := FindKeyboards(sysDir)
+	if err != nil {
+		t.Fatalf("FindKeyboards returned error: %v", err)
+	}
+	expected := []string{"/dev/input/event0", "/dev/input/event4"}
+	if !slices.Equal(keyboards, expected) {
+		t.Errorf("Expected %v, got %v", expected, keyboards)
+	}
+
+	if name := deviceName(sysDir, "/dev/input/event4"); name != "AT Translated Set 2 keyboard" {
+		t.Errorf("Expected the device name, got %q", name)
+	}
+	if name := deviceName(sysDir, "/dev/input/event0"); name != "/dev/input/event0" {
+		t.Errorf("Expected the path without a name, got %q", name)
+	}
+}
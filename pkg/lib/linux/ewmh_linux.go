@@ -0,0 +1,70 @@
+//go:build x11 && cgo
+
+package linux
+
+/*
+#include <x11_linux.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ewmh focuses windows through the EWMH hints of X11 window managers
+type ewmh struct {
+	display string
+}
+
+func newEWMH(display string) windowManager {
+	return ewmh{display: display}
+}
+
+func (e ewmh) windows() ([]window, error) {
+	x, err := openX11Display(e.display)
+	if err != nil {
+		return nil, err
+	}
+	defer x.close()
+
+	ids := make([]C.ulong, 1024)
+	n := C.clientWindows(x.dpy, &ids[0], C.int(len(ids)))
+	if n < 0 {
+		return nil, errors.New("the window manager doesn't support EWMH")
+	}
+	current := C.windowDesktop(x.dpy, 0)
+
+	var windows []window
+	instance := make([]C.char, 256)
+	class := make([]C.char, 256)
+	for _, id := range ids[:n] {
+		if C.windowClass(x.dpy, id, &instance[0], &class[0], C.int(len(class))) == 0 {
+			continue
+		}
+		desktop := C.windowDesktop(x.dpy, id)
+		windows = append(windows, window{
+			id:       strconv.FormatUint(uint64(id), 10),
+			class:    C.GoString(&class[0]),
+			instance: C.GoString(&instance[0]),
+			current:  desktop == current || desktop == 0xFFFFFFFF,
+		})
+	}
+	return windows, nil
+}
+
+func (e ewmh) focus(w window) error {
+	id, err := strconv.ParseUint(w.id, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	x, err := openX11Display(e.display)
+	if err != nil {
+		return err
+	}
+	defer x.close()
+
+	C.activateWindow(x.dpy, C.ulong(id))
+	return nil
+}
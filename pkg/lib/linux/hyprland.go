@@ -0,0 +1,97 @@
+package linux
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hyprland focuses windows through the request socket of Hyprland
+type hyprland struct {
+	socket string
+}
+
+// hyprlandSocket returns the request socket of the Hyprland instance with
+// signature sig, in the runtime directory since Hyprland 0.40 and in /tmp
+// before.
+func hyprlandSocket(runtimeDir string, sig string) string {
+	socket := filepath.Join(runtimeDir, "hypr", sig, ".socket.sock")
+	if _, err := os.Stat(socket); err != nil {
+		legacy := filepath.Join("/tmp", "hypr", sig, ".socket.sock")
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy
+		}
+	}
+	return socket
+}
+
+// request sends a command to Hyprland and returns its reply.
+func (h hyprland) request(command string) ([]byte, error) {
+	conn, err := net.Dial("unix", h.socket)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(command)); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(conn)
+}
+
+type hyprlandWorkspace struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func (h hyprland) windows() ([]window, error) {
+	reply, err := h.request("j/activeworkspace")
+	if err != nil {
+		return nil, err
+	}
+	var active hyprlandWorkspace
+	if err := json.Unmarshal(reply, &active); err != nil {
+		return nil, err
+	}
+
+	if reply, err = h.request("j/clients"); err != nil {
+		return nil, err
+	}
+	var clients []struct {
+		Address   string            `json:"address"`
+		Class     string            `json:"class"`
+		Mapped    bool              `json:"mapped"`
+		Workspace hyprlandWorkspace `json:"workspace"`
+	}
+	if err := json.Unmarshal(reply, &clients); err != nil {
+		return nil, err
+	}
+
+	var windows []window
+	for _, c := range clients {
+		if !c.Mapped {
+			continue
+		}
+		windows = append(windows, window{
+			id:      c.Address,
+			class:   c.Class,
+			current: c.Workspace.Id == active.Id,
+		})
+	}
+	return windows, nil
+}
+
+func (h hyprland) focus(w window) error {
+	reply, err := h.request("dispatch focuswindow address:" + w.id)
+	if err != nil {
+		return err
+	}
+	if r := strings.TrimSpace(string(reply)); r != "ok" {
+		return fmt.Errorf("Hyprland couldn't focus the window: %s", r)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package linux
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// fakeHyprland answers Hyprland requests on a socket, recording them
+func fakeHyprland(t *testing.T, socket string, replies map[string]string) *[]string {
+	t.Helper()
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	var requests []string
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 1024)
+			n, _ := conn.Read(buf)
+			requests = append(requests, string(buf[:n]))
+			conn.Write([]byte(replies[string(buf[:n])]))
+			conn.Close()
+		}
+	}()
+	return &requests
+}
+
+func TestHyprland(t *testing.T) {
+	runtimeDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(runtimeDir, "hypr", "sig"), 0755); err != nil {
+		t.Fatalf("Failed to create socket dir: %v", err)
+	}
+	socket := hyprlandSocket(runtimeDir, "sig")
+	if socket != filepath.Join(runtimeDir, "hypr", "sig", ".socket.sock") {
+		t.Fatalf("Unexpected socket %q", socket)
+	}
+
+	requests := fakeHyprland(t, socket, map[string]string{
+		"j/activeworkspace": `{"id":2,"name":"2"}`,
+		"j/clients": `[
+			{"address":"0x1","class":"firefox","mapped":true,"workspace":{"id":1,"name":"1"}},
+			{"address":"0x2","class":"kitty","mapped":true,"workspace":{"id":2,"name":"2"}},
+			{"address":"0x3","class":"hidden","mapped":false,"workspace":{"id":2,"name":"2"}}
+		]`,
+		"dispatch focuswindow address:0x1": "ok",
+	})
+	h := hyprland{socket: socket}
+
+	windows, err := h.windows()
+	if err != nil {
+		t.Fatalf("windows returned error: %v", err)
+	}
+	expected := []window{{id: "0x1", class: "firefox"}, {id: "0x2", class: "kitty", current: true}}
+	if !slices.Equal(windows, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, windows)
+	}
+
+	if err := h.focus(windows[0]); err != nil {
+		t.Fatalf("focus returned error: %v", err)
+	}
+	if err := h.focus(window{id: "0x9"}); err == nil {
+		t.Error("Expected an error for a window Hyprland can't focus")
+	}
+	if last := (*requests)[len(*requests)-1]; last != "dispatch focuswindow address:0x9" {
+		t.Errorf("Unexpected request %q", last)
+	}
+}
@@ -0,0 +1,132 @@
+package linux
+
+// Modifier names, shared with the other platforms so hotkeys stored in the
+// database mean the same everywhere. Super is "command" and Alt is "option".
+var ModifiersLinux = []string{"shift", "option", "control", "command"}
+
+// Event types and key values of linux/input-event-codes.h
+const (
+	EV_SYN = 0x00
+	EV_KEY = 0x01
+	EV_MSC = 0x04
+
+	KeyReleased = 0
+	KeyPressed  = 1
+	KeyRepeated = 2
+)
+
+// Evdev key codes used outside the key table
+const (
+	KEY_ESC   = 1
+	KEY_ENTER = 28
+	KEY_A     = 30
+	KEY_Z     = 44
+	KEY_SPACE = 57
+)
+
+// Evdev key codes to the canonical key names of RawToKeyDarwin, see
+// linux/input-event-codes.h
+var RawToKeyLinux = map[uint16]string{
+	1:   "esc",
+	2:   "1",
+	3:   "2",
+	4:   "3",
+	5:   "4",
+	6:   "5",
+	7:   "6",
+	8:   "7",
+	9:   "8",
+	10:  "9",
+	11:  "0",
+	12:  "dash",
+	13:  "equal sign",
+	14:  "backspace",
+	15:  "tab",
+	16:  "q",
+	17:  "w",
+	18:  "e",
+	19:  "r",
+	20:  "t",
+	21:  "y",
+	22:  "u",
+	23:  "i",
+	24:  "o",
+	25:  "p",
+	26:  "open bracket",
+	27:  "close bracket / å",
+	28:  "enter",
+	29:  "control", // left control
+	30:  "a",
+	31:  "s",
+	32:  "d",
+	33:  "f",
+	34:  "g",
+	35:  "h",
+	36:  "j",
+	37:  "k",
+	38:  "l",
+	39:  "semi-colon / ñ",
+	40:  "single quote / ø / ä",
+	41:  "`",
+	42:  "shift", // left shift
+	43:  "back slash",
+	44:  "z",
+	45:  "x",
+	46:  "c",
+	47:  "v",
+	48:  "b",
+	49:  "n",
+	50:  "m",
+	51:  "comma",
+	52:  "period",
+	53:  "forward slash / ç",
+	54:  "shift", // right shift
+	55:  "multiply",
+	56:  "option", // left alt
+	57:  "space",
+	58:  "capslock",
+	59:  "f1",
+	60:  "f2",
+	61:  "f3",
+	62:  "f4",
+	63:  "f5",
+	64:  "f6",
+	65:  "f7",
+	66:  "f8",
+	67:  "f9",
+	68:  "f10",
+	71:  "numpad 7",
+	72:  "numpad 8",
+	73:  "numpad 9",
+	74:  "subtract",
+	75:  "numpad 4",
+	76:  "numpad 5",
+	77:  "numpad 6",
+	78:  "add",
+	79:  "numpad 1",
+	80:  "numpad 2",
+	81:  "numpad 3",
+	82:  "numpad 0",
+	83:  "decimal point",
+	87:  "f11",
+	88:  "f12",
+	96:  "numpad enter",
+	97:  "control", // right control
+	98:  "divide",
+	100: "option", // right alt
+	102: "home",
+	103: "up arrow",
+	104: "page up",
+	105: "left arrow",
+	106: "right arrow",
+	107: "end",
+	108: "down arrow",
+	109: "page down",
+	110: "insert",
+	111: "delete",
+	125: "command", // left super
+	126: "command", // right super
+	183: "f13",
+	184: "f14",
+	185: "f15",
+}
@@ -0,0 +1,132 @@
+package linux
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// window is an open window of the X server or compositor
+type window struct {
+	id       string // how the window manager addresses it
+	class    string // WM_CLASS class or Wayland app_id
+	instance string // WM_CLASS instance, empty on Wayland
+	current  bool   // on the current desktop or workspace
+}
+
+// windowManager finds and focuses the windows of apps
+type windowManager interface {
+	windows() ([]window, error)
+	focus(w window) error
+}
+
+// detectWindowManager returns the window manager of the session, preferring
+// compositor IPC over EWMH, or nil when windows can't be focused.
+func detectWindowManager() windowManager {
+	if socket := os.Getenv("SWAYSOCK"); socket != "" {
+		return sway{socket: socket}
+	}
+	if sig := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE"); sig != "" {
+		return hyprland{socket: hyprlandSocket(os.Getenv("XDG_RUNTIME_DIR"), sig)}
+	}
+	if display := os.Getenv("DISPLAY"); display != "" {
+		return newEWMH(display)
+	}
+	return nil
+}
+
+// Replaced in tests
+var (
+	currentWindowManager = detectWindowManager
+	startProcess         = func(cmd *exec.Cmd) error { return cmd.Start() }
+)
+
+// windowClasses returns the names the windows of entry are likely to have,
+// lowercased: StartupWMClass, the desktop file id and its last part, and the
+// executable name.
+func windowClasses(entry DesktopEntry) []string {
+	var classes []string
+	add := func(name string) {
+		if name != "" {
+			classes = append(classes, strings.ToLower(name))
+		}
+	}
+
+	add(entry.StartupWMClass)
+	id := entry.Id()
+	add(id)
+	if i := strings.LastIndex(id, "."); i >= 0 {
+		add(id[i+1:])
+	}
+	if args, err := splitExec(entry.Exec); err == nil {
+		add(filepath.Base(args[0]))
+	}
+	return classes
+}
+
+// pickWindow returns the window of entry to focus. In "desktop" mode only
+// windows on the current desktop count, so the app opens a new window
+// rather than switching desktops.
+func pickWindow(windows []window, entry DesktopEntry, mode string) (window, bool) {
+	classes := windowClasses(entry)
+	for _, class := range classes {
+		for _, w := range windows {
+			if mode == "desktop" && !w.current {
+				continue
+			}
+			if strings.ToLower(w.class) == class || strings.ToLower(w.instance) == class {
+				return w, true
+			}
+		}
+	}
+	return window{}, false
+}
+
+// Launch opens the app of the .desktop file at desktopFile in mode. In the
+// "default" mode it focuses a window the app already has, in "desktop" mode
+// one on the current desktop, and otherwise runs its Exec key.
+func Launch(desktopFile string, mode string) error {
+	entry, err := ReadDesktopEntry(desktopFile)
+	if err != nil {
+		return err
+	}
+
+	// Without a window list the app is started like when it has no window
+	if wm := currentWindowManager(); wm != nil {
+		if windows, err := wm.windows(); err == nil {
+			if w, ok := pickWindow(windows, entry, mode); ok {
+				return wm.focus(w)
+			}
+		}
+	}
+	return startEntry(entry)
+}
+
+// startEntry runs the Exec key of entry in its own session, so it outlives
+// Yay, in a terminal when the entry asks for one.
+func startEntry(entry DesktopEntry) error {
+	args, err := entry.ExecArgs(nil)
+	if err != nil {
+		return err
+	}
+	if entry.Terminal {
+		terminal := os.Getenv("TERMINAL")
+		if terminal == "" {
+			terminal = "x-terminal-emulator"
+		}
+		args = append([]string{terminal, "-e"}, args...)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = entry.Path
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := startProcess(cmd); err != nil {
+		return err
+	}
+	if cmd.Process != nil {
+		go cmd.Wait()
+	}
+	return nil
+}
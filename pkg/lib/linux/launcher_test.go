@@ -0,0 +1,150 @@
+package linux
+
+import (
+	"errors"
+	"os/exec"
+	"slices"
+	"testing"
+)
+
+// fakeWindowManager has a fixed list of windows and records those focused
+type fakeWindowManager struct {
+	list    []window
+	err     error
+	focused []string
+}
+
+func (f *fakeWindowManager) windows() ([]window, error) {
+	return f.list, f.err
+}
+
+func (f *fakeWindowManager) focus(w window) error {
+	f.focused = append(f.focused, w.id)
+	return nil
+}
+
+// stubStart replaces the window manager and records the commands started
+func stubStart(t *testing.T, wm windowManager) func() [][]string {
+	var started [][]string
+	currentWindowManager = func() windowManager { return wm }
+	startProcess = func(cmd *exec.Cmd) error {
+		started = append(started, cmd.Args)
+		return nil
+	}
+	t.Cleanup(func() {
+		currentWindowManager = detectWindowManager
+		startProcess = func(cmd *exec.Cmd) error { return cmd.Start() }
+	})
+	return func() [][]string { return started }
+}
+
+func TestWindowClasses(t *testing.T) {
+	entry := DesktopEntry{
+		File:           "/usr/share/applications/org.gnome.Terminal.desktop",
+		Exec:           "/usr/bin/gnome-terminal --window",
+		StartupWMClass: "Gnome-terminal",
+	}
+	expected := []string{"gnome-terminal", "org.gnome.terminal", "terminal", "gnome-terminal"}
+	if got := windowClasses(entry); !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestPickWindow(t *testing.T) {
+	windows := []window{
+		{id: "1", class: "Gnome-terminal", instance: "gnome-terminal-server", current: false},
+		{id: "2", class: "firefox", current: false},
+		{id: "3", class: "org.mozilla.firefox", current: true},
+	}
+	firefox := DesktopEntry{File: "/usr/share/applications/org.mozilla.firefox.desktop", Exec: "firefox %u"}
+	terminal := DesktopEntry{File: "/usr/share/applications/org.gnome.Terminal.desktop", Exec: "gnome-terminal"}
+	editor := DesktopEntry{File: "/usr/share/applications/editor.desktop", Exec: "editor"}
+
+	tests := []struct {
+		entry DesktopEntry
+		mode  string
+		id    string
+		ok    bool
+	}{
+		{firefox, "default", "3", true}, // the desktop file id matches first
+		{firefox, "desktop", "3", true},
+		{terminal, "default", "1", true},
+		{terminal, "desktop", "", false}, // only on another desktop
+		{editor, "default", "", false},
+	}
+	for _, tc := range tests {
+		w, ok := pickWindow(windows, tc.entry, tc.mode)
+		if w.id != tc.id || ok != tc.ok {
+			t.Errorf("pickWindow(%s, %s) = %q, %v, want %q, %v", tc.entry.Id(), tc.mode, w.id, ok, tc.id, tc.ok)
+		}
+	}
+}
+
+func TestLaunch(t *testing.T) {
+	dir := t.TempDir()
+	firefox := writeDesktopFile(t, dir, "firefox.desktop", "[Desktop Entry]\nName=Firefox\nExec=firefox %u\n")
+	htop := writeDesktopFile(t, dir, "htop.desktop", "[Desktop Entry]\nName=htop\nExec=htop\nTerminal=true\n")
+
+	wm := &fakeWindowManager{list: []window{{id: "7", class: "firefox", current: false}}}
+	started := stubStart(t, wm)
+	t.Setenv("TERMINAL", "foot")
+
+	// An open window is focused
+	if err := Launch(firefox, "default"); err != nil {
+		t.Fatalf("Launch returned error: %v", err)
+	}
+	// In desktop mode a window on another desktop isn't
+	if err := Launch(firefox, "desktop"); err != nil {
+		t.Fatalf("Launch returned error: %v", err)
+	}
+	if err := Launch(htop, "default"); err != nil {
+		t.Fatalf("Launch returned error: %v", err)
+	}
+
+	if !slices.Equal(wm.focused, []string{"7"}) {
+		t.Errorf("Expected window 7 to be focused, got %v", wm.focused)
+	}
+	expected := [][]string{{"firefox"}, {"foot", "-e", "htop"}}
+	if got := started(); !slices.EqualFunc(got, expected, slices.Equal) {
+		t.Errorf("Expected %q to be started, got %q", expected, got)
+	}
+
+	// Apps still launch when windows can't be listed
+	wm.err = errors.New("no window manager")
+	if err := Launch(firefox, "default"); err != nil {
+		t.Fatalf("Launch returned error: %v", err)
+	}
+	if len(started()) != 3 {
+		t.Errorf("Expected firefox to be started, got %q", started())
+	}
+
+	if err := Launch(dir+"/missing.desktop", "default"); err == nil {
+		t.Error("Expected an error for a missing desktop file")
+	}
+}
+
+func TestDetectWindowManager(t *testing.T) {
+	t.Setenv("SWAYSOCK", "")
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "")
+	t.Setenv("DISPLAY", "")
+	if wm := detectWindowManager(); wm != nil {
+		t.Errorf("Expected no window manager, got %T", wm)
+	}
+
+	t.Setenv("DISPLAY", ":0")
+	if wm := detectWindowManager(); (wm != nil) != x11Supported {
+		t.Errorf("Expected EWMH under X11 only in x11 builds, got %T", wm)
+	}
+
+	dir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "abc")
+	if wm, ok := detectWindowManager().(hyprland); !ok || wm.socket != dir+"/hypr/abc/.socket.sock" {
+		t.Errorf("Expected Hyprland, got %#v", detectWindowManager())
+	}
+
+	t.Setenv("SWAYSOCK", "/run/user/1000/sway-ipc.sock")
+	if _, ok := detectWindowManager().(sway); !ok {
+		t.Error("Expected sway to take precedence")
+	}
+}
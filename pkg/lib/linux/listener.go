@@ -0,0 +1,342 @@
+package linux
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Builtbyjb/yay/pkg/lib/chord"
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
+// KeyEvent is a key press, repeat or release read from a keyboard
+type KeyEvent struct {
+	Code  uint16
+	Value int32 // KeyReleased, KeyPressed or KeyRepeated
+	Time  time.Time
+}
+
+// Called to open the app of a setting, replaced in tests. The path of
+// settings on Linux is their .desktop file.
+var launch = func(s core.Setting) error { return Launch(s.Path, s.Mode) }
+
+// newHotkeys returns the hotkey handling shared with the other platforms.
+// Linux can't tell the frontmost app, so hotkeys with app scoped bindings fail
+// with core.ErrFrontmostUnknown and exclusions don't apply.
+func newHotkeys(db *core.Database, log func(msg string, err error)) *core.Hotkeys {
+	return &core.Hotkeys{
+		DB:     db,
+		Launch: func(s core.Setting) error { return launch(s) },
+		Log:    log,
+	}
+}
+
+// ReservedHotkey reports whether hotkey is handled by the listener itself
+// rather than looked up in the settings, and what it does.
+func ReservedHotkey(hotkey string) (string, bool) {
+	action, ok := (&core.Hotkeys{}).Reserved(hotkey)
+	return action.Desc, ok
+}
+
+// LaunchSetting opens the app of setting in its mode and records the launch.
+func LaunchSetting(db *core.Database, setting core.Setting) error {
+	return newHotkeys(db, nil).LaunchSetting(setting)
+}
+
+// TriggerHotkey does what pressing hotkey does while the listener runs, and
+// describes what was done. Like a key press it does nothing but return a
+// *core.SuspendedError while hotkeys are paused.
+func TriggerHotkey(db *core.Database, hotkey string) (string, error) {
+	h := newHotkeys(db, nil)
+	action, ok, err := h.Resolve(hotkey, nil)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", core.ErrUnboundHotkey
+	}
+
+	if hotkey != core.PauseToggleHotkey {
+		now := time.Now()
+		if err := h.RefreshPause(now); err != nil {
+			return "", err
+		}
+		reason, err := h.SuspendReason(now, nil)
+		if err != nil {
+			return "", err
+		}
+		if reason != "" {
+			return action.Desc, &core.SuspendedError{Reason: reason}
+		}
+	}
+	return action.Desc, action.Run()
+}
+
+// chordMatcher feeds the evdev key events of the keyboards to a chord.Matcher
+type chordMatcher struct {
+	chord.Matcher
+}
+
+func newChordMatcher() *chordMatcher {
+	return &chordMatcher{}
+}
+
+// feed updates the held modifiers with event and returns the hotkey it
+// completes, if any. A key repeat doesn't complete a hotkey again, evdev
+// can't swallow it anyway.
+func (c *chordMatcher) feed(event KeyEvent) (string, bool) {
+	k, ok := RawToKeyLinux[event.Code]
+	if !ok {
+		return "", false
+	}
+
+	if slices.Contains(ModifiersLinux, k) {
+		c.Modifier(event.Code, k, event.Value != KeyReleased)
+		return "", false
+	}
+	if event.Value != KeyPressed {
+		return "", false
+	}
+	return c.Key(k)
+}
+
+// dispatcher runs the actions of the hotkeys a chordMatcher completes
+type dispatcher struct {
+	hotkeys *core.Hotkeys
+	log     func(msg string, err error)
+	mu      sync.Mutex
+	matcher *chordMatcher
+	onEvent func(KeyEvent)
+	done    chan struct{}  // closed by close
+	wg      sync.WaitGroup // running actions
+}
+
+// newDispatcher returns a dispatcher reporting through log, which may be nil.
+// Call close once done with it.
+func newDispatcher(db *core.Database, onEvent func(KeyEvent), log func(msg string, err error)) *dispatcher {
+	if log == nil {
+		log = func(string, error) {}
+	}
+	d := &dispatcher{
+		hotkeys: newHotkeys(db, log),
+		log:     log,
+		matcher: newChordMatcher(),
+		onEvent: onEvent,
+		done:    make(chan struct{}),
+	}
+
+	if excluded, err := db.GetExclusions(); err == nil && len(excluded) > 0 {
+		log("Excluded apps are ignored", core.ErrFrontmostUnknown)
+	}
+	d.hotkeys.WatchPause(d.done)
+	return d
+}
+
+// close waits for the running actions and stops watching for pauses.
+func (d *dispatcher) close() {
+	d.wg.Wait()
+	close(d.done)
+}
+
+func (d *dispatcher) handle(event KeyEvent) {
+	// Forward to the TUI if a callback is provided
+	if d.onEvent != nil {
+		d.onEvent(event)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	hotkey, ok := d.matcher.feed(event)
+	if !ok {
+		return
+	}
+	d.dispatchLocked(hotkey)
+}
+
+// dispatch runs the action bound to hotkey, for backends that match chords
+// themselves.
+func (d *dispatcher) dispatch(hotkey string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dispatchLocked(hotkey)
+}
+
+func (d *dispatcher) dispatchLocked(hotkey string) {
+	action, ok, err := d.hotkeys.Resolve(hotkey, nil)
+	if err != nil {
+		d.log("Couldn't look up "+hotkey, err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	// The toggle works while paused
+	if hotkey != core.PauseToggleHotkey {
+		reason, err := d.hotkeys.SuspendReason(time.Now(), nil)
+		if err != nil {
+			d.log("Couldn't check whether hotkeys are suspended", err)
+			return
+		}
+		if d.hotkeys.PassThrough(reason) {
+			return
+		}
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		if err := action.Run(); err != nil {
+			d.log("Couldn't "+action.Desc, err)
+		}
+	}()
+}
+
+// read feeds the key events of an evdev stream to the dispatcher until it
+// ends.
+func (d *dispatcher) read(r io.Reader) error {
+	for {
+		ev, err := ReadEvent(r)
+		if err != nil {
+			return err
+		}
+		if ev.Type != EV_KEY {
+			continue
+		}
+		d.handle(KeyEvent{Code: ev.Code, Value: ev.Value, Time: ev.Time})
+	}
+}
+
+// Listen handles the hotkeys typed on a single evdev stream, a keyboard or a
+// recording, and returns when it ends once the actions it started are done.
+// Key events still reach the other apps, evdev can't swallow them. What the
+// listener reports goes to log, with err set for problems; log may be nil.
+func Listen(db *core.Database, r io.Reader, onEvent func(KeyEvent), log func(msg string, err error)) error {
+	d := newDispatcher(db, onEvent, log)
+	err := d.read(r)
+	d.close()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+var (
+	listenersMu sync.Mutex
+	// Keyboards being read, so StopListener knows what to close
+	devices []*os.File
+	// Closed by StopListener to end the running listeners
+	stops []chan struct{}
+	// Set by StopListener when no listener runs, so a listener starting
+	// meanwhile stops too
+	stopPending bool
+)
+
+// listening registers a starting listener, it returns once stop is closed by
+// StopListener. Call release when the listener returns.
+func listening() (stop <-chan struct{}, release func()) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+
+	ch := make(chan struct{})
+	if stopPending {
+		stopPending = false
+		close(ch)
+		return ch, func() {}
+	}
+	stops = append(stops, ch)
+	return ch, func() {
+		listenersMu.Lock()
+		defer listenersMu.Unlock()
+		stops = slices.DeleteFunc(stops, func(c chan struct{}) bool { return c == ch })
+	}
+}
+
+// Listener reads every keyboard in /dev/input, the modifiers held on one
+// keyboard work with keys pressed on another. An optional onEvent callback is
+// called for every key event (e.g. to forward to a tea.Program), and what the
+// listener reports goes to log, which may be nil.
+// This function blocks until StopListener is called or returns right away
+// when no keyboard can be opened.
+func Listener(db *core.Database, onEvent func(KeyEvent), log func(msg string, err error)) error {
+	stop, release := listening()
+	defer release()
+
+	paths, err := FindKeyboards(sysInputDir)
+	if err != nil {
+		return err
+	}
+
+	var files []*os.File
+	var openErr error
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			openErr = err
+			continue
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		if openErr != nil {
+			return fmt.Errorf("%w: %v", ErrNoKeyboards, openErr)
+		}
+		return ErrNoKeyboards
+	}
+
+	listenersMu.Lock()
+	select {
+	case <-stop:
+		// Stopped while opening the keyboards
+		listenersMu.Unlock()
+		for _, f := range files {
+			f.Close()
+		}
+		return nil
+	default:
+	}
+	devices = files
+	listenersMu.Unlock()
+
+	d := newDispatcher(db, onEvent, log)
+	d.log("Listening for global keyboard events", nil)
+	var wg sync.WaitGroup
+	for _, f := range files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.read(f); err != nil && !errors.Is(err, os.ErrClosed) {
+				d.log("Stopped reading "+deviceName(sysInputDir, f.Name()), err)
+			}
+		}()
+	}
+	wg.Wait()
+	d.close()
+	return nil
+}
+
+// StopListener closes the keyboards so Listener returns, and ends
+// X11Listener within a second and PortalListener right away. When called
+// before a listener started, that listener returns as soon as it starts.
+func StopListener() {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+
+	if len(stops) == 0 {
+		stopPending = true
+		return
+	}
+	for _, ch := range stops {
+		close(ch)
+	}
+	stops = nil
+	for _, f := range devices {
+		f.Close()
+	}
+	devices = nil
+}
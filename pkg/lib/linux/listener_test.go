@@ -0,0 +1,315 @@
+package linux
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
+func setupTestDatabase(t *testing.T) *core.Database {
+	t.Helper()
+	db, err := core.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to init database: %v", err)
+	}
+	return db
+}
+
+// recording is an evdev stream of key events, each followed by a sync like
+// a keyboard reports them
+func recording(t *testing.T, events ...KeyEvent) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, ev := range events {
+		if err := WriteEvent(&buf, InputEvent{Time: ev.Time, Type: EV_MSC, Code: 4, Value: int32(ev.Code)}); err != nil {
+			t.Fatalf("Failed to record event: %v", err)
+		}
+		if err := WriteEvent(&buf, InputEvent{Time: ev.Time, Type: EV_KEY, Code: ev.Code, Value: ev.Value}); err != nil {
+			t.Fatalf("Failed to record event: %v", err)
+		}
+		if err := WriteEvent(&buf, InputEvent{Time: ev.Time, Type: EV_SYN}); err != nil {
+			t.Fatalf("Failed to record event: %v", err)
+		}
+	}
+	return &buf
+}
+
+func press(code uint16) KeyEvent   { return KeyEvent{Code: code, Value: KeyPressed} }
+func repeat(code uint16) KeyEvent  { return KeyEvent{Code: code, Value: KeyRepeated} }
+func release(code uint16) KeyEvent { return KeyEvent{Code: code, Value: KeyReleased} }
+
+// Evdev codes used in the tests
+const (
+	leftCtrl   = 29
+	leftShift  = 42
+	rightAlt   = 100
+	leftSuper  = 125
+	rightSuper = 126
+	key1       = 2
+	key2       = 3
+)
+
+// stubLaunch records the apps the listener opens instead of starting them
+func stubLaunch(t *testing.T) func() []string {
+	var mu sync.Mutex
+	var launched []string
+	original := launch
+	t.Cleanup(func() { launch = original })
+	launch = func(s core.Setting) error {
+		mu.Lock()
+		defer mu.Unlock()
+		launched = append(launched, s.Name)
+		return nil
+	}
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(launched)
+	}
+}
+
+func TestKeyTable(t *testing.T) {
+	seen := map[string]bool{}
+	for code, key := range RawToKeyLinux {
+		if key == "" || strings.Contains(key, "+") {
+			t.Errorf("Invalid key name %q for code %d", key, code)
+		}
+		seen[key] = true
+	}
+	for _, mod := range ModifiersLinux {
+		if !seen[mod] {
+			t.Errorf("Expected a key for modifier %q", mod)
+		}
+	}
+	for _, key := range []string{"a", "z", "0", "esc", "space", "enter", "f12", "left arrow"} {
+		if !seen[key] {
+			t.Errorf("Expected a key named %q", key)
+		}
+	}
+}
+
+func TestChordMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		events   []KeyEvent
+		expected []string
+	}{
+		{"single modifier", []KeyEvent{press(leftSuper), press(key1), release(key1), release(leftSuper)}, []string{"command+1"}},
+		{"no modifier", []KeyEvent{press(key1), release(key1)}, nil},
+		{"canonical order", []KeyEvent{press(leftShift), press(rightAlt), press(leftCtrl), press(KEY_A)}, []string{"control+option+shift+a"}},
+		{"repeat", []KeyEvent{press(leftCtrl), press(key1), repeat(key1), repeat(key1), release(key1)}, []string{"control+1"}},
+		{"released modifier", []KeyEvent{press(leftCtrl), release(leftCtrl), press(key1)}, nil},
+		{"both sides", []KeyEvent{press(leftSuper), press(rightSuper), release(leftSuper), press(key2)}, []string{"command+2"}},
+		{"held across keys", []KeyEvent{press(leftCtrl), press(key1), release(key1), press(key2)}, []string{"control+1", "control+2"}},
+		{"unknown key", []KeyEvent{press(leftCtrl), press(240)}, nil},
+	}
+	for _, tc := range tests {
+		c := newChordMatcher()
+		var got []string
+		for _, ev := range tc.events {
+			if hotkey, ok := c.feed(ev); ok {
+				got = append(got, hotkey)
+			}
+		}
+		if !slices.Equal(got, tc.expected) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestListenRecording(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+	launched := stubLaunch(t)
+
+	if err := db.Insert("Firefox", "/usr/share/applications/firefox.desktop", "firefox", sql.NullString{String: "command+1", Valid: true}, "default", true); err != nil {
+		t.Fatalf("Failed to insert setting: %v", err)
+	}
+	if err := db.Insert("Notes", "/usr/share/applications/notes.desktop", "notes", sql.NullString{String: "command+2", Valid: true}, "default", false); err != nil {
+		t.Fatalf("Failed to insert setting: %v", err)
+	}
+
+	var forwarded int
+	stream := recording(t,
+		press(leftSuper), press(key1), repeat(key1), release(key1),
+		press(key2), release(key2), // disabled
+		release(leftSuper),
+		press(key1), release(key1), // no modifier
+	)
+	if err := Listen(db, stream, func(KeyEvent) { forwarded++ }, nil); err != nil {
+		t.Fatalf("Listen returned error: %v", err)
+	}
+
+	if got := launched(); !slices.Equal(got, []string{"Firefox"}) {
+		t.Errorf("Expected Firefox to be opened once, got %v", got)
+	}
+	if forwarded != 9 {
+		t.Errorf("Expected 9 key events to be forwarded, got %d", forwarded)
+	}
+}
+
+func TestListenWhilePaused(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+	launched := stubLaunch(t)
+
+	if err := db.Insert("Firefox", "/usr/share/applications/firefox.desktop", "firefox", sql.NullString{String: "command+1", Valid: true}, "default", true); err != nil {
+		t.Fatalf("Failed to insert setting: %v", err)
+	}
+	if err := db.Pause(time.Time{}); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+
+	if err := Listen(db, recording(t, press(leftSuper), press(key1), release(key1)), nil, nil); err != nil {
+		t.Fatalf("Listen returned error: %v", err)
+	}
+	if got := launched(); len(got) != 0 {
+		t.Errorf("Expected nothing to be opened while paused, got %v", got)
+	}
+
+	// The toggle works while paused
+	stream := recording(t, press(leftSuper), press(leftShift), press(KEY_ESC), release(KEY_ESC), release(leftShift))
+	if err := Listen(db, stream, nil, nil); err != nil {
+		t.Fatalf("Listen returned error: %v", err)
+	}
+	if _, paused, _ := db.PausedUntil(time.Now()); paused {
+		t.Error("Expected the toggle to resume hotkeys")
+	}
+
+	if err := Listen(db, recording(t, press(leftSuper), press(key1), release(key1)), nil, nil); err != nil {
+		t.Fatalf("Listen returned error: %v", err)
+	}
+	if got := launched(); !slices.Equal(got, []string{"Firefox"}) {
+		t.Errorf("Expected Firefox to be opened once resumed, got %v", got)
+	}
+}
+
+func TestListenTruncated(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	stream := recording(t, press(leftSuper))
+	stream.Write([]byte{1, 2, 3})
+	if err := Listen(db, stream, nil, nil); err == nil {
+		t.Error("Expected an error for a truncated stream")
+	}
+}
+
+func TestTriggerHotkeyUnbound(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	if _, err := TriggerHotkey(db, "command+9"); err != core.ErrUnboundHotkey {
+		t.Errorf("Expected ErrUnboundHotkey, got %v", err)
+	}
+}
+
+func TestTriggerHotkeyWhilePaused(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+	launched := stubLaunch(t)
+
+	if err := db.Insert("Firefox", "/usr/share/applications/firefox.desktop", "firefox", sql.NullString{String: "command+1", Valid: true}, "default", true); err != nil {
+		t.Fatalf("Failed to insert setting: %v", err)
+	}
+	if err := db.Pause(time.Time{}); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+
+	var suspended *core.SuspendedError
+	if _, err := TriggerHotkey(db, "command+1"); !errors.As(err, &suspended) || suspended.Reason != "paused" {
+		t.Errorf("Expected the hotkey to pass through while paused, got %v", err)
+	}
+	if got := launched(); len(got) != 0 {
+		t.Errorf("Expected nothing to be opened while paused, got %v", got)
+	}
+
+	// The toggle still works
+	if _, err := TriggerHotkey(db, core.PauseToggleHotkey); err != nil {
+		t.Fatalf("Expected the toggle to work, got %v", err)
+	}
+	if _, err := TriggerHotkey(db, "command+1"); err != nil {
+		t.Errorf("Expected Firefox to be opened once resumed, got %v", err)
+	}
+}
+
+func TestListenScopedBinding(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+	launched := stubLaunch(t)
+
+	if err := db.Insert("Notes", "/usr/share/applications/notes.desktop", "notes", sql.NullString{String: "command+1", Valid: true}, "default", true); err != nil {
+		t.Fatalf("Failed to insert setting: %v", err)
+	}
+	settings, err := db.GetAllSettings()
+	if err != nil {
+		t.Fatalf("Failed to get settings: %v", err)
+	}
+	if err := db.SetScope(settings[0].Id, core.Scope{Only: []string{"org.gnome.gedit"}}); err != nil {
+		t.Fatalf("Failed to set scope: %v", err)
+	}
+	if err := db.Exclude(settings[0].Id); err != nil {
+		t.Fatalf("Failed to exclude: %v", err)
+	}
+
+	var mu sync.Mutex
+	var logged []error
+	log := func(msg string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			logged = append(logged, err)
+		}
+	}
+	if err := Listen(db, recording(t, press(leftSuper), press(key1), release(key1)), nil, log); err != nil {
+		t.Fatalf("Listen returned error: %v", err)
+	}
+
+	// Linux can't tell the frontmost app, the exclusion and the scope are
+	// reported instead of ignored
+	if got := launched(); len(got) != 0 {
+		t.Errorf("Expected the scoped binding not to open anything, got %v", got)
+	}
+	if len(logged) != 2 || !errors.Is(logged[0], core.ErrFrontmostUnknown) || !errors.Is(logged[1], core.ErrFrontmostUnknown) {
+		t.Errorf("Expected the exclusion and the scope to be reported, got %v", logged)
+	}
+	if _, err := TriggerHotkey(db, "command+1"); !errors.Is(err, core.ErrFrontmostUnknown) {
+		t.Errorf("Expected ErrFrontmostUnknown, got %v", err)
+	}
+}
+
+func TestStopListenerBeforeStart(t *testing.T) {
+	// A stop requested while nothing listens ends the next listener
+	StopListener()
+	stop, release := listening()
+	select {
+	case <-stop:
+	default:
+		t.Error("Expected the pending stop to end the listener")
+	}
+	release()
+
+	stop, release = listening()
+	defer release()
+	select {
+	case <-stop:
+		t.Fatal("Expected the pending stop to be used up")
+	default:
+	}
+	StopListener()
+	select {
+	case <-stop:
+	default:
+		t.Error("Expected StopListener to end the running listener")
+	}
+}
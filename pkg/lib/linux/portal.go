@@ -0,0 +1,367 @@
+package linux
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+	"github.com/godbus/dbus/v5"
+)
+
+// The XDG desktop portal, see
+// https://flatpak.github.io/xdg-desktop-portal/docs/doc-org.freedesktop.portal.GlobalShortcuts.html
+const (
+	portalBusName            = "org.freedesktop.portal.Desktop"
+	portalPath               = dbus.ObjectPath("/org/freedesktop/portal/desktop")
+	globalShortcutsInterface = "org.freedesktop.portal.GlobalShortcuts"
+	requestInterface         = "org.freedesktop.portal.Request"
+	sessionInterface         = "org.freedesktop.portal.Session"
+)
+
+// ErrNoPortal is returned when the desktop doesn't provide the
+// GlobalShortcuts portal.
+var ErrNoPortal = errors.New("the desktop has no GlobalShortcuts portal")
+
+// Modifier names to those of the XDG shortcuts spec
+var portalModifiers = map[string]string{
+	"command": "LOGO",
+	"control": "CTRL",
+	"option":  "ALT",
+	"shift":   "SHIFT",
+}
+
+// Canonical key names to xkb keysym names, as used in portal triggers.
+// Letters and digits are their own name.
+var portalKeys = map[string]string{
+	"dash":                 "minus",
+	"equal sign":           "equal",
+	"open bracket":         "bracketleft",
+	"close bracket / å":    "bracketright",
+	"semi-colon / ñ":       "semicolon",
+	"single quote / ø / ä": "apostrophe",
+	"`":                    "grave",
+	"back slash":           "backslash",
+	"comma":                "comma",
+	"period":               "period",
+	"forward slash / ç":    "slash",
+	"enter":                "Return",
+	"tab":                  "Tab",
+	"backspace":            "BackSpace",
+	"esc":                  "Escape",
+	"space":                "space",
+	"delete":               "Delete",
+	"insert":               "Insert",
+	"home":                 "Home",
+	"end":                  "End",
+	"page up":              "Page_Up",
+	"page down":            "Page_Down",
+	"left arrow":           "Left",
+	"right arrow":          "Right",
+	"up arrow":             "Up",
+	"down arrow":           "Down",
+	"capslock":             "Caps_Lock",
+	"clear":                "Clear",
+	"numpad enter":         "KP_Enter",
+	"decimal point":        "KP_Decimal",
+	"multiply":             "KP_Multiply",
+	"add":                  "KP_Add",
+	"subtract":             "KP_Subtract",
+	"divide":               "KP_Divide",
+}
+
+// portalTrigger returns the preferred trigger of hotkey in the format of
+// the XDG shortcuts spec, e.g. "LOGO+SHIFT+Escape" for "command+shift+esc".
+func portalTrigger(hotkey string) (string, bool) {
+	parts := strings.Split(hotkey, "+")
+	if len(parts) < 2 {
+		return "", false
+	}
+
+	var trigger []string
+	for _, mod := range parts[:len(parts)-1] {
+		name, ok := portalModifiers[mod]
+		if !ok {
+			return "", false
+		}
+		trigger = append(trigger, name)
+	}
+
+	key := parts[len(parts)-1]
+	if name, ok := portalKeys[key]; ok {
+		key = name
+	} else if f, ok := strings.CutPrefix(key, "f"); ok && f != "" && strings.Trim(f, "0123456789") == "" {
+		key = "F" + f
+	} else if k, ok := strings.CutPrefix(key, "numpad "); ok {
+		key = "KP_" + k
+	} else if len(key) != 1 || !strings.Contains("abcdefghijklmnopqrstuvwxyz0123456789", key) {
+		return "", false
+	}
+	return strings.Join(append(trigger, key), "+"), true
+}
+
+// portalShortcut is a shortcut as BindShortcuts takes it, (sa{sv}). The id is
+// the canonical hotkey so activations go through the same lookup as key
+// presses, whatever trigger the user picked.
+type portalShortcut struct {
+	Id      string
+	Options map[string]dbus.Variant
+}
+
+// portalShortcuts returns the shortcuts to bind: the pause toggle and the
+// hotkeys of the enabled apps, sorted by hotkey.
+func portalShortcuts(db *core.Database) ([]portalShortcut, error) {
+	settings, err := db.GetAllSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	descriptions := map[string]string{core.PauseToggleHotkey: "Pause or resume hotkeys"}
+	for _, s := range settings {
+		if !s.Enabled || !s.HotKey.Valid || s.HotKey.String == "" {
+			continue
+		}
+		if _, ok := descriptions[s.HotKey.String]; !ok {
+			descriptions[s.HotKey.String] = "Open " + s.Title()
+		}
+	}
+
+	var shortcuts []portalShortcut
+	for hotkey, desc := range descriptions {
+		trigger, ok := portalTrigger(hotkey)
+		if !ok {
+			continue
+		}
+		shortcuts = append(shortcuts, portalShortcut{
+			Id: hotkey,
+			Options: map[string]dbus.Variant{
+				"description":       dbus.MakeVariant(desc),
+				"preferred_trigger": dbus.MakeVariant(trigger),
+			},
+		})
+	}
+	slices.SortFunc(shortcuts, func(a, b portalShortcut) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+	return shortcuts, nil
+}
+
+// sameShortcuts reports whether binding a and b would be the same.
+func sameShortcuts(a, b []portalShortcut) bool {
+	return slices.EqualFunc(a, b, func(x, y portalShortcut) bool {
+		return x.Id == y.Id && x.Options["description"] == y.Options["description"]
+	})
+}
+
+// Makes the request and session tokens unique
+var portalTokens atomic.Int64
+
+// portal is a GlobalShortcuts session
+type portal struct {
+	conn    *dbus.Conn
+	session dbus.ObjectPath
+}
+
+// requestPath returns the object path the portal uses for the request with
+// token, see org.freedesktop.portal.Request.
+func requestPath(uniqueName string, token string) dbus.ObjectPath {
+	sender := strings.ReplaceAll(strings.TrimPrefix(uniqueName, ":"), ".", "_")
+	return dbus.ObjectPath(fmt.Sprintf("%s/request/%s/%s", portalPath, sender, token))
+}
+
+// request calls a portal method whose last argument is its options and waits
+// for the Response signal of the request it starts.
+func (p *portal) request(method string, args []any, options map[string]dbus.Variant) (map[string]dbus.Variant, error) {
+	token := fmt.Sprintf("yay%d", portalTokens.Add(1))
+	options["handle_token"] = dbus.MakeVariant(token)
+	expected := requestPath(p.conn.Names()[0], token)
+
+	// Subscribe first, the response can come before the call returns
+	match := []dbus.MatchOption{dbus.WithMatchInterface(requestInterface), dbus.WithMatchMember("Response")}
+	if err := p.conn.AddMatchSignal(match...); err != nil {
+		return nil, err
+	}
+	defer p.conn.RemoveMatchSignal(match...)
+	signals := make(chan *dbus.Signal, 4)
+	p.conn.Signal(signals)
+	defer p.conn.RemoveSignal(signals)
+
+	var handle dbus.ObjectPath
+	obj := p.conn.Object(portalBusName, portalPath)
+	if err := obj.Call(globalShortcutsInterface+"."+method, 0, append(args, options)...).Store(&handle); err != nil {
+		return nil, err
+	}
+
+	timeout := time.After(time.Minute) // the user may be asked to confirm
+	for {
+		select {
+		case sig, ok := <-signals:
+			if !ok {
+				return nil, errors.New("the D-Bus connection was closed")
+			}
+			if sig.Name != requestInterface+".Response" || (sig.Path != expected && sig.Path != handle) {
+				continue
+			}
+			var code uint32
+			var results map[string]dbus.Variant
+			if err := dbus.Store(sig.Body, &code, &results); err != nil {
+				return nil, err
+			}
+			switch code {
+			case 0:
+				return results, nil
+			case 1:
+				return nil, fmt.Errorf("%s was cancelled", method)
+			default:
+				return nil, fmt.Errorf("%s failed", method)
+			}
+		case <-timeout:
+			return nil, fmt.Errorf("%s timed out", method)
+		}
+	}
+}
+
+// openPortal creates a GlobalShortcuts session on conn.
+func openPortal(conn *dbus.Conn) (*portal, error) {
+	obj := conn.Object(portalBusName, portalPath)
+	if _, err := obj.GetProperty(globalShortcutsInterface + ".version"); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoPortal, err)
+	}
+
+	p := &portal{conn: conn}
+	results, err := p.request("CreateSession", nil, map[string]dbus.Variant{
+		"session_handle_token": dbus.MakeVariant(fmt.Sprintf("yay%d", portalTokens.Add(1))),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Older portals return the handle as a string
+	switch handle := results["session_handle"].Value().(type) {
+	case dbus.ObjectPath:
+		p.session = handle
+	case string:
+		p.session = dbus.ObjectPath(handle)
+	default:
+		return nil, errors.New("CreateSession returned no session")
+	}
+	return p, nil
+}
+
+// bind registers shortcuts with the session. The desktop may ask the user
+// to confirm or change their triggers.
+func (p *portal) bind(shortcuts []portalShortcut) error {
+	_, err := p.request("BindShortcuts", []any{p.session, shortcuts, ""}, map[string]dbus.Variant{})
+	return err
+}
+
+// close ends the session, releasing its shortcuts.
+func (p *portal) close() error {
+	return p.conn.Object(portalBusName, p.session).Call(sessionInterface+".Close", 0).Err
+}
+
+// PortalListener binds the hotkeys of the enabled apps through the XDG
+// GlobalShortcuts portal, for Wayland desktops where neither evdev nor X11
+// grabs are available. The desktop decides the actual triggers and may let
+// the user change them.
+// What the listener reports goes to log, which may be nil.
+// This function blocks until StopListener is called or returns right away
+// when the desktop has no GlobalShortcuts portal.
+func PortalListener(db *core.Database, log func(msg string, err error)) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return listenPortal(db, conn, log, func() {
+		if log != nil {
+			log("Listening for global shortcuts", nil)
+		}
+	})
+}
+
+func listenPortal(db *core.Database, conn *dbus.Conn, log func(msg string, err error), started func()) error {
+	stop, release := listening()
+	defer release()
+
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(portalPath),
+		dbus.WithMatchInterface(globalShortcutsInterface),
+		dbus.WithMatchMember("Activated"),
+	}
+	if err := conn.AddMatchSignal(match...); err != nil {
+		return err
+	}
+	defer conn.RemoveMatchSignal(match...)
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	shortcuts, err := portalShortcuts(db)
+	if err != nil {
+		return err
+	}
+	p, err := openPortal(conn)
+	if err != nil {
+		return err
+	}
+	defer func() { p.close() }()
+	if err := p.bind(shortcuts); err != nil {
+		return err
+	}
+
+	if started != nil {
+		started()
+	}
+
+	d := newDispatcher(db, nil, log)
+	defer d.close()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+
+		case sig, ok := <-signals:
+			if !ok {
+				return errors.New("the D-Bus connection was closed")
+			}
+			if sig.Name != globalShortcutsInterface+".Activated" || len(sig.Body) < 2 {
+				continue
+			}
+			session, _ := sig.Body[0].(dbus.ObjectPath)
+			id, _ := sig.Body[1].(string)
+			if session == p.session {
+				d.dispatch(id)
+			}
+
+		case <-ticker.C:
+			// Pick up the hotkeys edited since, e.g. in the TUI. Shortcuts
+			// are bound once per session, rebinding takes a new one.
+			latest, err := portalShortcuts(db)
+			if err != nil {
+				d.log("Couldn't fetch the hotkeys", err)
+				continue
+			}
+			if sameShortcuts(latest, shortcuts) {
+				continue
+			}
+			p.close()
+			next, err := openPortal(conn)
+			if err != nil {
+				return err
+			}
+			p = next
+			if err := p.bind(latest); err != nil {
+				return err
+			}
+			shortcuts = latest
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package linux
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+func TestPortalTrigger(t *testing.T) {
+	tests := []struct {
+		hotkey  string
+		trigger string
+		ok      bool
+	}{
+		{"command+1", "LOGO+1", true},
+		{"command+shift+esc", "LOGO+SHIFT+Escape", true},
+		{"control+option+a", "CTRL+ALT+a", true},
+		{"command+f", "LOGO+f", true},
+		{"command+f12", "LOGO+F12", true},
+		{"control+numpad 5", "CTRL+KP_5", true},
+		{"command+close bracket / å", "LOGO+bracketright", true},
+		{"command+page down", "LOGO+Page_Down", true},
+		{"a", "", false},
+		{"hyper+a", "", false},
+		{"command+nope", "", false},
+	}
+	for _, tc := range tests {
+		trigger, ok := portalTrigger(tc.hotkey)
+		if trigger != tc.trigger || ok != tc.ok {
+			t.Errorf("portalTrigger(%q) = %q, %v, want %q, %v", tc.hotkey, trigger, ok, tc.trigger, tc.ok)
+		}
+	}
+
+	// Every key X11 can grab has a trigger
+	for _, key := range KeysymToKeyX11 {
+		if _, ok := portalTrigger("command+" + key); !ok {
+			t.Errorf("No trigger for %q", key)
+		}
+	}
+}
+
+func TestPortalShortcuts(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	for _, s := range []struct {
+		name    string
+		hotkey  string
+		enabled bool
+	}{
+		{"Firefox", "command+1", true},
+		{"Notes", "command+2", false},
+		{"Terminal", "", true},
+	} {
+		hotkey := sql.NullString{String: s.hotkey, Valid: s.hotkey != ""}
+		if err := db.Insert(s.name, "/usr/share/applications/"+s.name+".desktop", "", hotkey, "default", s.enabled); err != nil {
+			t.Fatalf("Failed to insert setting: %v", err)
+		}
+	}
+
+	shortcuts, err := portalShortcuts(db)
+	if err != nil {
+		t.Fatalf("portalShortcuts returned error: %v", err)
+	}
+	got := describeShortcuts(shortcuts)
+	expected := []string{"command+1 Open Firefox LOGO+1", "command+shift+esc Pause or resume hotkeys LOGO+SHIFT+Escape"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("Expected shortcuts %v, got %v", expected, got)
+	}
+}
+
+func TestRequestPath(t *testing.T) {
+	if got := requestPath(":1.42", "yay1"); got != "/org/freedesktop/portal/desktop/request/1_42/yay1" {
+		t.Errorf("Unexpected request path %q", got)
+	}
+}
+
+// describeShortcuts returns "id description trigger" for each shortcut
+func describeShortcuts(shortcuts []portalShortcut) []string {
+	var desc []string
+	for _, s := range shortcuts {
+		desc = append(desc, fmt.Sprintf("%s %s %s", s.Id, s.Options["description"].Value(), s.Options["preferred_trigger"].Value()))
+	}
+	return desc
+}
+
+// startBus runs a private session bus for the test and returns its address,
+// skipping the test when dbus-daemon isn't installed.
+func startBus(t *testing.T) string {
+	t.Helper()
+	path, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+
+	dir := t.TempDir()
+	config := fmt.Sprintf(`<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-BUS Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>`, filepath.Join(dir, "bus"))
+	if err := os.WriteFile(filepath.Join(dir, "bus.conf"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write bus config: %v", err)
+	}
+
+	cmd := exec.Command(path, "--config-file="+filepath.Join(dir, "bus.conf"), "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("Failed to start dbus-daemon: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read the bus address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+func connectBus(t *testing.T, address string) *dbus.Conn {
+	t.Helper()
+	conn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("Failed to connect to the bus: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// mockPortal implements the GlobalShortcuts portal, answering every request
+// right away like a desktop that doesn't ask the user
+type mockPortal struct {
+	conn     *dbus.Conn
+	mu       sync.Mutex
+	sessions int
+	bound    map[dbus.ObjectPath][]portalShortcut
+	closed   []dbus.ObjectPath
+}
+
+func startMockPortal(t *testing.T, address string) *mockPortal {
+	t.Helper()
+	m := &mockPortal{conn: connectBus(t, address), bound: map[dbus.ObjectPath][]portalShortcut{}}
+
+	if err := m.conn.Export(m, portalPath, globalShortcutsInterface); err != nil {
+		t.Fatalf("Failed to export the portal: %v", err)
+	}
+	props := prop.Map{globalShortcutsInterface: {"version": {Value: uint32(1)}}}
+	if _, err := prop.Export(m.conn, portalPath, props); err != nil {
+		t.Fatalf("Failed to export the portal properties: %v", err)
+	}
+	if reply, err := m.conn.RequestName(portalBusName, dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("Failed to own %s: %v", portalBusName, err)
+	}
+	return m
+}
+
+func (m *mockPortal) respond(sender dbus.Sender, options map[string]dbus.Variant, results map[string]dbus.Variant) (dbus.ObjectPath, *dbus.Error) {
+	token, _ := options["handle_token"].Value().(string)
+	handle := requestPath(string(sender), token)
+	if err := m.conn.Emit(handle, requestInterface+".Response", uint32(0), results); err != nil {
+		return "", dbus.MakeFailedError(err)
+	}
+	return handle, nil
+}
+
+func (m *mockPortal) CreateSession(sender dbus.Sender, options map[string]dbus.Variant) (dbus.ObjectPath, *dbus.Error) {
+	m.mu.Lock()
+	m.sessions++
+	session := dbus.ObjectPath(fmt.Sprintf("%s/session/yay/%d", portalPath, m.sessions))
+	m.mu.Unlock()
+
+	if err := m.conn.Export(&mockSession{m, session}, session, sessionInterface); err != nil {
+		return "", dbus.MakeFailedError(err)
+	}
+	return m.respond(sender, options, map[string]dbus.Variant{"session_handle": dbus.MakeVariant(session)})
+}
+
+func (m *mockPortal) BindShortcuts(sender dbus.Sender, session dbus.ObjectPath, shortcuts []portalShortcut, parent string, options map[string]dbus.Variant) (dbus.ObjectPath, *dbus.Error) {
+	m.mu.Lock()
+	m.bound[session] = shortcuts
+	m.mu.Unlock()
+	return m.respond(sender, options, map[string]dbus.Variant{"shortcuts": dbus.MakeVariant(shortcuts)})
+}
+
+// activate emits Activated for shortcut id of session, like the desktop
+// when the user presses its trigger
+func (m *mockPortal) activate(session dbus.ObjectPath, id string) error {
+	return m.conn.Emit(portalPath, globalShortcutsInterface+".Activated", session, id, uint64(0), map[string]dbus.Variant{})
+}
+
+// state returns the sessions bound so far and those closed
+func (m *mockPortal) state() (map[dbus.ObjectPath][]string, []dbus.ObjectPath) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bound := map[dbus.ObjectPath][]string{}
+	for session, shortcuts := range m.bound {
+		bound[session] = describeShortcuts(shortcuts)
+	}
+	return bound, slices.Clone(m.closed)
+}
+
+type mockSession struct {
+	portal *mockPortal
+	path   dbus.ObjectPath
+}
+
+func (s *mockSession) Close() *dbus.Error {
+	s.portal.mu.Lock()
+	defer s.portal.mu.Unlock()
+	s.portal.closed = append(s.portal.closed, s.path)
+	return nil
+}
+
+// waitFor polls cond for up to 3 seconds
+func waitFor(cond func() bool) bool {
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+func TestPortalListener(t *testing.T) {
+	address := startBus(t)
+	mock := startMockPortal(t, address)
+
+	db := setupTestDatabase(t)
+	defer db.Close()
+	launched := stubLaunch(t)
+
+	if err := db.Insert("Firefox", "/usr/share/applications/firefox.desktop", "firefox", sql.NullString{String: "control+option+1", Valid: true}, "default", true); err != nil {
+		t.Fatalf("Failed to insert setting: %v", err)
+	}
+
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- listenPortal(db, connectBus(t, address), nil, func() { close(started) })
+	}()
+	select {
+	case <-started:
+	case err := <-done:
+		t.Fatalf("listenPortal returned early: %v", err)
+	}
+
+	first := dbus.ObjectPath(portalPath + "/session/yay/1")
+	bound, _ := mock.state()
+	expected := []string{"command+shift+esc Pause or resume hotkeys LOGO+SHIFT+Escape", "control+option+1 Open Firefox CTRL+ALT+1"}
+	if !slices.Equal(bound[first], expected) {
+		t.Fatalf("Expected %v to be bound, got %v", expected, bound)
+	}
+
+	// Activations of other sessions are someone else's
+	if err := mock.activate("/org/freedesktop/portal/desktop/session/other/1", "control+option+1"); err != nil {
+		t.Fatalf("Failed to activate: %v", err)
+	}
+	if err := mock.activate(first, "control+option+1"); err != nil {
+		t.Fatalf("Failed to activate: %v", err)
+	}
+	if !waitFor(func() bool { return len(launched()) > 0 }) {
+		t.Fatal("Expected Firefox to be opened")
+	}
+
+	// Editing a hotkey binds the shortcuts again in a new session
+	firefox, _ := db.FindApp("Firefox")
+	if err := db.UpdateHotkey(firefox.Id, sql.NullString{String: "control+option+2", Valid: true}); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+	second := dbus.ObjectPath(portalPath + "/session/yay/2")
+	if !waitFor(func() bool { bound, _ := mock.state(); return bound[second] != nil }) {
+		t.Fatal("Expected the shortcuts to be bound again")
+	}
+	bound, closed := mock.state()
+	if !slices.Contains(bound[second], "control+option+2 Open Firefox CTRL+ALT+2") {
+		t.Errorf("Expected the new hotkey to be bound, got %v", bound[second])
+	}
+	if !slices.Equal(closed, []dbus.ObjectPath{first}) {
+		t.Errorf("Expected the first session to be closed, got %v", closed)
+	}
+
+	StopListener()
+	if err := <-done; err != nil {
+		t.Errorf("listenPortal returned error: %v", err)
+	}
+	if _, closed := mock.state(); !slices.Contains(closed, second) {
+		t.Errorf("Expected the last session to be closed, got %v", closed)
+	}
+	if got := launched(); !slices.Equal(got, []string{"Firefox"}) {
+		t.Errorf("Expected Firefox to be opened once, got %v", got)
+	}
+}
+
+func TestPortalMissing(t *testing.T) {
+	address := startBus(t)
+
+	_, err := openPortal(connectBus(t, address))
+	if !errors.Is(err, ErrNoPortal) {
+		t.Errorf("Expected ErrNoPortal, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package linux
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+)
+
+// Message types of the sway IPC, see sway-ipc(7)
+const (
+	swayRunCommand    = 0
+	swayGetWorkspaces = 1
+	swayGetTree       = 4
+)
+
+const swayMagic = "i3-ipc"
+
+// sway focuses windows through the IPC socket of sway
+type sway struct {
+	socket string
+}
+
+// call sends a message to sway and decodes its JSON reply into reply.
+func (s sway) call(msgType uint32, payload string, reply any) error {
+	conn, err := net.Dial("unix", s.socket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	header := make([]byte, len(swayMagic)+8)
+	copy(header, swayMagic)
+	binary.LittleEndian.PutUint32(header[6:10], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(header[10:14], msgType)
+	if _, err := conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if string(header[:len(swayMagic)]) != swayMagic {
+		return errors.New("invalid sway IPC reply")
+	}
+	body := make([]byte, binary.LittleEndian.Uint32(header[6:10]))
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return err
+	}
+	return json.Unmarshal(body, reply)
+}
+
+// swayNode is a node of the sway layout tree
+type swayNode struct {
+	Id               int64  `json:"id"`
+	Type             string `json:"type"`
+	Name             string `json:"name"`
+	AppId            string `json:"app_id"`
+	WindowProperties struct {
+		Class    string `json:"class"`
+		Instance string `json:"instance"`
+	} `json:"window_properties"`
+	Nodes         []swayNode `json:"nodes"`
+	FloatingNodes []swayNode `json:"floating_nodes"`
+}
+
+func (s sway) windows() ([]window, error) {
+	var workspaces []struct {
+		Name    string `json:"name"`
+		Focused bool   `json:"focused"`
+	}
+	if err := s.call(swayGetWorkspaces, "", &workspaces); err != nil {
+		return nil, err
+	}
+	current := ""
+	for _, ws := range workspaces {
+		if ws.Focused {
+			current = ws.Name
+		}
+	}
+
+	var tree swayNode
+	if err := s.call(swayGetTree, "", &tree); err != nil {
+		return nil, err
+	}
+
+	var windows []window
+	var walk func(node swayNode, workspace string)
+	walk = func(node swayNode, workspace string) {
+		if node.Type == "workspace" {
+			workspace = node.Name
+		}
+		class := node.AppId
+		if class == "" {
+			class = node.WindowProperties.Class // Xwayland
+		}
+		if (node.Type == "con" || node.Type == "floating_con") && class != "" {
+			windows = append(windows, window{
+				id:       strconv.FormatInt(node.Id, 10),
+				class:    class,
+				instance: node.WindowProperties.Instance,
+				current:  workspace == current,
+			})
+		}
+		for _, child := range append(node.Nodes, node.FloatingNodes...) {
+			walk(child, workspace)
+		}
+	}
+	walk(tree, "")
+	return windows, nil
+}
+
+func (s sway) focus(w window) error {
+	var results []struct {
+		Success bool   `json:"success"`
+		Error   string `json:"error"`
+	}
+	if err := s.call(swayRunCommand, fmt.Sprintf("[con_id=%s] focus", w.id), &results); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if !r.Success {
+			return fmt.Errorf("sway couldn't focus the window: %s", r.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package linux
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// fakeSway answers sway IPC messages on a socket with replies by message
+// type, recording the commands run
+func fakeSway(t *testing.T, replies map[uint32]string) (string, *[]string) {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "sway.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	var commands []string
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			header := make([]byte, 14)
+			if _, err := io.ReadFull(conn, header); err != nil {
+				conn.Close()
+				continue
+			}
+			payload := make([]byte, binary.LittleEndian.Uint32(header[6:10]))
+			io.ReadFull(conn, payload)
+			msgType := binary.LittleEndian.Uint32(header[10:14])
+			if msgType == swayRunCommand {
+				commands = append(commands, string(payload))
+			}
+
+			reply := replies[msgType]
+			binary.LittleEndian.PutUint32(header[6:10], uint32(len(reply)))
+			conn.Write(append(header, reply...))
+			conn.Close()
+		}
+	}()
+	return socket, &commands
+}
+
+func TestSwayWindows(t *testing.T) {
+	socket, commands := fakeSway(t, map[uint32]string{
+		swayGetWorkspaces: `[{"name":"1","focused":false},{"name":"2","focused":true}]`,
+		swayGetTree: `{"id":1,"type":"root","nodes":[{"id":2,"type":"output","nodes":[
+			{"id":3,"type":"workspace","name":"1","nodes":[
+				{"id":10,"type":"con","app_id":"firefox","nodes":[]},
+				{"id":11,"type":"con","nodes":[
+					{"id":12,"type":"con","app_id":null,"window_properties":{"class":"Gimp","instance":"gimp"}}
+				]}
+			]},
+			{"id":4,"type":"workspace","name":"2","nodes":[],"floating_nodes":[
+				{"id":20,"type":"floating_con","app_id":"foot"}
+			]}
+		]}]}`,
+		swayRunCommand: `[{"success":true}]`,
+	})
+	s := sway{socket: socket}
+
+	windows, err := s.windows()
+	if err != nil {
+		t.Fatalf("windows returned error: %v", err)
+	}
+	expected := []window{
+		{id: "10", class: "firefox"},
+		{id: "12", class: "Gimp", instance: "gimp"},
+		{id: "20", class: "foot", current: true},
+	}
+	if !slices.Equal(windows, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, windows)
+	}
+
+	if err := s.focus(windows[0]); err != nil {
+		t.Fatalf("focus returned error: %v", err)
+	}
+	if !slices.Equal(*commands, []string{"[con_id=10] focus"}) {
+		t.Errorf("Expected a focus command, got %q", *commands)
+	}
+}
+
+func TestSwayFocusFailed(t *testing.T) {
+	socket, _ := fakeSway(t, map[uint32]string{
+		swayRunCommand: `[{"success":false,"error":"No matching node"}]`,
+	})
+	if err := (sway{socket: socket}).focus(window{id: "99"}); err == nil {
+		t.Error("Expected an error when sway can't focus")
+	}
+}
@@ -0,0 +1,129 @@
+package linux
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// ioctl requests of linux/uinput.h
+const (
+	uiDevCreate  = 0x5501
+	uiDevDestroy = 0x5502
+	uiDevSetup   = 0x405c5503 // _IOW('U', 3, struct uinput_setup)
+	uiSetEvbit   = 0x40045564
+	uiSetKeybit  = 0x40045565
+	uiGetSysname = 0x8050552c // _IOC(_IOC_READ, 'U', 44, 80)
+
+	busVirtual = 0x06
+)
+
+// VirtualKeyboard is a keyboard made with uinput, to type synthetic key
+// events the listener reads like those of a physical keyboard.
+type VirtualKeyboard struct {
+	f      *os.File
+	device string
+}
+
+// NewVirtualKeyboard creates a keyboard named name with every key of
+// RawToKeyLinux. It needs write access to /dev/uinput.
+func NewVirtualKeyboard(name string) (*VirtualKeyboard, error) {
+	f, err := os.OpenFile("/dev/uinput", os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &VirtualKeyboard{f: f}
+	if err := k.setup(name); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return k, nil
+}
+
+func (k *VirtualKeyboard) setup(name string) error {
+	if err := k.ioctl(uiSetEvbit, EV_KEY); err != nil {
+		return err
+	}
+	for code := range RawToKeyLinux {
+		if err := k.ioctl(uiSetKeybit, uintptr(code)); err != nil {
+			return err
+		}
+	}
+
+	// struct uinput_setup: struct input_id, char name[80], __u32 ff_effects_max
+	var setup [92]byte
+	binary.LittleEndian.PutUint16(setup[0:2], busVirtual)
+	copy(setup[8:87], name)
+	if err := k.ioctl(uiDevSetup, uintptr(unsafe.Pointer(&setup[0]))); err != nil {
+		return err
+	}
+	if err := k.ioctl(uiDevCreate, 0); err != nil {
+		return err
+	}
+
+	var sysname [80]byte
+	if err := k.ioctl(uiGetSysname, uintptr(unsafe.Pointer(&sysname[0]))); err != nil {
+		return err
+	}
+	input := string(sysname[:bytes.IndexByte(sysname[:], 0)])
+
+	// udev creates the event device shortly after the input device
+	for range 50 {
+		events, _ := filepath.Glob(filepath.Join(sysInputDir, input, "event*"))
+		if len(events) > 0 {
+			k.device = filepath.Join("/dev/input", filepath.Base(events[0]))
+			if _, err := os.Stat(k.device); err == nil {
+				return nil
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return fmt.Errorf("no event device for %s", input)
+}
+
+func (k *VirtualKeyboard) ioctl(req uintptr, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, k.f.Fd(), req, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// Device returns the path of the evdev device of the keyboard, e.g.
+// /dev/input/event7.
+func (k *VirtualKeyboard) Device() string {
+	return k.device
+}
+
+// Type presses the keys of a chord in order, then releases them in reverse.
+func (k *VirtualKeyboard) Type(codes ...uint16) error {
+	for _, code := range codes {
+		if err := k.emit(code, KeyPressed); err != nil {
+			return err
+		}
+	}
+	for i := len(codes) - 1; i >= 0; i-- {
+		if err := k.emit(codes[i], KeyReleased); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (k *VirtualKeyboard) emit(code uint16, value int32) error {
+	if err := WriteEvent(k.f, InputEvent{Type: EV_KEY, Code: code, Value: value}); err != nil {
+		return err
+	}
+	return WriteEvent(k.f, InputEvent{Type: EV_SYN})
+}
+
+// Close removes the keyboard.
+func (k *VirtualKeyboard) Close() error {
+	k.ioctl(uiDevDestroy, 0)
+	return k.f.Close()
+}
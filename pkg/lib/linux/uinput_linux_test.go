@@ -0,0 +1,50 @@
+package linux
+
+import (
+	"database/sql"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestVirtualKeyboard(t *testing.T) {
+	k, err := NewVirtualKeyboard("yay test keyboard")
+	if err != nil {
+		t.Skipf("uinput is unavailable: %v", err)
+	}
+	defer k.Close()
+
+	db := setupTestDatabase(t)
+	defer db.Close()
+	launched := stubLaunch(t)
+
+	if err := db.Insert("Firefox", "/usr/share/applications/firefox.desktop", "firefox", sql.NullString{String: "control+option+1", Valid: true}, "default", true); err != nil {
+		t.Fatalf("Failed to insert setting: %v", err)
+	}
+
+	f, err := os.Open(k.Device())
+	if err != nil {
+		t.Skipf("Can't read %s: %v", k.Device(), err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Listen(db, f, nil, nil)
+	}()
+
+	if err := k.Type(leftCtrl, rightAlt, key1); err != nil {
+		t.Fatalf("Type returned error: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(launched()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	f.Close()
+	<-done
+
+	if got := launched(); !slices.Equal(got, []string{"Firefox"}) {
+		t.Errorf("Expected Firefox to be opened, got %v", got)
+	}
+}
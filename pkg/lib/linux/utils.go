@@ -0,0 +1,143 @@
+package linux
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
+// AppDirectories returns the applications directories of the XDG base
+// directories, the user's first. See
+// https://specifications.freedesktop.org/basedir-spec/latest/
+func AppDirectories() []string {
+	var dirs []string
+	if home, err := dataHome(); err == nil {
+		dirs = append(dirs, filepath.Join(home, "applications"))
+	}
+
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		if dir != "" {
+			dirs = append(dirs, filepath.Join(dir, "applications"))
+		}
+	}
+	return dirs
+}
+
+// dataHome returns $XDG_DATA_HOME, ~/.local/share when unset.
+func dataHome() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+func GetSettings(database core.Database, dirs []string) ([]core.Setting, error) {
+	apps := getApps(dirs)
+	settings, err := database.Refresh(apps)
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// getApps lists the .desktop files of dirs. A desktop file id found in
+// several directories is the one of the first, as the spec has it. Entries
+// without an Exec key can't be launched and are skipped.
+func getApps(dirs []string) []core.App {
+	apps := []core.App{}
+	seen := map[string]bool{}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".desktop") || seen[entry.Name()] {
+				continue
+			}
+			desktop, err := ReadDesktopEntry(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				continue
+			}
+			seen[entry.Name()] = true
+
+			binName := ""
+			if args, err := splitExec(desktop.Exec); err == nil && len(args) > 0 {
+				binName = filepath.Base(args[0])
+			}
+			apps = append(apps, core.App{
+				Name:        desktop.Id(),
+				BinName:     binName,
+				Path:        desktop.File,
+				DisplayName: desktop.Name,
+				BundleId:    desktop.Id(),
+			})
+		}
+	}
+	return apps
+}
+
+// GetSupportDir returns Yay's directory inside $XDG_DATA_HOME, creating it if
+// needed.
+func GetSupportDir() (string, error) {
+	home, err := dataHome()
+	if err != nil {
+		return "", err
+	}
+	supportDir := filepath.Join(home, "yay")
+
+	if err := os.MkdirAll(supportDir, 0755); err != nil {
+		return "", err
+	}
+	return supportDir, nil
+}
+
+func GetDatabasePath() (string, error) {
+	supportDir, err := GetSupportDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(supportDir, "db.sqlite3"), nil
+}
+
+// GetLogPath returns the path of the log file errors are written to.
+func GetLogPath() (string, error) {
+	supportDir, err := GetSupportDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(supportDir, "yay.log"), nil
+}
+
+// GetThemePath returns the path of the user theme file.
+func GetThemePath() (string, error) {
+	supportDir, err := GetSupportDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(supportDir, "theme.json"), nil
+}
+
+// GetKeysPath returns the path of the file overriding the TUI key bindings.
+func GetKeysPath() (string, error) {
+	supportDir, err := GetSupportDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(supportDir, "keys.json"), nil
+}
@@ -0,0 +1,63 @@
+package linux
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestAppDirectories(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/home/me/data")
+	t.Setenv("XDG_DATA_DIRS", "/opt/share::/usr/share")
+	want := []string{"/home/me/data/applications", "/opt/share/applications", "/usr/share/applications"}
+	if got := AppDirectories(); !slices.Equal(got, want) {
+		t.Errorf("AppDirectories() = %q, want %q", got, want)
+	}
+
+	t.Setenv("HOME", "/home/me")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_DATA_DIRS", "")
+	want = []string{"/home/me/.local/share/applications", "/usr/local/share/applications", "/usr/share/applications"}
+	if got := AppDirectories(); !slices.Equal(got, want) {
+		t.Errorf("AppDirectories() without XDG variables = %q, want %q", got, want)
+	}
+}
+
+func TestGetApps(t *testing.T) {
+	user, system := t.TempDir(), t.TempDir()
+	writeDesktopFile(t, user, "firefox.desktop", "[Desktop Entry]\nName=My Firefox\nExec=/opt/firefox/firefox %u\n")
+	writeDesktopFile(t, system, "firefox.desktop", "[Desktop Entry]\nName=Firefox\nExec=firefox %u\n")
+	writeDesktopFile(t, system, "org.gnome.Terminal.desktop", "[Desktop Entry]\nName=Terminal\nExec=gnome-terminal\n")
+	writeDesktopFile(t, system, "broken.desktop", "[Desktop Entry]\nName=Broken\n")
+	writeDesktopFile(t, system, "notes.txt", "[Desktop Entry]\nName=Notes\nExec=notes\n")
+
+	apps := getApps([]string{user, filepath.Join(user, "missing"), system})
+	if len(apps) != 2 {
+		t.Fatalf("Expected 2 apps, got %+v", apps)
+	}
+
+	firefox := apps[0]
+	if firefox.Name != "firefox" || firefox.DisplayName != "My Firefox" || firefox.BinName != "firefox" ||
+		firefox.Path != filepath.Join(user, "firefox.desktop") || firefox.BundleId != "firefox" {
+		t.Errorf("Expected the user's Firefox entry, got %+v", firefox)
+	}
+	if terminal := apps[1]; terminal.BundleId != "org.gnome.Terminal" || terminal.BinName != "gnome-terminal" {
+		t.Errorf("Expected the terminal entry, got %+v", terminal)
+	}
+}
+
+func TestGetSettings(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	dir := t.TempDir()
+	writeDesktopFile(t, dir, "firefox.desktop", "[Desktop Entry]\nName=Firefox\nExec=firefox %u\n")
+
+	settings, err := GetSettings(*db, []string{dir})
+	if err != nil {
+		t.Fatalf("GetSettings returned error: %v", err)
+	}
+	if len(settings) != 1 || settings[0].Path != filepath.Join(dir, "firefox.desktop") || !settings[0].Enabled {
+		t.Errorf("Expected an enabled setting for Firefox, got %+v", settings)
+	}
+}
@@ -0,0 +1,147 @@
+//go:build x11 && cgo
+
+#include "x11_linux.h"
+
+#include <X11/XKBlib.h>
+#include <X11/Xatom.h>
+#include <X11/Xutil.h>
+#include <errno.h>
+#include <poll.h>
+#include <stdio.h>
+
+
+// Xlib reports errors to a process wide handler, grabKey syncs right after
+// grabbing so the error it sees is its own
+static volatile int lastError = 0;
+
+static int errorHandler(Display *dpy, XErrorEvent *event) {
+    lastError = event->error_code;
+    return 0;
+}
+
+Display *openDisplay(const char *name) {
+    XSetErrorHandler(errorHandler);
+    return XOpenDisplay(name[0] ? name : NULL);
+}
+
+void closeDisplay(Display *dpy) {
+    XCloseDisplay(dpy);
+}
+
+int grabKey(Display *dpy, unsigned long keysym, unsigned int modifiers) {
+    KeyCode keycode = XKeysymToKeycode(dpy, (KeySym)keysym);
+    if (keycode == 0) {
+        return -1;
+    }
+
+    lastError = 0;
+    XGrabKey(dpy, keycode, modifiers, DefaultRootWindow(dpy), False, GrabModeAsync, GrabModeAsync);
+    XSync(dpy, False);
+    return lastError == BadAccess ? 1 : 0;
+}
+
+void ungrabKeys(Display *dpy) {
+    XUngrabKey(dpy, AnyKey, AnyModifier, DefaultRootWindow(dpy));
+    XSync(dpy, False);
+}
+
+int nextKeyPress(Display *dpy, int timeoutMs, unsigned long *keysym, unsigned int *state, unsigned int *keycode) {
+    for (;;) {
+        while (XPending(dpy) > 0) {
+            XEvent event;
+            XNextEvent(dpy, &event);
+            if (event.type != KeyPress) {
+                continue;
+            }
+            // The unshifted keysym, so shift+1 is 1 rather than !
+            *keysym = XkbKeycodeToKeysym(dpy, event.xkey.keycode, 0, 0);
+            *state = event.xkey.state;
+            *keycode = event.xkey.keycode;
+            return 1;
+        }
+
+        // Xlib exits the process when it reads from a closed connection,
+        // notice the hang up first
+        struct pollfd fd = {ConnectionNumber(dpy), POLLIN, 0};
+        int n = poll(&fd, 1, timeoutMs);
+        if (n == 0 || (n < 0 && errno == EINTR)) {
+            return 0;
+        }
+        if (n < 0 || (fd.revents & (POLLHUP | POLLERR))) {
+            return -1;
+        }
+    }
+}
+
+// Reads a 32 bit property of window, the caller frees items with XFree
+static unsigned long *windowProperty(Display *dpy, Window window, const char *name, Atom type, unsigned long *count) {
+    Atom actualType;
+    int format;
+    unsigned long remaining;
+    unsigned char *data = NULL;
+
+    Atom property = XInternAtom(dpy, name, True);
+    if (property == None) {
+        return NULL;
+    }
+    if (XGetWindowProperty(dpy, window, property, 0, 4096, False, type, &actualType, &format, count, &remaining, &data) != Success) {
+        return NULL;
+    }
+    if (actualType != type || format != 32 || *count == 0) {
+        if (data) XFree(data);
+        return NULL;
+    }
+    return (unsigned long *)data;
+}
+
+int clientWindows(Display *dpy, unsigned long *windows, int max) {
+    unsigned long count;
+    unsigned long *list = windowProperty(dpy, DefaultRootWindow(dpy), "_NET_CLIENT_LIST", XA_WINDOW, &count);
+    if (!list) {
+        return -1;
+    }
+    int n = 0;
+    for (; n < (int)count && n < max; n++) {
+        windows[n] = list[n];
+    }
+    XFree(list);
+    return n;
+}
+
+int windowClass(Display *dpy, unsigned long window, char *instance, char *class, int size) {
+    XClassHint hint;
+    if (!XGetClassHint(dpy, (Window)window, &hint)) {
+        return 0;
+    }
+    snprintf(instance, size, "%s", hint.res_name ? hint.res_name : "");
+    snprintf(class, size, "%s", hint.res_class ? hint.res_class : "");
+    if (hint.res_name) XFree(hint.res_name);
+    if (hint.res_class) XFree(hint.res_class);
+    return 1;
+}
+
+long windowDesktop(Display *dpy, unsigned long window) {
+    unsigned long count;
+    unsigned long *desktop = window
+        ? windowProperty(dpy, (Window)window, "_NET_WM_DESKTOP", XA_CARDINAL, &count)
+        : windowProperty(dpy, DefaultRootWindow(dpy), "_NET_CURRENT_DESKTOP", XA_CARDINAL, &count);
+    if (!desktop) {
+        return -1;
+    }
+    long d = (long)(desktop[0] & 0xFFFFFFFF);
+    XFree(desktop);
+    return d;
+}
+
+void activateWindow(Display *dpy, unsigned long window) {
+    XEvent event = {0};
+    event.xclient.type = ClientMessage;
+    event.xclient.window = (Window)window;
+    event.xclient.message_type = XInternAtom(dpy, "_NET_ACTIVE_WINDOW", False);
+    event.xclient.format = 32;
+    event.xclient.data.l[0] = 2; // from a pager, window managers don't refuse it
+    event.xclient.data.l[1] = CurrentTime;
+
+    XSendEvent(dpy, DefaultRootWindow(dpy), False, SubstructureRedirectMask | SubstructureNotifyMask, &event);
+    XSync(dpy, False);
+}
@@ -0,0 +1,148 @@
+//go:build x11 && cgo
+
+package linux
+
+/*
+#cgo LDFLAGS: -lX11
+#include <stdlib.h>
+#include <x11_linux.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"slices"
+	"unsafe"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
+// Built with the X11 listener and EWMH focusing
+const x11Supported = true
+
+// x11Display is a connection to an X server
+type x11Display struct {
+	dpy *C.Display
+}
+
+// openX11Display connects to the X server named name, e.g. ":1", or to
+// $DISPLAY when name is empty.
+func openX11Display(name string) (*x11Display, error) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	dpy := C.openDisplay(cname)
+	if dpy == nil {
+		return nil, ErrNoDisplay
+	}
+	return &x11Display{dpy: dpy}, nil
+}
+
+func (x *x11Display) close() {
+	C.closeDisplay(x.dpy)
+}
+
+// grab grabs the keys of grabs with every combination of lock modifiers,
+// releasing those grabbed before, and returns the hotkeys another client
+// already grabbed.
+func (x *x11Display) grab(grabs []x11Grab) []string {
+	C.ungrabKeys(x.dpy)
+
+	var taken []string
+	for _, g := range grabs {
+		for _, ignored := range ignoredMasksX11 {
+			res := C.grabKey(x.dpy, C.ulong(g.keysym), C.uint(g.modifiers|ignored))
+			if res == 1 {
+				taken = append(taken, g.hotkey)
+				break
+			}
+			if res < 0 {
+				break // the keyboard has no such key
+			}
+		}
+	}
+	return taken
+}
+
+// Reported for hotkeys XGrabKey refuses
+var errGrabbed = errors.New("another app uses it")
+
+// X11Listener grabs the hotkeys of the enabled apps with XGrabKey, so it
+// works without access to /dev/input but only in X11 sessions. Unlike with
+// evdev the apps don't see grabbed hotkeys. An optional onEvent callback is
+// called for every hotkey pressed, and what the listener reports goes to log,
+// which may be nil.
+// This function blocks until StopListener is called or returns right away
+// when it can't connect to the X server.
+func X11Listener(db *core.Database, onEvent func(KeyEvent), log func(msg string, err error)) error {
+	return listenX11(db, "", onEvent, log, func() {
+		if log != nil {
+			log("Listening for global keyboard events", nil)
+		}
+	})
+}
+
+func listenX11(db *core.Database, display string, onEvent func(KeyEvent), log func(msg string, err error), started func()) error {
+	stop, release := listening()
+	defer release()
+
+	x, err := openX11Display(display)
+	if err != nil {
+		return err
+	}
+	defer x.close()
+
+	grabs, err := x11Grabs(db)
+	if err != nil {
+		return err
+	}
+	d := newDispatcher(db, nil, log)
+	defer d.close()
+
+	for _, hotkey := range x.grab(grabs) {
+		d.log("Couldn't grab "+hotkey, errGrabbed)
+	}
+
+	if started != nil {
+		started()
+	}
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+
+		var keysym C.ulong
+		var state, keycode C.uint
+		switch C.nextKeyPress(x.dpy, 1000, &keysym, &state, &keycode) {
+		case -1:
+			return errors.New("lost the connection to the X server")
+
+		case 0:
+			// Pick up the hotkeys edited since, e.g. in the TUI
+			latest, err := x11Grabs(db)
+			if err != nil {
+				d.log("Couldn't fetch the hotkeys", err)
+				continue
+			}
+			if slices.Equal(latest, grabs) {
+				continue
+			}
+			grabs = latest
+			for _, hotkey := range x.grab(grabs) {
+				d.log("Couldn't grab "+hotkey, errGrabbed)
+			}
+
+		case 1:
+			// With the evdev driver X11 keycodes are evdev codes plus 8
+			if onEvent != nil {
+				onEvent(KeyEvent{Code: uint16(keycode) - 8, Value: KeyPressed})
+			}
+			if hotkey, ok := hotkeyFromX11(uint32(keysym), uint(state)); ok {
+				d.dispatch(hotkey)
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+#ifndef X11_LINUX_H
+#define X11_LINUX_H
+
+#include <X11/Xlib.h>
+
+
+// Connects to the X server named name, or to $DISPLAY when name is empty.
+// Returns NULL when it can't connect
+Display *openDisplay(const char *name);
+void closeDisplay(Display *dpy);
+
+// Grabs the key of keysym with modifiers on the root window, returns 0 once
+// grabbed, 1 when another client already grabbed it and -1 when no key
+// types keysym
+int grabKey(Display *dpy, unsigned long keysym, unsigned int modifiers);
+
+// Releases every key grabbed on the root window
+void ungrabKeys(Display *dpy);
+
+// Waits up to timeoutMs for a key press, returns 1 with its keysym, modifier
+// state and keycode, 0 on timeout and -1 when the connection was lost
+int nextKeyPress(Display *dpy, int timeoutMs, unsigned long *keysym, unsigned int *state, unsigned int *keycode);
+
+// Writes up to max windows of _NET_CLIENT_LIST to windows, returns how many
+// or -1 when the window manager doesn't support EWMH
+int clientWindows(Display *dpy, unsigned long *windows, int max);
+
+// Writes the WM_CLASS instance and class of window, returns 0 when it has
+// none
+int windowClass(Display *dpy, unsigned long window, char *instance, char *class, int size);
+
+// Returns _NET_WM_DESKTOP of window, or _NET_CURRENT_DESKTOP when window is
+// 0, and -1 when unknown. 0xFFFFFFFF means every desktop
+long windowDesktop(Display *dpy, unsigned long window);
+
+// Asks the window manager to switch to window through _NET_ACTIVE_WINDOW
+void activateWindow(Display *dpy, unsigned long window);
+
+#endif // X11_LINUX_H
@@ -0,0 +1,92 @@
+//go:build x11 && xtest && cgo
+
+package linux
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"testing"
+	"time"
+)
+
+// startXvfb runs a headless X server for the test and returns its display
+// name, skipping the test when Xvfb isn't installed.
+func startXvfb(t *testing.T) string {
+	t.Helper()
+	path, err := exec.LookPath("Xvfb")
+	if err != nil {
+		t.Skip("Xvfb is not installed")
+	}
+
+	display := fmt.Sprintf(":%d", 100+os.Getpid()%400)
+	cmd := exec.Command(path, display, "-nolisten", "tcp")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start Xvfb: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	for range 100 {
+		if x, err := openX11Display(display); err == nil {
+			x.close()
+			return display
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("Xvfb didn't start on %s", display)
+	return ""
+}
+
+func TestX11Listener(t *testing.T) {
+	display := startXvfb(t)
+
+	db := setupTestDatabase(t)
+	defer db.Close()
+	launched := stubLaunch(t)
+
+	if err := db.Insert("Firefox", "/usr/share/applications/firefox.desktop", "firefox", sql.NullString{String: "control+option+1", Valid: true}, "default", true); err != nil {
+		t.Fatalf("Failed to insert setting: %v", err)
+	}
+
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- listenX11(db, display, nil, nil, func() { close(started) })
+	}()
+	select {
+	case <-started:
+	case err := <-done:
+		t.Fatalf("listenX11 returned early: %v", err)
+	}
+
+	x, err := openX11Display(display)
+	if err != nil {
+		t.Fatalf("Failed to connect to Xvfb: %v", err)
+	}
+	defer x.close()
+
+	// Only the grabbed hotkey opens Firefox
+	for _, hotkey := range []string{"control+1", "control+option+1"} {
+		if err := x.typeHotkey(hotkey); err != nil {
+			t.Skipf("Can't type %s: %v", hotkey, err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(launched()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	StopListener()
+	if err := <-done; err != nil {
+		t.Errorf("listenX11 returned error: %v", err)
+	}
+	if got := launched(); !slices.Equal(got, []string{"Firefox"}) {
+		t.Errorf("Expected Firefox to be opened once, got %v", got)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !linux || !x11 || !cgo
+
+package linux
+
+import "github.com/Builtbyjb/yay/pkg/lib/core"
+
+// Built without Xlib, rebuilding with -tags x11 links the X11 listener and
+// EWMH focusing
+const x11Supported = false
+
+// Without Xlib there's no window manager to ask under X11, apps always start
+// a new instance
+func newEWMH(display string) windowManager {
+	return nil
+}
+
+// X11Listener returns ErrNoX11, builds without the x11 tag don't link Xlib.
+func X11Listener(db *core.Database, onEvent func(KeyEvent), log func(msg string, err error)) error {
+	return ErrNoX11
+}
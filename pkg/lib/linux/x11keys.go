@@ -0,0 +1,225 @@
+package linux
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/Builtbyjb/yay/pkg/lib/chord"
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
+var (
+	// ErrNoDisplay is returned when the X11 listener can't connect to the X
+	// server.
+	ErrNoDisplay = errors.New("couldn't connect to the X server, check that DISPLAY is set")
+	// ErrNoX11 is returned by the X11 listener of builds without the x11 tag.
+	ErrNoX11 = errors.New("built without X11 support, rebuild with -tags x11")
+)
+
+// X11 modifier masks of X11/X.h. Super is usually on Mod4 and Alt on Mod1.
+const (
+	ShiftMask   = 1 << 0
+	LockMask    = 1 << 1
+	ControlMask = 1 << 2
+	Mod1Mask    = 1 << 3
+	Mod2Mask    = 1 << 4 // Num Lock
+	Mod4Mask    = 1 << 6
+)
+
+// Modifier names to the X11 masks of their keys
+var modifierMasksX11 = map[string]uint{
+	"shift":   ShiftMask,
+	"option":  Mod1Mask,
+	"control": ControlMask,
+	"command": Mod4Mask,
+}
+
+// Lock masks that don't change a hotkey, a key is grabbed with each
+// combination of them so Caps Lock or Num Lock don't get in the way
+var ignoredMasksX11 = []uint{0, LockMask, Mod2Mask, LockMask | Mod2Mask}
+
+// X11 keysyms to the canonical key names of RawToKeyDarwin, see
+// X11/keysymdef.h. Keysyms are those of the unshifted level, so shift+1 is
+// found as "1" rather than "!".
+var KeysymToKeyX11 = map[uint32]string{
+	0xff1b: "esc",
+	0x0030: "0",
+	0x0031: "1",
+	0x0032: "2",
+	0x0033: "3",
+	0x0034: "4",
+	0x0035: "5",
+	0x0036: "6",
+	0x0037: "7",
+	0x0038: "8",
+	0x0039: "9",
+	0x002d: "dash",
+	0x003d: "equal sign",
+	0xff08: "backspace",
+	0xff09: "tab",
+	0x0061: "a",
+	0x0062: "b",
+	0x0063: "c",
+	0x0064: "d",
+	0x0065: "e",
+	0x0066: "f",
+	0x0067: "g",
+	0x0068: "h",
+	0x0069: "i",
+	0x006a: "j",
+	0x006b: "k",
+	0x006c: "l",
+	0x006d: "m",
+	0x006e: "n",
+	0x006f: "o",
+	0x0070: "p",
+	0x0071: "q",
+	0x0072: "r",
+	0x0073: "s",
+	0x0074: "t",
+	0x0075: "u",
+	0x0076: "v",
+	0x0077: "w",
+	0x0078: "x",
+	0x0079: "y",
+	0x007a: "z",
+	0x005b: "open bracket",
+	0x005d: "close bracket / å",
+	0xff0d: "enter",
+	0x003b: "semi-colon / ñ",
+	0x0027: "single quote / ø / ä",
+	0x0060: "`",
+	0x005c: "back slash",
+	0x002c: "comma",
+	0x002e: "period",
+	0x002f: "forward slash / ç",
+	0x0020: "space",
+	0xffe5: "capslock",
+	0xffbe: "f1",
+	0xffbf: "f2",
+	0xffc0: "f3",
+	0xffc1: "f4",
+	0xffc2: "f5",
+	0xffc3: "f6",
+	0xffc4: "f7",
+	0xffc5: "f8",
+	0xffc6: "f9",
+	0xffc7: "f10",
+	0xffc8: "f11",
+	0xffc9: "f12",
+	0xffca: "f13",
+	0xffcb: "f14",
+	0xffcc: "f15",
+	0xff50: "home",
+	0xff51: "left arrow",
+	0xff52: "up arrow",
+	0xff53: "right arrow",
+	0xff54: "down arrow",
+	0xff55: "page up",
+	0xff56: "page down",
+	0xff57: "end",
+	0xff63: "insert",
+	0xffff: "delete",
+	0xff0b: "clear",
+	0xff8d: "numpad enter",
+	0xffaa: "multiply",
+	0xffab: "add",
+	0xffad: "subtract",
+	0xffae: "decimal point",
+	0xffaf: "divide",
+	0xffb0: "numpad 0",
+	0xffb1: "numpad 1",
+	0xffb2: "numpad 2",
+	0xffb3: "numpad 3",
+	0xffb4: "numpad 4",
+	0xffb5: "numpad 5",
+	0xffb6: "numpad 6",
+	0xffb7: "numpad 7",
+	0xffb8: "numpad 8",
+	0xffb9: "numpad 9",
+}
+
+// x11Grab is a key to grab with XGrabKey
+type x11Grab struct {
+	hotkey    string
+	keysym    uint32
+	modifiers uint
+}
+
+// keysymOf returns the keysym of a canonical key name.
+func keysymOf(key string) (uint32, bool) {
+	for keysym, name := range KeysymToKeyX11 {
+		if name == key {
+			return keysym, true
+		}
+	}
+	return 0, false
+}
+
+// parseX11Grab returns the key and modifiers to grab for hotkey, ok is false
+// when X11 can't grab it.
+func parseX11Grab(hotkey string) (x11Grab, bool) {
+	parts := strings.Split(hotkey, "+")
+	key := parts[len(parts)-1]
+	keysym, ok := keysymOf(key)
+	if !ok || len(parts) < 2 {
+		return x11Grab{}, false
+	}
+
+	g := x11Grab{hotkey: hotkey, keysym: keysym}
+	for _, mod := range parts[:len(parts)-1] {
+		mask, ok := modifierMasksX11[mod]
+		if !ok {
+			return x11Grab{}, false
+		}
+		g.modifiers |= mask
+	}
+	return g, true
+}
+
+// hotkeyFromX11 returns the canonical hotkey of a key press with the
+// modifier state of an XKeyEvent.
+func hotkeyFromX11(keysym uint32, state uint) (string, bool) {
+	key, ok := KeysymToKeyX11[keysym]
+	if !ok {
+		return "", false
+	}
+
+	var mods []string
+	for mod, mask := range modifierMasksX11 {
+		if state&mask != 0 {
+			mods = append(mods, mod)
+		}
+	}
+	if len(mods) == 0 {
+		return "", false
+	}
+	return chord.CanonicalHotkey(mods, key), true
+}
+
+// x11Grabs returns the keys to grab: the pause toggle and the hotkeys of the
+// enabled apps, sorted by hotkey.
+func x11Grabs(db *core.Database) ([]x11Grab, error) {
+	settings, err := db.GetAllSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	hotkeys := []string{core.PauseToggleHotkey}
+	for _, s := range settings {
+		if s.Enabled && s.HotKey.Valid && s.HotKey.String != "" {
+			hotkeys = append(hotkeys, s.HotKey.String)
+		}
+	}
+	slices.Sort(hotkeys)
+	hotkeys = slices.Compact(hotkeys)
+
+	var grabs []x11Grab
+	for _, hotkey := range hotkeys {
+		if g, ok := parseX11Grab(hotkey); ok {
+			grabs = append(grabs, g)
+		}
+	}
+	return grabs, nil
+}
@@ -0,0 +1,101 @@
+package linux
+
+import (
+	"database/sql"
+	"slices"
+	"testing"
+)
+
+func TestKeysymTable(t *testing.T) {
+	// Every key evdev knows, except the modifiers, can be grabbed
+	keys := map[string]bool{}
+	for _, key := range KeysymToKeyX11 {
+		keys[key] = true
+	}
+	for code, key := range RawToKeyLinux {
+		if !slices.Contains(ModifiersLinux, key) && !keys[key] {
+			t.Errorf("No keysym for %q, evdev code %d", key, code)
+		}
+	}
+}
+
+func TestParseX11Grab(t *testing.T) {
+	tests := []struct {
+		hotkey    string
+		ok        bool
+		keysym    uint32
+		modifiers uint
+	}{
+		{"command+1", true, 0x31, Mod4Mask},
+		{"control+option+shift+a", true, 0x61, ControlMask | Mod1Mask | ShiftMask},
+		{"command+shift+esc", true, 0xff1b, Mod4Mask | ShiftMask},
+		{"command+left arrow", true, 0xff51, Mod4Mask},
+		{"a", false, 0, 0},
+		{"hyper+a", false, 0, 0},
+		{"command+nope", false, 0, 0},
+	}
+	for _, tc := range tests {
+		g, ok := parseX11Grab(tc.hotkey)
+		if ok != tc.ok || g.keysym != tc.keysym || g.modifiers != tc.modifiers {
+			t.Errorf("parseX11Grab(%q) = %#x, %#x, %v, want %#x, %#x, %v",
+				tc.hotkey, g.keysym, g.modifiers, ok, tc.keysym, tc.modifiers, tc.ok)
+		}
+	}
+}
+
+func TestHotkeyFromX11(t *testing.T) {
+	tests := []struct {
+		keysym uint32
+		state  uint
+		hotkey string
+		ok     bool
+	}{
+		{0x31, Mod4Mask, "command+1", true},
+		{0x61, ShiftMask | Mod1Mask | ControlMask, "control+option+shift+a", true},
+		{0x31, Mod4Mask | LockMask | Mod2Mask, "command+1", true}, // locks are ignored
+		{0x31, 0, "", false},
+		{0x31, LockMask, "", false},
+		{0x10000e9, Mod4Mask, "", false}, // unknown keysym
+	}
+	for _, tc := range tests {
+		hotkey, ok := hotkeyFromX11(tc.keysym, tc.state)
+		if hotkey != tc.hotkey || ok != tc.ok {
+			t.Errorf("hotkeyFromX11(%#x, %#x) = %q, %v, want %q, %v", tc.keysym, tc.state, hotkey, ok, tc.hotkey, tc.ok)
+		}
+	}
+}
+
+func TestX11Grabs(t *testing.T) {
+	db := setupTestDatabase(t)
+	defer db.Close()
+
+	settings := []struct {
+		name    string
+		hotkey  string
+		enabled bool
+	}{
+		{"Firefox", "command+1", true},
+		{"Notes", "command+2", false},
+		{"Terminal", "", true},
+		{"Editor", "control+option+e", true},
+	}
+	for _, s := range settings {
+		hotkey := sql.NullString{String: s.hotkey, Valid: s.hotkey != ""}
+		if err := db.Insert(s.name, "/usr/share/applications/"+s.name+".desktop", "", hotkey, "default", s.enabled); err != nil {
+			t.Fatalf("Failed to insert setting: %v", err)
+		}
+	}
+
+	grabs, err := x11Grabs(db)
+	if err != nil {
+		t.Fatalf("x11Grabs returned error: %v", err)
+	}
+	var hotkeys []string
+	for _, g := range grabs {
+		hotkeys = append(hotkeys, g.hotkey)
+	}
+	expected := []string{"command+1", "command+shift+esc", "control+option+e"}
+	if !slices.Equal(hotkeys, expected) {
+		t.Errorf("Expected grabs %v, got %v", expected, hotkeys)
+	}
+}
@@ -0,0 +1,71 @@
+//go:build x11 && xtest && cgo
+
+package linux
+
+/*
+#cgo LDFLAGS: -lXtst
+#include <X11/extensions/XTest.h>
+#include <x11_linux.h>
+
+// Presses or releases the key of keysym through the XTEST extension,
+// returns 0 when the server lacks XTEST or no key types keysym
+static int fakeKey(Display *dpy, unsigned long keysym, int press) {
+    int eventBase, errorBase, major, minor;
+    if (!XTestQueryExtension(dpy, &eventBase, &errorBase, &major, &minor)) {
+        return 0;
+    }
+    KeyCode keycode = XKeysymToKeycode(dpy, (KeySym)keysym);
+    if (keycode == 0) {
+        return 0;
+    }
+
+    if (!XTestFakeKeyEvent(dpy, keycode, press ? True : False, CurrentTime)) {
+        return 0;
+    }
+    XSync(dpy, False);
+    return 1;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Keysyms of the left modifier keys, for the tests to type hotkeys through
+// XTEST
+var modifierKeysymsX11 = map[string]uint32{
+	"shift":   0xffe1,
+	"control": 0xffe3,
+	"option":  0xffe9,
+	"command": 0xffeb,
+}
+
+// typeHotkey presses the keys of hotkey through XTEST, then releases them in
+// reverse, like a user typing it.
+func (x *x11Display) typeHotkey(hotkey string) error {
+	parts := strings.Split(hotkey, "+")
+	keysym, ok := keysymOf(parts[len(parts)-1])
+	if !ok {
+		return fmt.Errorf("no keysym for %q", hotkey)
+	}
+
+	var keysyms []uint32
+	for _, mod := range parts[:len(parts)-1] {
+		keysyms = append(keysyms, modifierKeysymsX11[mod])
+	}
+	keysyms = append(keysyms, keysym)
+
+	for _, k := range keysyms {
+		if C.fakeKey(x.dpy, C.ulong(k), 1) == 0 {
+			return errors.New("the X server lacks the XTEST extension")
+		}
+	}
+	for _, k := range slices.Backward(keysyms) {
+		C.fakeKey(x.dpy, C.ulong(k), 0)
+	}
+	return nil
+}
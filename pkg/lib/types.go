@@ -1,5 +1,12 @@
 package lib
 
+import (
+	"io"
+
+	"github.com/Builtbyjb/yay/pkg/lib/chord"
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
 type KeyEvent struct {
 	Keycode   uint16
 	Flags     uint64
@@ -15,3 +22,27 @@ const (
 type CKeyMsg struct {
 	Event KeyEvent
 }
+
+// ErrUnboundHotkey is returned by TriggerHotkey when pressing the hotkey
+// wouldn't do anything.
+var ErrUnboundHotkey = core.ErrUnboundHotkey
+
+// SuspendedError is returned by TriggerHotkey when the hotkey passes through
+// right now, e.g. while hotkeys are paused.
+type SuspendedError = core.SuspendedError
+
+// RecordKeyEvents returns a KeyEventListener callback writing every event to
+// w, for chord.ReadEvents to replay in tests. The first write failure is
+// reported to log and ends the recording, the listener keeps running.
+func RecordKeyEvents(w io.Writer, log func(msg string, err error)) func(KeyEvent) {
+	failed := false
+	return func(event KeyEvent) {
+		if failed {
+			return
+		}
+		if err := chord.WriteEvent(w, chord.KeyEvent(event)); err != nil {
+			failed = true
+			log("Stopped recording key events", err)
+		}
+	}
+}
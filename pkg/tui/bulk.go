@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Steps of the bulk edit menu
+type bulkStep int
+
+const (
+	bulkMenu bulkStep = iota // choosing an action
+	bulkMode                 // choosing the mode to set
+	bulkTag                  // typing the tag to add
+)
+
+// openBulk shows the bulk edit menu for the selected apps.
+func (m *model) openBulk() {
+	if !m.hasSelection() {
+		k := m.keymap
+		m.notify(severityInfo, fmt.Sprintf("Mark apps with %s, %s or %s first", k.Mark.Help().Key, k.Visual.Help().Key, k.SelectAll.Help().Key))
+		return
+	}
+	m.state = stateBulk
+	m.bulkStep = bulkMenu
+}
+
+func (m *model) closeBulk() {
+	m.state = stateBrowse
+	m.bulkStep = bulkMenu
+	m.bulkInput.Reset()
+	m.bulkInput.Blur()
+}
+
+func (m model) handleBulkKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keymap.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keymap.Cancel):
+		m.closeBulk()
+		return m, nil
+	}
+
+	ids := m.selectedIds()
+
+	switch m.bulkStep {
+	case bulkMenu:
+		switch {
+		case key.Matches(msg, m.keymap.BulkEnable, m.keymap.BulkDisable):
+			enabled := key.Matches(msg, m.keymap.BulkEnable)
+			if err := m.db.UpdateEnabledAll(ids, enabled); err != nil {
+				m.notifyError(err)
+				return m, nil
+			}
+			m.updateSettings(ids, func(s *core.Setting) { s.Enabled = enabled })
+			if enabled {
+				m.finishBulk(fmt.Sprintf("Enabled %d apps", len(ids)))
+			} else {
+				m.finishBulk(fmt.Sprintf("Disabled %d apps", len(ids)))
+			}
+
+		case key.Matches(msg, m.keymap.BulkClear):
+			if err := m.db.ClearHotkeys(ids); err != nil {
+				m.notifyError(err)
+				return m, nil
+			}
+			m.updateSettings(ids, func(s *core.Setting) { s.HotKey = sql.NullString{} })
+			m.finishBulk(fmt.Sprintf("Cleared the hotkeys of %d apps", len(ids)))
+
+		case key.Matches(msg, m.keymap.BulkMode):
+			m.bulkStep = bulkMode
+
+		case key.Matches(msg, m.keymap.BulkTag):
+			m.bulkStep = bulkTag
+			m.bulkInput.Reset()
+			return m, m.bulkInput.Focus()
+		}
+		return m, nil
+
+	case bulkMode:
+		// Modes are picked by their 1-based position
+		n, err := strconv.Atoi(msg.String())
+		if err != nil || n < 1 || n > len(AvailableModes) {
+			return m, nil
+		}
+		mode := AvailableModes[n-1]
+		if err := m.db.UpdateModeAll(ids, mode); err != nil {
+			m.notifyError(err)
+			return m, nil
+		}
+		m.updateSettings(ids, func(s *core.Setting) { s.Mode = mode })
+		m.finishBulk(fmt.Sprintf("Set %d apps to %s mode", len(ids), mode))
+		return m, nil
+
+	case bulkTag:
+		if !key.Matches(msg, m.keymap.Select) {
+			var cmd tea.Cmd
+			m.bulkInput, cmd = m.bulkInput.Update(msg)
+			return m, cmd
+		}
+		tag := core.NormalizeTag(m.bulkInput.Value())
+		if err := m.db.AddTagAll(ids, tag); err != nil {
+			m.notifyError(err)
+			return m, nil
+		}
+		m.updateSettings(ids, func(s *core.Setting) {
+			if !slices.Contains(s.Tags, tag) {
+				s.Tags = append(s.Tags, tag)
+				slices.Sort(s.Tags)
+			}
+		})
+		m.finishBulk(fmt.Sprintf("Tagged %d apps %s", len(ids), tag))
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// updateSettings applies update to the in-memory settings with the given ids.
+func (m *model) updateSettings(ids []int, update func(*core.Setting)) {
+	for _, id := range ids {
+		if idx := m.indexOfId(id); idx >= 0 {
+			update(&m.settings[idx])
+		}
+	}
+}
+
+// finishBulk reports a completed bulk action and clears the selection.
+func (m *model) finishBulk(summary string) {
+	m.closeBulk()
+	m.clearSelection()
+	m.updateFilter()
+	m.notify(severityInfo, summary)
+}
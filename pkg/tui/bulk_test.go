@@ -0,0 +1,273 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func enabledOf(m model, name string) bool {
+	for _, s := range m.settings {
+		if s.Name == name {
+			return s.Enabled
+		}
+	}
+	return false
+}
+
+// ─── Selection ───
+
+func TestMark_TogglesAndAdvances(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = sendKey(t, m, MARK_KEY)
+	if m.cursor != 1 {
+		t.Errorf("expected cursor to advance to 1, got %d", m.cursor)
+	}
+	if len(m.selectedIds()) != 1 {
+		t.Fatalf("expected 1 selected app, got %d", len(m.selectedIds()))
+	}
+
+	m = sendKey(t, m, "up")
+	m = sendKey(t, m, MARK_KEY)
+	if m.hasSelection() {
+		t.Error("expected marking again to unmark")
+	}
+}
+
+func TestMark_SurvivesFiltering(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = sendKey(t, m, MARK_KEY)
+	m.searchInput.SetValue("safari")
+	m.updateFilter()
+
+	if len(m.selectedIds()) != 1 {
+		t.Errorf("expected the mark to survive filtering, got %d", len(m.selectedIds()))
+	}
+}
+
+func TestVisual_SelectsRange(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = sendKey(t, m, "down")
+	m = sendKey(t, m, VISUAL_KEY)
+	m = sendKey(t, m, "down")
+	m = sendKey(t, m, "down")
+
+	if !m.visual {
+		t.Fatal("expected visual mode")
+	}
+	if len(m.selectedIds()) != 3 {
+		t.Errorf("expected 3 apps in range, got %d", len(m.selectedIds()))
+	}
+	if m.isRowSelected(0) || !m.isRowSelected(1) || !m.isRowSelected(3) {
+		t.Error("expected rows 1-3 to be selected")
+	}
+
+	// Closing the range keeps its apps marked
+	m = sendKey(t, m, VISUAL_KEY)
+	if m.visual {
+		t.Error("expected visual mode to end")
+	}
+	if len(m.marked) != 3 {
+		t.Errorf("expected 3 marked apps, got %d", len(m.marked))
+	}
+}
+
+func TestSelectAll_FilteredOnly(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.searchInput.SetValue("mode:desktop")
+	m.updateFilter()
+
+	m = sendKey(t, m, SELECT_ALL_KEY)
+	if len(m.selectedIds()) != 2 {
+		t.Fatalf("expected the 2 desktop apps selected, got %d", len(m.selectedIds()))
+	}
+
+	m = sendKey(t, m, SELECT_ALL_KEY)
+	if m.hasSelection() {
+		t.Error("expected select all to toggle off")
+	}
+}
+
+func TestMark_GroupHeaderMarksGroup(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.settings[0].Tags = []string{"browsers"} // Finder
+	m.settings[1].Tags = []string{"browsers"} // Firefox
+	m.updateFilter()
+	m.toggleGrouping()
+	m.cursor = 0
+
+	m = sendKey(t, m, MARK_KEY)
+	if len(m.selectedIds()) != 2 {
+		t.Errorf("expected the 2 apps of the group selected, got %d", len(m.selectedIds()))
+	}
+}
+
+func TestCancel_ClearsSelectionBeforeQuitting(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = sendKey(t, m, MARK_KEY)
+	m = sendKey(t, m, "esc")
+	if m.hasSelection() {
+		t.Error("expected esc to clear the selection")
+	}
+
+	if _, cmd := m.Update(specialKeyMsg(tea.KeyEscape)); cmd == nil {
+		t.Error("expected esc without a selection to quit")
+	}
+}
+
+// ─── Bulk actions ───
+
+func TestBulk_RequiresSelection(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = sendKey(t, m, BULK_KEY)
+	if m.state != stateBrowse {
+		t.Errorf("expected to stay in browse, got %d", m.state)
+	}
+	if len(m.activeNotifications()) != 1 {
+		t.Error("expected a hint to select apps")
+	}
+}
+
+func TestBulk_DisableAndUndo(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	// Firefox and Notes are enabled
+	m = sendKey(t, m, "down")
+	m = sendKey(t, m, MARK_KEY)
+	m = sendKey(t, m, MARK_KEY)
+	m = sendKey(t, m, BULK_KEY)
+	if m.state != stateBulk {
+		t.Fatalf("expected stateBulk, got %d", m.state)
+	}
+	m = sendKey(t, m, BULK_DISABLE_KEY)
+
+	if m.state != stateBrowse || m.hasSelection() {
+		t.Error("expected the bulk edit to finish and clear the selection")
+	}
+	if enabledOf(m, "Firefox") || enabledOf(m, "Notes") {
+		t.Error("expected Firefox and Notes to be disabled")
+	}
+	if !enabledOf(m, "Safari") {
+		t.Error("expected Safari to stay enabled")
+	}
+
+	// A single undo reverts the whole bulk edit
+	m = sendKey(t, m, UNDO_KEY)
+	if !enabledOf(m, "Firefox") || !enabledOf(m, "Notes") {
+		t.Error("expected undo to re-enable both apps")
+	}
+}
+
+func TestBulk_SetMode(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = sendKey(t, m, SELECT_ALL_KEY)
+	m = sendKey(t, m, BULK_KEY)
+	m = sendKey(t, m, BULK_MODE_KEY)
+	m = sendKey(t, m, "9") // out of range, ignored
+	if m.state != stateBulk {
+		t.Fatalf("expected stateBulk, got %d", m.state)
+	}
+	m = sendKey(t, m, "2")
+
+	for _, s := range m.settings {
+		if s.Mode != "desktop" {
+			t.Errorf("expected %s in desktop mode, got %s", s.Name, s.Mode)
+		}
+	}
+
+	stored, _ := database.GetAllSettings()
+	for _, s := range stored {
+		if s.Mode != "desktop" {
+			t.Errorf("expected %s stored in desktop mode, got %s", s.Name, s.Mode)
+		}
+	}
+}
+
+func TestBulk_ClearHotkeys(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = sendKey(t, m, SELECT_ALL_KEY)
+	m = sendKey(t, m, BULK_KEY)
+	m = sendKey(t, m, BULK_CLEAR_KEY)
+
+	for _, s := range m.settings {
+		if s.HotKey.Valid {
+			t.Errorf("expected %s without hotkey, got %q", s.Name, s.HotKey.String)
+		}
+	}
+}
+
+func TestBulk_Tag(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = sendKey(t, m, MARK_KEY)
+	m = sendKey(t, m, MARK_KEY)
+	m = sendKey(t, m, BULK_KEY)
+	m = sendKey(t, m, BULK_TAG_KEY)
+	for _, r := range "Core Apps" {
+		m = sendKey(t, m, string(r))
+	}
+	m = sendKey(t, m, "enter")
+
+	if m.state != stateBrowse {
+		t.Fatalf("expected stateBrowse, got %d", m.state)
+	}
+	if !slices.Contains(m.settings[0].Tags, "core-apps") || !slices.Contains(m.settings[1].Tags, "core-apps") {
+		t.Errorf("expected both apps tagged core-apps, got %v and %v", m.settings[0].Tags, m.settings[1].Tags)
+	}
+
+	tags, _ := database.GetTags()
+	if len(tags[m.settings[0].Id]) != 1 {
+		t.Errorf("expected the tag to be stored, got %v", tags[m.settings[0].Id])
+	}
+}
+
+func TestBulk_CancelKeepsSelection(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = sendKey(t, m, MARK_KEY)
+	m = sendKey(t, m, BULK_KEY)
+	m = sendKey(t, m, "esc")
+
+	if m.state != stateBrowse {
+		t.Errorf("expected stateBrowse, got %d", m.state)
+	}
+	if !m.hasSelection() {
+		t.Error("expected the selection to be kept")
+	}
+}
+
+func TestBulk_ViewShowsSelection(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.width = 120
+	m.height = 40
+
+	m = sendKey(t, m, MARK_KEY)
+	view := m.View()
+	if !containsAny(view, MARK_ICON) {
+		t.Error("expected the mark icon in the view")
+	}
+	if !containsAny(view, "1 selected") {
+		t.Error("expected the selection count in the status line")
+	}
+}
@@ -0,0 +1,346 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tableColumn is a column the table can show and be sorted by
+type tableColumn struct {
+	id      string
+	title   string
+	width   int      // longer values are truncated
+	edit    columnID // column edited in row focus, colNone if read only
+	desc    bool     // sorted descending by default, e.g. most used first
+	value   func(s core.Setting) string
+	compare func(a, b core.Setting) int
+}
+
+// Every column, in the order they are cycled through when sorting
+var tableColumns = []tableColumn{
+	{
+		id: "name", title: "Application", width: colWidthName,
+		value: func(s core.Setting) string { return s.Title() },
+		compare: func(a, b core.Setting) int {
+			return strings.Compare(strings.ToLower(a.Title()), strings.ToLower(b.Title()))
+		},
+	},
+	{
+		id: "hotkey", title: "HotKey", width: colWidthHotkey, edit: colKey,
+		value: func(s core.Setting) string { return s.HotKey.String },
+		compare: func(a, b core.Setting) int {
+			return strings.Compare(a.HotKey.String, b.HotKey.String)
+		},
+	},
+	{
+		id: "mode", title: "Mode", width: colWidthMode, edit: colMode,
+		value: func(s core.Setting) string { return s.Mode },
+		compare: func(a, b core.Setting) int {
+			return strings.Compare(a.Mode, b.Mode)
+		},
+	},
+	{
+		id: "enabled", title: "Enabled", width: colWidthEnabled, edit: colEnabled,
+		value: func(s core.Setting) string { return formatBool(s.Enabled) },
+		compare: func(a, b core.Setting) int {
+			return strings.Compare(formatBool(a.Enabled), formatBool(b.Enabled))
+		},
+	},
+	{
+		id: "last_used", title: "Last Used", width: colWidthLastUsed, desc: true,
+		value: func(s core.Setting) string { return formatLastUsed(s.Usage.LastUsed, time.Now()) },
+		compare: func(a, b core.Setting) int {
+			return a.Usage.LastUsed.Compare(b.Usage.LastUsed)
+		},
+	},
+	{
+		id: "launches", title: "Launches", width: colWidthLaunches, desc: true,
+		value: func(s core.Setting) string { return strconv.Itoa(s.Usage.LaunchCount) },
+		compare: func(a, b core.Setting) int {
+			return cmp.Compare(a.Usage.LaunchCount, b.Usage.LaunchCount)
+		},
+	},
+	{
+		id: "dir", title: "Directory", width: colWidthPath,
+		value: func(s core.Setting) string { return installDir(s.Path) },
+		compare: func(a, b core.Setting) int {
+			return strings.Compare(installDir(a.Path), installDir(b.Path))
+		},
+	},
+	{
+		id: "path", title: "Path", width: colWidthPath,
+		value: func(s core.Setting) string { return s.Path },
+		compare: func(a, b core.Setting) int {
+			return strings.Compare(a.Path, b.Path)
+		},
+	},
+	{
+		id: "bundle_id", title: "Bundle ID", width: colWidthBundleId,
+		value: func(s core.Setting) string { return s.BundleId },
+		compare: func(a, b core.Setting) int {
+			return strings.Compare(a.BundleId, b.BundleId)
+		},
+	},
+	{
+		id: "version", title: "Version", width: colWidthVersion,
+		value: func(s core.Setting) string { return s.Version },
+		compare: func(a, b core.Setting) int {
+			return strings.Compare(a.Version, b.Version)
+		},
+	},
+}
+
+// Preference keys
+const (
+	prefColumns = "columns"
+	prefSort    = "sort" // column id, prefixed with "-" when descending
+)
+
+// installDir returns the directory an app is installed in, the one holding
+// the .app bundle for paths inside a bundle, e.g. "/Applications" for
+// "/Applications/Safari.app/Contents/MacOS".
+func installDir(path string) string {
+	if i := strings.LastIndex(path, ".app/"); i >= 0 {
+		return filepath.Dir(path[:i+len(".app")])
+	}
+	return filepath.Dir(path)
+}
+
+func findColumn(id string) (tableColumn, bool) {
+	i := slices.IndexFunc(tableColumns, func(c tableColumn) bool { return c.id == id })
+	if i < 0 {
+		return tableColumn{}, false
+	}
+	return tableColumns[i], true
+}
+
+// visibleColumns returns the columns shown in the table, in order.
+func (m model) visibleColumns() []tableColumn {
+	columns := make([]tableColumn, 0, len(m.columns))
+	for _, id := range m.columns {
+		if c, ok := findColumn(id); ok {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// nextEditableColumn returns the visible editable column after current,
+// wrapping around, or colNone if no editable column is shown.
+func (m model) nextEditableColumn(current columnID) columnID {
+	var editable []columnID
+	for _, c := range m.visibleColumns() {
+		if c.edit != colNone {
+			editable = append(editable, c.edit)
+		}
+	}
+	if len(editable) == 0 {
+		return colNone
+	}
+	i := slices.Index(editable, current)
+	return editable[(i+1)%len(editable)]
+}
+
+// loadPreferences restores the visible columns and sort order. Unknown
+// column ids, e.g. from a newer version, are ignored.
+func (m *model) loadPreferences() {
+	if m.db == nil {
+		return
+	}
+
+	if value, ok, err := m.db.GetPreference(prefColumns); err != nil {
+		m.notifyError(err)
+	} else if ok {
+		columns := []string{"name"}
+		for _, id := range strings.Split(value, ",") {
+			if _, known := findColumn(id); known && !slices.Contains(columns, id) {
+				columns = append(columns, id)
+			}
+		}
+		m.columns = columns
+	}
+
+	if value, ok, err := m.db.GetPreference(prefSort); err != nil {
+		m.notifyError(err)
+	} else if ok {
+		id, desc := strings.CutPrefix(value, "-")
+		if _, known := findColumn(id); known {
+			m.sortCol = id
+			m.sortDesc = desc
+		}
+	}
+}
+
+func (m *model) saveColumns() {
+	if m.db == nil {
+		return
+	}
+	if err := m.db.SetPreference(prefColumns, strings.Join(m.columns, ",")); err != nil {
+		m.notifyError(err)
+	}
+}
+
+func (m *model) saveSort() {
+	if m.db == nil {
+		return
+	}
+	value := m.sortCol
+	if m.sortDesc {
+		value = "-" + value
+	}
+	if err := m.db.SetPreference(prefSort, value); err != nil {
+		m.notifyError(err)
+	}
+}
+
+// sortedByScore reports whether search results are ranked by match score,
+// which is only the case while the table has its default sort order.
+func (m model) sortedByScore() bool {
+	return m.sortCol == DEFAULT_SORT && !m.sortDesc
+}
+
+// sortIndices orders searchedIndices by the sort column. Apps without a value
+// go last in either direction, ties keep the alphabetical order.
+func (m *model) sortIndices() {
+	c, ok := findColumn(m.sortCol)
+	if !ok {
+		return
+	}
+	slices.SortStableFunc(m.searchedIndices, func(i, j int) int {
+		a, b := m.settings[i], m.settings[j]
+		if missingA, missingB := c.value(a) == "", c.value(b) == ""; missingA != missingB {
+			if missingA {
+				return 1
+			}
+			return -1
+		}
+		if m.sortDesc {
+			return c.compare(b, a)
+		}
+		return c.compare(a, b)
+	})
+}
+
+// cycleSort sorts by the next column, in its default direction.
+func (m *model) cycleSort() {
+	i := slices.IndexFunc(tableColumns, func(c tableColumn) bool { return c.id == m.sortCol })
+	next := tableColumns[(i+1)%len(tableColumns)]
+	m.sortCol = next.id
+	m.sortDesc = next.desc
+	m.saveSort()
+	m.updateFilter()
+}
+
+func (m *model) reverseSort() {
+	m.sortDesc = !m.sortDesc
+	m.saveSort()
+	m.updateFilter()
+}
+
+// headerTitle returns the title of a column with an arrow on the sort column.
+func (m model) headerTitle(c tableColumn) string {
+	if c.id != m.sortCol {
+		return c.title
+	}
+	if m.sortDesc {
+		return c.title + " ▼"
+	}
+	return c.title + " ▲"
+}
+
+// toggleColumn shows or hides the column under the picker cursor. The
+// application name is always shown.
+func (m *model) toggleColumn() {
+	c := tableColumns[m.columnCursor]
+	if c.id == "name" {
+		return
+	}
+	if i := slices.Index(m.columns, c.id); i >= 0 {
+		m.columns = slices.Delete(m.columns, i, i+1)
+	} else {
+		m.columns = append(m.columns, c.id)
+	}
+	m.saveColumns()
+}
+
+func (m model) handleColumnsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keymap.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keymap.Cancel) || key.Matches(msg, m.keymap.Columns):
+		m.state = stateBrowse
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Up):
+		m.columnCursor = max(m.columnCursor-1, 0)
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Down):
+		m.columnCursor = min(m.columnCursor+1, len(tableColumns)-1)
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Edit):
+		m.toggleColumn()
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// ColumnsView lists every column with its visibility.
+func (m model) ColumnsView() string {
+	lines := make([]string, 0, len(tableColumns))
+	for i, c := range tableColumns {
+		prefix := "  "
+		if i == m.columnCursor {
+			prefix = "> "
+		}
+		check := "[ ]"
+		if slices.Contains(m.columns, c.id) {
+			check = "[x]"
+		}
+
+		line := fmt.Sprintf("%s%s %s", prefix, check, c.title)
+		if i == m.columnCursor {
+			lines = append(lines, CursorRowStyle.Render(line))
+		} else {
+			lines = append(lines, NormalRowStyle.Render(line))
+		}
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		PanelStyle.Width(max(m.width-2, 0)).Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
+
+// formatLastUsed describes t relative to now, or returns "" if the app was
+// never launched.
+func formatLastUsed(t time.Time, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+	return t.Format("2006-01-02")
+}
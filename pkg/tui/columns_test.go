@@ -0,0 +1,242 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func visibleNames(m model) []string {
+	names := make([]string, 0, len(m.searchedIndices))
+	for _, idx := range m.searchedIndices {
+		names = append(names, m.settings[idx].Name)
+	}
+	return names
+}
+
+// ─── Sorting ───
+
+func TestSort_DefaultIsByName(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	expected := []string{"Finder", "Firefox", "Notes", "Safari", "Terminal"}
+	if got := visibleNames(m); !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestSort_ByHotkeyPutsMissingLast(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = sendKey(t, m, SORT_KEY)
+	if m.sortCol != "hotkey" {
+		t.Fatalf("expected sort by hotkey, got %s", m.sortCol)
+	}
+
+	expected := []string{"Notes", "Firefox", "Finder", "Safari", "Terminal"}
+	if got := visibleNames(m); !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	m = sendKey(t, m, SORT_REVERSE_KEY)
+	expected = []string{"Finder", "Firefox", "Notes", "Safari", "Terminal"}
+	if got := visibleNames(m); !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestSort_ByLaunchesMostUsedFirst(t *testing.T) {
+	database := setupTestDatabase(t)
+	settings := testSettings(t, database)
+	settings[3].Usage.LaunchCount = 7 // Safari
+	settings[2].Usage.LaunchCount = 3 // Notes
+	m := NewModel(database, settings, "0.1.0")
+
+	m.sortCol = "launches"
+	m.sortDesc = true
+	m.updateFilter()
+
+	if got := visibleNames(m)[:2]; !slices.Equal(got, []string{"Safari", "Notes"}) {
+		t.Errorf("expected Safari then Notes, got %v", got)
+	}
+}
+
+func TestSort_CycleUsesColumnDefaultDirection(t *testing.T) {
+	m := NewModel(nil, nil, "0.1.0")
+	for m.sortCol != "last_used" {
+		m.cycleSort()
+	}
+	if !m.sortDesc {
+		t.Error("expected last used to sort descending by default")
+	}
+	m.cycleSort()
+	if m.sortCol != "launches" || !m.sortDesc {
+		t.Errorf("expected launches descending, got %s desc=%v", m.sortCol, m.sortDesc)
+	}
+	m.cycleSort()
+	if m.sortDesc {
+		t.Error("expected directory to sort ascending by default")
+	}
+}
+
+func TestSort_OverridesSearchRanking(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.searchInput.SetValue("fi")
+	m.updateFilter()
+	ranked := visibleNames(m)
+
+	m.sortCol = "name"
+	m.sortDesc = true
+	m.updateFilter()
+	if got := visibleNames(m); slices.Equal(got, ranked) || got[0] != "Safari" {
+		t.Errorf("expected name descending to override the ranking, got %v", got)
+	}
+}
+
+func TestSort_HeaderShowsDirection(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.width = 120
+	m.height = 40
+
+	if !containsAny(m.View(), "Application ▲") {
+		t.Error("expected an ascending arrow on the name column")
+	}
+	m = sendKey(t, m, SORT_REVERSE_KEY)
+	if !containsAny(m.View(), "Application ▼") {
+		t.Error("expected a descending arrow on the name column")
+	}
+}
+
+// ─── Columns ───
+
+func TestColumns_ToggleVisibility(t *testing.T) {
+	database := setupTestDatabase(t)
+	settings := testSettings(t, database)
+	settings[0].BundleId = "com.apple.finder"
+	m := NewModel(database, settings, "0.1.0")
+	m.width = 160
+	m.height = 40
+
+	m = sendKey(t, m, COLUMNS_KEY)
+	if m.state != stateColumns {
+		t.Fatalf("expected stateColumns, got %d", m.state)
+	}
+
+	// Bundle ID is the ninth column
+	for m.columnCursor < 8 {
+		m = sendKey(t, m, "j")
+	}
+	m = sendKey(t, m, " ")
+	m = sendKey(t, m, "esc")
+
+	view := m.View()
+	if !containsAny(view, "Bundle ID") || !containsAny(view, "com.apple.finder") {
+		t.Error("expected the bundle id column in the table")
+	}
+
+	// The name column can't be hidden
+	m = sendKey(t, m, COLUMNS_KEY)
+	for m.columnCursor > 0 {
+		m = sendKey(t, m, "k")
+	}
+	m = sendKey(t, m, " ")
+	if !slices.Contains(m.columns, "name") {
+		t.Error("expected the name column to stay visible")
+	}
+}
+
+func TestColumns_HiddenEditableColumn(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.width = 120
+	m.height = 40
+	m.columns = []string{"name", "mode"}
+
+	m = sendKey(t, m, "enter")
+	if containsAny(m.View(), "HotKey") {
+		t.Error("expected the hotkey column to be hidden")
+	}
+	if m.activeCol != colMode {
+		t.Errorf("expected editing to start on the mode column, got %d", m.activeCol)
+	}
+
+	m = sendKey(t, m, "tab")
+	if m.activeCol != colMode {
+		t.Errorf("expected tab to stay on the only editable column, got %d", m.activeCol)
+	}
+}
+
+func TestColumns_PreferencesPersisted(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = sendKey(t, m, COLUMNS_KEY)
+	m = sendKey(t, m, "j") // hotkey
+	m = sendKey(t, m, " ")
+	m = sendKey(t, m, "esc")
+	m = sendKey(t, m, SORT_KEY)
+	m = sendKey(t, m, SORT_REVERSE_KEY)
+
+	reloaded := NewModel(database, m.settings, "0.1.0")
+	if !slices.Equal(reloaded.columns, []string{"name", "mode", "enabled"}) {
+		t.Errorf("expected saved columns, got %v", reloaded.columns)
+	}
+	if reloaded.sortCol != "hotkey" || !reloaded.sortDesc {
+		t.Errorf("expected hotkey descending, got %s desc=%v", reloaded.sortCol, reloaded.sortDesc)
+	}
+}
+
+func TestColumns_UnknownPreferencesIgnored(t *testing.T) {
+	database := setupTestDatabase(t)
+	database.SetPreference(prefColumns, "hotkey,color,hotkey")
+	database.SetPreference(prefSort, "-color")
+
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	if !slices.Equal(m.columns, []string{"name", "hotkey"}) {
+		t.Errorf("expected name and hotkey, got %v", m.columns)
+	}
+	if m.sortCol != DEFAULT_SORT || m.sortDesc {
+		t.Errorf("expected the default sort, got %s desc=%v", m.sortCol, m.sortDesc)
+	}
+}
+
+func TestInstallDir(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/Applications/Safari.app/Contents/MacOS", "/Applications"},
+		{"/Applications/Utilities/Terminal.app/Contents/MacOS", "/Applications/Utilities"},
+		{"/Applications/Xcode.app/Contents/Applications/Simulator.app/Contents/MacOS", "/Applications/Xcode.app/Contents/Applications"},
+		{"/usr/bin/firefox", "/usr/bin"},
+	}
+	for _, tc := range tests {
+		if got := installDir(tc.path); got != tc.expected {
+			t.Errorf("installDir(%q) = %q, want %q", tc.path, got, tc.expected)
+		}
+	}
+}
+
+func TestFormatLastUsed(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		t        time.Time
+		expected string
+	}{
+		{time.Time{}, ""},
+		{now.Add(-10 * time.Second), "just now"},
+		{now.Add(-5 * time.Minute), "5m ago"},
+		{now.Add(-3 * time.Hour), "3h ago"},
+		{now.Add(-50 * time.Hour), "2d ago"},
+		{now.AddDate(0, -2, 0), "2026-01-10"},
+	}
+	for _, tc := range tests {
+		if got := formatLastUsed(tc.t, now); got != tc.expected {
+			t.Errorf("formatLastUsed(%v) = %q, want %q", tc.t, got, tc.expected)
+		}
+	}
+}
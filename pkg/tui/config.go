@@ -1,5 +1,7 @@
 package tui
 
+import "time"
+
 var AvailableModes = []string{"default", "desktop"}
 
 var AvailableModifiersMacos = []string{"Shift", "Option", "Control", "Command"}
@@ -12,6 +14,10 @@ const (
 	stateBrowse   focusState = iota // navigating the list, q exits
 	stateFilter                     // typing in the filter input
 	stateRowFocus                   // a row is focused for editing
+	stateConflict                   // a recorded hotkey is already in use
+	stateLog                        // the notification log panel is open
+	stateBulk                       // choosing an action for the selected apps
+	stateColumns                    // choosing the visible table columns
 )
 
 // Column focus within a focused row
@@ -29,17 +35,109 @@ const (
 	colWidthHotkey  = 18
 	colWidthMode    = 14
 	colWidthEnabled = 10
+	colWidthIcon    = 3 // two columns for the image plus a separator
+
+	colWidthLastUsed = 10
+	colWidthLaunches = 9
+	colWidthPath     = 40
+	colWidthBundleId = 30
+	colWidthVersion  = 12
 )
 
-/* Colors */
-const PRIMARY_COLOR = "#fffff"
-const SECONDARY_COLOR = "#b6b8ba"
-const PRIMARY_ACCENT_COLOR = "#1a3a5c"
-const SECONDARY_ACCENT_COLOR = "#0f3460"
-const ACTIVE_COLOR = "#00b4d8"
+// Columns shown and sort order used until the user picks others
+var DEFAULT_COLUMNS = []string{"name", "hotkey", "mode", "enabled"}
+
+const DEFAULT_SORT = "name"
+
+/* Inspector */
+// Terminal width below which the inspector is hidden
+const INSPECTOR_MIN_WIDTH = 130
+const INSPECTOR_MIN_PANE_WIDTH = 36
+const INSPECTOR_MAX_PANE_WIDTH = 56
+
+// Hotkey changes listed in the inspector
+const INSPECTOR_HISTORY_LIMIT = 10
+
+/* Themes */
+// Picks the dark or light theme from the terminal background
+const THEME_AUTO = "auto"
+
+var DARK_THEME = Theme{
+	Name:            "dark",
+	Primary:         "#ffffff",
+	Secondary:       "#b6b8ba",
+	PrimaryAccent:   "#1a3a5c",
+	SecondaryAccent: "#0f3460",
+	Active:          "#00b4d8",
+	Error:           "#e06c75",
+	Warning:         "#e5c07b",
+}
+
+var LIGHT_THEME = Theme{
+	Name:            "light",
+	Primary:         "#1f2328",
+	Secondary:       "#59636e",
+	PrimaryAccent:   "#cfe3fb",
+	SecondaryAccent: "#a8cdf7",
+	Active:          "#0969da",
+	Error:           "#cf222e",
+	Warning:         "#9a6700",
+}
+
+var HIGH_CONTRAST_THEME = Theme{
+	Name:            "high-contrast",
+	Primary:         "#ffffff",
+	Secondary:       "#e0e0e0",
+	PrimaryAccent:   "#0000c0",
+	SecondaryAccent: "#800080",
+	Active:          "#ffff00",
+	Error:           "#ff4040",
+	Warning:         "#ffa500",
+}
+
+/* Mouse */
+// Longest gap between the clicks of a double-click
+const DOUBLE_CLICK_INTERVAL = 400 * time.Millisecond
+
+/* Notifications */
+// How long toasts stay visible
+const NOTIFICATION_TIMEOUT = 4 * time.Second
+const ERROR_NOTIFICATION_TIMEOUT = 8 * time.Second
+
+// Notifications kept for the log panel
+const MAX_NOTIFICATIONS = 200
+
+/* Icons */
+// Shown in place of the app icon when the terminal can't draw images
+const FALLBACK_ICON = "▪"
+
+// Shown next to selected apps
+const MARK_ICON = "●"
 
 /* Keys */
 const SWITCH_COLUMN_KEY = "tab"
 const SEARCH_KEY = "/"
 const CANCEL_KEY = "esc"
 const EXIT_KEY = "ctrl+c"
+const GROUP_KEY = "t"
+const SWAP_KEY = "s"
+const REASSIGN_KEY = "r"
+const CONFIRM_KEY = "y"
+const LOG_KEY = "e"
+const UNDO_KEY = "u"
+const REDO_KEY = "ctrl+r"
+const MARK_KEY = " "
+const VISUAL_KEY = "v"
+const SELECT_ALL_KEY = "a"
+const BULK_KEY = "b"
+const BULK_ENABLE_KEY = "e"
+const BULK_DISABLE_KEY = "d"
+const BULK_MODE_KEY = "m"
+const BULK_CLEAR_KEY = "x"
+const BULK_TAG_KEY = "t"
+const SORT_KEY = "o"
+const SORT_REVERSE_KEY = "O"
+const COLUMNS_KEY = "c"
+const INSPECTOR_KEY = "i"
+const LAUNCH_KEY = "l"
+const TEST_BINDING_KEY = "T"
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pendingHotkey is a recorded hotkey waiting for the user to resolve its
+// conflicts before it is saved
+type pendingHotkey struct {
+	idx       int // settings index the hotkey was recorded for
+	hotkey    string
+	conflicts []core.Conflict
+}
+
+// otherSetting returns the application currently bound to the hotkey.
+func (p pendingHotkey) otherSetting() (*core.Setting, bool) {
+	for _, c := range p.conflicts {
+		if c.Kind == core.ConflictSetting {
+			return c.Setting, true
+		}
+	}
+	return nil, false
+}
+
+// reserved reports whether the hotkey can't be used at all.
+func (p pendingHotkey) reserved() bool {
+	return slices.ContainsFunc(p.conflicts, func(c core.Conflict) bool {
+		return c.Kind == core.ConflictReserved
+	})
+}
+
+func (p pendingHotkey) String() string {
+	parts := make([]string, 0, len(p.conflicts))
+	for _, c := range p.conflicts {
+		parts = append(parts, c.String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// assignHotkey saves hotkey for the setting at idx, or asks the user how to
+// resolve its conflicts first.
+func (m *model) assignHotkey(idx int, hotkey string) {
+	conflicts := m.checker.Check(hotkey, m.settings[idx].Id, m.settings)
+	if len(conflicts) == 0 {
+		m.setHotkey(idx, hotkey)
+		return
+	}
+
+	m.pending = &pendingHotkey{idx: idx, hotkey: hotkey, conflicts: conflicts}
+	m.state = stateConflict
+}
+
+func (m *model) setHotkey(idx int, hotkey string) {
+	m.settings[idx].HotKey = sql.NullString{String: hotkey, Valid: true}
+	if err := m.db.UpdateHotkey(m.settings[idx].Id, m.settings[idx].HotKey); err != nil {
+		m.notifyError(err)
+	}
+}
+
+// indexOfId returns the settings index of the setting with the given id.
+func (m model) indexOfId(id int) int {
+	return slices.IndexFunc(m.settings, func(s core.Setting) bool {
+		return s.Id == id
+	})
+}
+
+func (m model) handleConflictKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.pending
+	other, hasOther := p.otherSetting()
+
+	switch {
+	case key.Matches(msg, m.keymap.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keymap.Cancel):
+		m.closeConflict()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Swap):
+		if !hasOther || p.reserved() {
+			return m, nil
+		}
+		otherIdx := m.indexOfId(other.Id)
+		if err := m.db.SwapHotkeys(m.settings[p.idx].Id, other.Id); err != nil {
+			m.notifyError(err)
+		} else {
+			m.settings[otherIdx].HotKey = m.settings[p.idx].HotKey
+			m.settings[p.idx].HotKey = sql.NullString{String: p.hotkey, Valid: true}
+			m.settings[otherIdx].Scope, m.settings[p.idx].Scope = m.settings[p.idx].Scope, m.settings[otherIdx].Scope
+			m.notify(severityInfo, fmt.Sprintf("Swapped hotkeys with %s", other.Title()))
+		}
+		m.closeConflict()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Reassign):
+		if !hasOther || p.reserved() {
+			return m, nil
+		}
+		otherIdx := m.indexOfId(other.Id)
+		if err := m.db.ReassignHotkey(other.Id, m.settings[p.idx].Id, p.hotkey); err != nil {
+			m.notifyError(err)
+		} else {
+			m.settings[otherIdx].HotKey = sql.NullString{String: "", Valid: false}
+			m.settings[p.idx].HotKey = sql.NullString{String: p.hotkey, Valid: true}
+			m.notify(severityInfo, fmt.Sprintf("Removed %s from %s", p.hotkey, other.Title()))
+		}
+		m.closeConflict()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Confirm):
+		// Only system shortcuts can be overridden in place
+		if hasOther || p.reserved() {
+			return m, nil
+		}
+		m.setHotkey(p.idx, p.hotkey)
+		m.closeConflict()
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m *model) closeConflict() {
+	m.pending = nil
+	m.state = stateRowFocus
+}
@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/Builtbyjb/yay/pkg/lib"
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
+// recordHotkey focuses the cursor row, starts recording and sends command+<key>
+// through the event tap message path.
+func recordHotkey(t *testing.T, m model, keycode uint16) model {
+	t.Helper()
+	m = sendKey(t, m, "enter")
+	m = sendKey(t, m, " ")
+
+	result, _ := m.Update(lib.CKeyMsg{Event: lib.KeyEvent{Keycode: keycodeCommand, EventType: lib.EventFlagsChanged}})
+	m = result.(model)
+	result, _ = m.Update(lib.CKeyMsg{Event: lib.KeyEvent{Keycode: keycode, EventType: lib.EventKeyDown}})
+	return result.(model)
+}
+
+func hotkeyOf(m model, name string) string {
+	for _, s := range m.settings {
+		if s.Name == name {
+			return s.HotKey.String
+		}
+	}
+	return ""
+}
+
+func TestRecordKey_NoConflictSaves(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = recordHotkey(t, m, keycodeF)
+
+	if m.state != stateRowFocus {
+		t.Errorf("expected stateRowFocus, got %d", m.state)
+	}
+	if got := hotkeyOf(m, "Finder"); got != "command+f" {
+		t.Errorf("expected Finder hotkey command+f, got %q", got)
+	}
+}
+
+func TestRecordKey_SettingConflictPrompts(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.settings[4].HotKey.String = "command+n" // Terminal
+	m.settings[4].HotKey.Valid = true
+	database.UpdateHotkey(m.settings[4].Id, m.settings[4].HotKey)
+
+	m = recordHotkey(t, m, keycodeN)
+
+	if m.state != stateConflict {
+		t.Fatalf("expected stateConflict, got %d", m.state)
+	}
+	if got := hotkeyOf(m, "Finder"); got != "ctrl+3" {
+		t.Errorf("expected Finder hotkey unchanged, got %q", got)
+	}
+
+	m.width = 120
+	m.height = 40
+	if !containsAny(m.View(), "already used by Terminal") {
+		t.Error("expected conflict description in the view")
+	}
+}
+
+func TestConflict_Swap(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.settings[4].HotKey.String = "command+n"
+	m.settings[4].HotKey.Valid = true
+	database.UpdateHotkey(m.settings[4].Id, m.settings[4].HotKey)
+
+	m = recordHotkey(t, m, keycodeN)
+	m = sendKey(t, m, SWAP_KEY)
+
+	if m.state != stateRowFocus {
+		t.Errorf("expected stateRowFocus after swap, got %d", m.state)
+	}
+	if got := hotkeyOf(m, "Finder"); got != "command+n" {
+		t.Errorf("expected Finder to get command+n, got %q", got)
+	}
+	if got := hotkeyOf(m, "Terminal"); got != "ctrl+3" {
+		t.Errorf("expected Terminal to get Finder's old hotkey, got %q", got)
+	}
+
+	stored, _ := database.FindByHotkey("command+n")
+	if stored == nil || stored.Name != "Finder" {
+		t.Errorf("expected swap to be persisted, got %v", stored)
+	}
+}
+
+func TestConflict_Reassign(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.settings[4].HotKey.String = "command+n"
+	m.settings[4].HotKey.Valid = true
+	database.UpdateHotkey(m.settings[4].Id, m.settings[4].HotKey)
+
+	m = recordHotkey(t, m, keycodeN)
+	m = sendKey(t, m, REASSIGN_KEY)
+
+	if got := hotkeyOf(m, "Finder"); got != "command+n" {
+		t.Errorf("expected Finder to get command+n, got %q", got)
+	}
+	if got := hotkeyOf(m, "Terminal"); got != "" {
+		t.Errorf("expected Terminal hotkey to be cleared, got %q", got)
+	}
+}
+
+func TestConflict_CancelKeepsHotkeys(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.settings[4].HotKey.String = "command+n"
+	m.settings[4].HotKey.Valid = true
+	database.UpdateHotkey(m.settings[4].Id, m.settings[4].HotKey)
+
+	m = recordHotkey(t, m, keycodeN)
+	m = sendKey(t, m, "esc")
+
+	if m.state != stateRowFocus {
+		t.Errorf("expected stateRowFocus after cancel, got %d", m.state)
+	}
+	if got := hotkeyOf(m, "Finder"); got != "ctrl+3" {
+		t.Errorf("expected Finder hotkey unchanged, got %q", got)
+	}
+}
+
+func TestConflict_ReservedCannotBeAssigned(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.checker.Reserved = func(hotkey string) (string, bool) {
+		return "test", hotkey == "command+f"
+	}
+
+	m = recordHotkey(t, m, keycodeF)
+	if m.state != stateConflict {
+		t.Fatalf("expected stateConflict, got %d", m.state)
+	}
+
+	m = sendKey(t, m, CONFIRM_KEY)
+	if m.state != stateConflict {
+		t.Error("expected reserved hotkey to stay unresolved")
+	}
+	if got := hotkeyOf(m, "Finder"); got != "ctrl+3" {
+		t.Errorf("expected Finder hotkey unchanged, got %q", got)
+	}
+}
+
+func TestConflict_SystemShortcutAssignAnyway(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.checker.System = []core.Shortcut{{Hotkey: "command+f", Name: "Find"}}
+
+	m = recordHotkey(t, m, keycodeF)
+	if m.state != stateConflict {
+		t.Fatalf("expected stateConflict, got %d", m.state)
+	}
+
+	m = sendKey(t, m, CONFIRM_KEY)
+	if m.state != stateRowFocus {
+		t.Errorf("expected stateRowFocus, got %d", m.state)
+	}
+	if got := hotkeyOf(m, "Finder"); got != "command+f" {
+		t.Errorf("expected Finder hotkey command+f, got %q", got)
+	}
+}
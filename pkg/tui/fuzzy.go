@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"unicode"
+)
+
+// Scoring constants, modelled on fzf's v1 algorithm
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	// Matching right after a separator, e.g. "c" in "visual-code"
+	bonusBoundary = scoreMatch / 2
+	// Matching an upper case letter after a lower case one, e.g. "C" in "VSCode"
+	bonusCamel = bonusBoundary - 1
+	// Matching right after the previous match
+	bonusConsecutive = -(scoreGapStart + scoreGapExtension)
+	// The bonus of the first pattern character counts double
+	bonusFirstCharMultiplier = 2
+)
+
+// fuzzyMatch reports whether the runes of pattern appear in order in text,
+// ignoring case. It returns a score, higher for tighter matches on word
+// boundaries, and the rune positions in text that matched. pattern must be
+// lower case.
+func fuzzyMatch(pattern []rune, text string) (int, []int, bool) {
+	if len(pattern) == 0 {
+		return 0, nil, true
+	}
+
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// Forward scan finds the first occurrence of the whole pattern...
+	pi, start, end := 0, -1, -1
+	for i, r := range lower {
+		if r != pattern[pi] {
+			continue
+		}
+		if pi == 0 {
+			start = i
+		}
+		pi++
+		if pi == len(pattern) {
+			end = i + 1
+			break
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// ...and a backward scan from its end finds the shortest window
+	pi = len(pattern) - 1
+	for i := end - 1; i >= start; i-- {
+		if lower[i] == pattern[pi] {
+			pi--
+			if pi < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	score := 0
+	positions := make([]int, 0, len(pattern))
+	consecutive := 0
+	inGap := false
+	pi = 0
+	for i := start; i < end && pi < len(pattern); i++ {
+		if lower[i] != pattern[pi] {
+			if inGap {
+				score += scoreGapExtension
+			} else {
+				score += scoreGapStart
+			}
+			inGap = true
+			consecutive = 0
+			continue
+		}
+
+		bonus := charBonus(runes, i)
+		score += scoreMatch
+		switch {
+		case pi == 0:
+			score += bonus * bonusFirstCharMultiplier
+		case consecutive > 0:
+			score += max(bonus, bonusConsecutive)
+		default:
+			score += bonus
+		}
+
+		positions = append(positions, i)
+		consecutive++
+		inGap = false
+		pi++
+	}
+
+	return score, positions, true
+}
+
+// charBonus scores where in a word the rune at i sits.
+func charBonus(runes []rune, i int) int {
+	if i == 0 {
+		return bonusBoundary
+	}
+	prev, cur := runes[i-1], runes[i]
+	switch {
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev):
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return bonusCamel
+	case unicode.IsLetter(prev) && unicode.IsDigit(cur):
+		return bonusCamel
+	}
+	return 0
+}
@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestFuzzyMatch_Subsequence(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		matched bool
+	}{
+		{"", "anything", true},
+		{"fx", "Firefox", true},
+		{"ffx", "Firefox", true},
+		{"xf", "Firefox", false},
+		{"term", "Terminal", true},
+		{"terminals", "Terminal", false},
+		{"vsc", "Visual Studio Code", true},
+	}
+	for _, tc := range tests {
+		_, _, ok := fuzzyMatch([]rune(tc.pattern), tc.text)
+		if ok != tc.matched {
+			t.Errorf("fuzzyMatch(%q, %q) matched = %v, want %v", tc.pattern, tc.text, ok, tc.matched)
+		}
+	}
+}
+
+func TestFuzzyMatch_Positions(t *testing.T) {
+	_, positions, ok := fuzzyMatch([]rune("code"), "Visual Studio Code")
+	if !ok {
+		t.Fatal("expected a match")
+	}
+	// The backward scan should prefer the tight "Code" over scattered letters
+	expected := []int{14, 15, 16, 17}
+	if !slices.Equal(positions, expected) {
+		t.Errorf("expected positions %v, got %v", expected, positions)
+	}
+}
+
+func TestFuzzyMatch_PrefersBoundaries(t *testing.T) {
+	boundary, _, _ := fuzzyMatch([]rune("sc"), "Studio Code")
+	inner, _, _ := fuzzyMatch([]rune("sc"), "Mascot")
+	if boundary <= inner {
+		t.Errorf("expected word boundary match to score higher: %d <= %d", boundary, inner)
+	}
+}
+
+func TestFuzzyMatch_PrefersConsecutive(t *testing.T) {
+	consecutive, _, _ := fuzzyMatch([]rune("fire"), "Firefox")
+	scattered, _, _ := fuzzyMatch([]rune("fire"), "Finder Reader")
+	if consecutive <= scattered {
+		t.Errorf("expected consecutive match to score higher: %d <= %d", consecutive, scattered)
+	}
+}
+
+func TestUpdateFilter_MatchesBundleIdAndHotkey(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(nil, testSettings(t, database), "0.1.0")
+	m.settings[0].BundleId = "com.apple.finder"
+
+	m.searchInput.SetValue("com.apple")
+	m.updateFilter()
+	if len(m.searchedIndices) != 1 || m.settings[m.searchedIndices[0]].Name != "Finder" {
+		t.Fatalf("expected Finder to match by bundle id")
+	}
+	if len(m.matches[m.searchedIndices[0]]) != 0 {
+		t.Error("expected no name highlight for a bundle id match")
+	}
+
+	m.searchInput.SetValue("alt+n")
+	m.updateFilter()
+	if len(m.searchedIndices) != 1 || m.settings[m.searchedIndices[0]].Name != "Notes" {
+		t.Fatalf("expected Notes to match by hotkey")
+	}
+}
+
+func TestUpdateFilter_RecordsNamePositions(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(nil, testSettings(t, database), "0.1.0")
+	m.searchInput.SetValue("ffx")
+	m.updateFilter()
+
+	if len(m.searchedIndices) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(m.searchedIndices))
+	}
+	if got := m.matches[m.searchedIndices[0]]; !slices.Equal(got, []int{0, 4, 6}) {
+		t.Errorf("expected positions [0 4 6], got %v", got)
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	base := lipgloss.NewStyle()
+	if got := highlightMatches("Firefox", nil, base); got != "Firefox" {
+		t.Errorf("expected unchanged text without matches, got %q", got)
+	}
+
+	got := highlightMatches("Firefox", []int{0, 4}, base)
+	if lipgloss.Width(got) != len("Firefox") {
+		t.Errorf("expected highlighted text to keep its width, got %d", lipgloss.Width(got))
+	}
+	if !strings.Contains(stripANSI(got), "Firefox") {
+		t.Errorf("expected highlighted text to contain Firefox, got %q", got)
+	}
+}
+
+// stripANSI removes SGR escape sequences.
+func stripANSI(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\x1b' {
+			for i < len(s) && s[i] != 'm' {
+				i++
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func BenchmarkUpdateFilter(b *testing.B) {
+	words := []string{"Visual", "Studio", "Code", "Terminal", "Fire", "fox", "Notes", "Preview", "Music", "Photo"}
+	settings := make([]core.Setting, 5000)
+	for i := range settings {
+		name := fmt.Sprintf("%s %s %d", words[i%len(words)], words[(i/len(words))%len(words)], i)
+		settings[i] = core.Setting{
+			Id:       i + 1,
+			Name:     name,
+			BundleId: "com.example." + strings.ToLower(strings.ReplaceAll(name, " ", ".")),
+			HotKey:   sql.NullString{String: fmt.Sprintf("command+%d", i%10), Valid: i%3 == 0},
+			Mode:     "default",
+			Enabled:  true,
+		}
+	}
+	m := NewModel(nil, settings, "0.1.0")
+
+	for _, query := range []string{"vsc", "terminal", "com.fox", "cmd 4"} {
+		b.Run(query, func(b *testing.B) {
+			m.searchInput.SetValue(query)
+			for b.Loop() {
+				m.updateFilter()
+			}
+		})
+	}
+}
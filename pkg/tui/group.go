@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"slices"
+)
+
+// Group name for apps without any tag
+const untaggedGroup = "untagged"
+
+// tableRow is a line of the table the cursor can rest on: either an app or,
+// when grouping by tag, a group header.
+type tableRow struct {
+	idx   int    // index into settings, -1 for group headers
+	group string // tag the row is listed under when grouped
+	count int    // number of apps in the group, headers only
+}
+
+func (r tableRow) isHeader() bool {
+	return r.idx < 0
+}
+
+// buildRows lays out the filtered settings as table rows. Ungrouped, every
+// filtered app gets one row. Grouped, each tag gets a header followed by its
+// apps unless collapsed; apps with several tags are listed under each.
+func (m *model) buildRows() {
+	m.rows = make([]tableRow, 0, len(m.searchedIndices))
+
+	if !m.grouped {
+		for _, idx := range m.searchedIndices {
+			m.rows = append(m.rows, tableRow{idx: idx})
+		}
+		return
+	}
+
+	members := make(map[string][]int)
+	for _, idx := range m.searchedIndices {
+		tags := m.settings[idx].Tags
+		if len(tags) == 0 {
+			tags = []string{untaggedGroup}
+		}
+		for _, tag := range tags {
+			members[tag] = append(members[tag], idx)
+		}
+	}
+
+	groups := make([]string, 0, len(members))
+	for tag := range members {
+		if tag != untaggedGroup {
+			groups = append(groups, tag)
+		}
+	}
+	slices.Sort(groups)
+	if _, ok := members[untaggedGroup]; ok {
+		groups = append(groups, untaggedGroup)
+	}
+
+	for _, group := range groups {
+		m.rows = append(m.rows, tableRow{idx: -1, group: group, count: len(members[group])})
+		if m.collapsed[group] {
+			continue
+		}
+		for _, idx := range members[group] {
+			m.rows = append(m.rows, tableRow{idx: idx, group: group})
+		}
+	}
+}
+
+// selectedIndex returns the settings index under the cursor. ok is false when
+// the table is empty or the cursor rests on a group header.
+func (m model) selectedIndex() (int, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return 0, false
+	}
+	row := m.rows[m.cursor]
+	if row.isHeader() {
+		return 0, false
+	}
+	return row.idx, true
+}
+
+// onHeader reports whether the cursor rests on a group header.
+func (m model) onHeader() bool {
+	return m.cursor >= 0 && m.cursor < len(m.rows) && m.rows[m.cursor].isHeader()
+}
+
+// toggleGrouping switches between the flat list and the grouped view,
+// keeping the cursor on the same app where possible.
+func (m *model) toggleGrouping() {
+	idx, ok := m.selectedIndex()
+	m.grouped = !m.grouped
+	m.buildRows()
+	m.cursor = 0
+	if ok {
+		m.moveCursorToIndex(idx)
+	}
+}
+
+// toggleCollapse collapses or expands the group under the cursor.
+func (m *model) toggleCollapse() {
+	if !m.onHeader() {
+		return
+	}
+	group := m.rows[m.cursor].group
+	m.collapsed[group] = !m.collapsed[group]
+	m.buildRows()
+	for i, row := range m.rows {
+		if row.isHeader() && row.group == group {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// moveCursorToIndex places the cursor on the first row showing the app at
+// settings index idx.
+func (m *model) moveCursorToIndex(idx int) {
+	for i, row := range m.rows {
+		if row.idx == idx {
+			m.cursor = i
+			return
+		}
+	}
+}
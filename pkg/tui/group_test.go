@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+)
+
+// taggedModel returns a model whose settings carry tags:
+// Finder, Firefox(web), Notes(work), Safari(web, work), Terminal(dev)
+func taggedModel(t *testing.T) model {
+	t.Helper()
+	database := setupTestDatabase(t)
+	m := NewModel(nil, testSettings(t, database), "0.1.0")
+	tags := map[string][]string{
+		"Firefox":  {"web"},
+		"Notes":    {"work"},
+		"Safari":   {"web", "work"},
+		"Terminal": {"dev"},
+	}
+	for i := range m.settings {
+		m.settings[i].Tags = tags[m.settings[i].Name]
+	}
+	m.updateFilter()
+	return m
+}
+
+func rowLabels(m model) []string {
+	labels := []string{}
+	for _, row := range m.rows {
+		if row.isHeader() {
+			labels = append(labels, "#"+row.group)
+		} else {
+			labels = append(labels, m.settings[row.idx].Name)
+		}
+	}
+	return labels
+}
+
+func TestBuildRows_Ungrouped(t *testing.T) {
+	m := taggedModel(t)
+	if len(m.rows) != len(m.searchedIndices) {
+		t.Fatalf("expected %d rows, got %d", len(m.searchedIndices), len(m.rows))
+	}
+	for i, row := range m.rows {
+		if row.isHeader() {
+			t.Errorf("unexpected header at row %d", i)
+		}
+	}
+}
+
+func TestBuildRows_GroupedByTag(t *testing.T) {
+	m := taggedModel(t)
+	m = sendKey(t, m, GROUP_KEY)
+
+	expected := []string{
+		"#dev", "Terminal",
+		"#web", "Firefox", "Safari",
+		"#work", "Notes", "Safari",
+		"#untagged", "Finder",
+	}
+	if got := rowLabels(m); !slices.Equal(got, expected) {
+		t.Errorf("expected rows %v, got %v", expected, got)
+	}
+}
+
+func TestGrouping_KeepsCursorOnApp(t *testing.T) {
+	m := taggedModel(t)
+	for i, row := range m.rows {
+		if m.settings[row.idx].Name == "Notes" {
+			m.cursor = i
+		}
+	}
+
+	m = sendKey(t, m, GROUP_KEY)
+	idx, ok := m.selectedIndex()
+	if !ok || m.settings[idx].Name != "Notes" {
+		t.Errorf("expected cursor to stay on Notes, got row %d", m.cursor)
+	}
+}
+
+func TestGrouping_CollapseGroup(t *testing.T) {
+	m := taggedModel(t)
+	m = sendKey(t, m, GROUP_KEY)
+	m.cursor = 0 // the "dev" header
+
+	if !m.onHeader() {
+		t.Fatal("expected cursor on a group header")
+	}
+	m = sendKey(t, m, "enter")
+
+	if !m.collapsed["dev"] {
+		t.Error("expected dev group to be collapsed")
+	}
+	if m.state != stateBrowse {
+		t.Errorf("expected to stay in browse mode, got %d", m.state)
+	}
+	if got := rowLabels(m); slices.Contains(got, "Terminal") {
+		t.Errorf("expected Terminal to be hidden, got %v", got)
+	}
+
+	m = sendKey(t, m, "enter")
+	if got := rowLabels(m); !slices.Contains(got, "Terminal") {
+		t.Errorf("expected Terminal to be visible again, got %v", got)
+	}
+}
+
+func TestGrouping_HeaderNotEditable(t *testing.T) {
+	m := taggedModel(t)
+	m = sendKey(t, m, GROUP_KEY)
+	m.cursor = 0
+
+	if _, ok := m.selectedIndex(); ok {
+		t.Error("expected no selected app on a group header")
+	}
+}
+
+func TestUpdateFilter_TagPrefix(t *testing.T) {
+	m := taggedModel(t)
+	m.searchInput.SetValue("tag:we")
+	m.updateFilter()
+
+	names := []string{}
+	for _, idx := range m.searchedIndices {
+		names = append(names, m.settings[idx].Name)
+	}
+	if !slices.Equal(names, []string{"Firefox", "Safari"}) {
+		t.Errorf("expected [Firefox Safari], got %v", names)
+	}
+}
+
+func TestUpdateFilter_TagAndText(t *testing.T) {
+	m := taggedModel(t)
+	m.searchInput.SetValue("saf tag:work")
+	m.updateFilter()
+
+	if len(m.searchedIndices) != 1 || m.settings[m.searchedIndices[0]].Name != "Safari" {
+		t.Errorf("expected only Safari to match")
+	}
+}
@@ -0,0 +1,275 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"strings"
+
+	"github.com/Builtbyjb/yay/pkg/lib"
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Terminal graphics protocols used to draw app icons
+type graphicsProtocol int
+
+const (
+	graphicsNone graphicsProtocol = iota
+	graphicsKitty
+	graphicsITerm2
+	graphicsSixel
+)
+
+// Maximum payload size of a single kitty graphics escape
+const kittyChunkSize = 4096
+
+// Pixel size icons are drawn at with sixel, roughly two cells wide and one
+// cell tall on common terminal fonts
+const sixelIconSize = 16
+
+// iconsLoadedMsg carries rendered icon cells keyed by setting id, and the
+// escapes that upload the images to the terminal once, when the protocol
+// keeps them (kitty)
+type iconsLoadedMsg struct {
+	cells  map[int]string
+	upload string
+}
+
+// detectGraphics guesses the graphics protocol supported by the terminal from
+// the environment. YAY_GRAPHICS (kitty, iterm2, sixel or none) overrides it.
+func detectGraphics() graphicsProtocol {
+	switch strings.ToLower(os.Getenv("YAY_GRAPHICS")) {
+	case "kitty":
+		return graphicsKitty
+	case "iterm2":
+		return graphicsITerm2
+	case "sixel":
+		return graphicsSixel
+	case "none", "off":
+		return graphicsNone
+	}
+
+	// Multiplexers need passthrough escapes, don't try to draw through them
+	if os.Getenv("TMUX") != "" || strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		return graphicsNone
+	}
+
+	term := os.Getenv("TERM")
+	if os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty" {
+		return graphicsKitty
+	}
+
+	switch os.Getenv("TERM_PROGRAM") {
+	case "ghostty":
+		return graphicsKitty
+	case "iTerm.app", "WezTerm":
+		return graphicsITerm2
+	case "mlterm":
+		return graphicsSixel
+	}
+
+	if strings.Contains(term, "sixel") || term == "foot" || strings.HasPrefix(term, "foot-") {
+		return graphicsSixel
+	}
+
+	return graphicsNone
+}
+
+// loadIcons converts every app icon in the background and renders it with the
+// given protocol. Apps without a usable icon are left out of the result.
+func loadIcons(settings []core.Setting, proto graphicsProtocol) tea.Cmd {
+	if proto == graphicsNone {
+		return nil
+	}
+
+	type iconSource struct {
+		id   int
+		path string
+	}
+	sources := make([]iconSource, 0, len(settings))
+	for _, s := range settings {
+		if s.IconPath != "" {
+			sources = append(sources, iconSource{s.Id, s.IconPath})
+		}
+	}
+
+	return func() tea.Msg {
+		icons := iconsLoadedMsg{cells: make(map[int]string, len(sources))}
+		var upload strings.Builder
+		for _, src := range sources {
+			pngPath, err := lib.CachedIcon(src.path)
+			if err != nil {
+				continue
+			}
+			data, err := os.ReadFile(pngPath)
+			if err != nil {
+				continue
+			}
+			cell, transmit, err := renderIcon(proto, src.id, data)
+			if err != nil {
+				continue
+			}
+			icons.cells[src.id] = cell
+			upload.WriteString(transmit)
+		}
+		icons.upload = upload.String()
+		return icons
+	}
+}
+
+// renderIcon returns a table cell drawing the PNG image over two terminal
+// columns. The escape sequences leave the cursor in place and the trailing
+// spaces reserve the columns, so the cell is two columns wide regardless of
+// protocol.
+//
+// Kitty keeps uploaded images, so the image is transmitted once under id by
+// the returned upload escapes and the cell only places it. Other protocols
+// draw the whole image from the cell and return no upload.
+func renderIcon(proto graphicsProtocol, id int, pngData []byte) (cell string, upload string, err error) {
+	var b strings.Builder
+
+	switch proto {
+	case graphicsKitty:
+		var u strings.Builder
+		payload := base64.StdEncoding.EncodeToString(pngData)
+		for i := 0; i < len(payload); i += kittyChunkSize {
+			end := min(i+kittyChunkSize, len(payload))
+			more := 0
+			if end < len(payload) {
+				more = 1
+			}
+			if i == 0 {
+				// q=2 silences terminal responses, which would otherwise
+				// arrive on stdin as key presses
+				fmt.Fprintf(&u, "\x1b_Ga=t,f=100,t=d,i=%d,q=2,m=%d;%s\x1b\\", id, more, payload[i:end])
+			} else {
+				fmt.Fprintf(&u, "\x1b_Gm=%d;%s\x1b\\", more, payload[i:end])
+			}
+		}
+		upload = u.String()
+		fmt.Fprintf(&b, "\x1b_Ga=p,i=%d,c=2,r=1,C=1,q=2\x1b\\", id)
+
+	case graphicsITerm2:
+		fmt.Fprintf(&b, "\x1b]1337;File=inline=1;size=%d;width=2;height=1;preserveAspectRatio=1;doNotMoveCursor=1:%s\a",
+			len(pngData), base64.StdEncoding.EncodeToString(pngData))
+
+	case graphicsSixel:
+		img, err := png.Decode(bytes.NewReader(pngData))
+		if err != nil {
+			return "", "", err
+		}
+		// Save and restore the cursor around the image since terminals
+		// disagree on where sixel output leaves it
+		b.WriteString("\x1b7")
+		b.WriteString(encodeSixel(img, sixelIconSize))
+		b.WriteString("\x1b8")
+
+	default:
+		return "", "", fmt.Errorf("no graphics protocol")
+	}
+
+	b.WriteString("  ")
+	return b.String(), upload, nil
+}
+
+// encodeSixel renders img as a size x size sixel image using the 6x6x6 color
+// cube. Mostly transparent pixels are left unpainted.
+func encodeSixel(img image.Image, size int) string {
+	bounds := img.Bounds()
+	pixel := func(x, y int) int {
+		sx := bounds.Min.X + x*bounds.Dx()/size
+		sy := bounds.Min.Y + y*bounds.Dy()/size
+		r, g, b, a := img.At(sx, sy).RGBA()
+		if a < 0x8000 {
+			return -1
+		}
+		// Undo alpha premultiplication before quantizing
+		r, g, b = r*0xffff/a, g*0xffff/a, b*0xffff/a
+		return int(r*5/0xffff)*36 + int(g*5/0xffff)*6 + int(b*5/0xffff)
+	}
+
+	var out strings.Builder
+	out.WriteString("\x1bP0;1;0q")
+	fmt.Fprintf(&out, "\"1;1;%d;%d", size, size)
+
+	// Palette, only for colors actually used
+	used := make(map[int]bool)
+	for y := range size {
+		for x := range size {
+			if c := pixel(x, y); c >= 0 {
+				used[c] = true
+			}
+		}
+	}
+	for c := range 216 {
+		if used[c] {
+			fmt.Fprintf(&out, "#%d;2;%d;%d;%d", c, c/36*20, c/6%6*20, c%6*20)
+		}
+	}
+
+	for band := 0; band < size; band += 6 {
+		first := true
+		for c := range 216 {
+			if !used[c] {
+				continue
+			}
+
+			row := make([]byte, size)
+			painted := false
+			for x := range size {
+				var bits byte
+				for dy := 0; dy < 6 && band+dy < size; dy++ {
+					if pixel(x, band+dy) == c {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = '?' + bits
+				painted = painted || bits != 0
+			}
+			if !painted {
+				continue
+			}
+
+			if !first {
+				out.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(&out, "#%d", c)
+			writeSixelRun(&out, row)
+		}
+		out.WriteByte('-')
+	}
+
+	out.WriteString("\x1b\\")
+	return out.String()
+}
+
+// writeSixelRun writes sixel characters using the "!<count><char>" repeat
+// introducer for runs longer than three.
+func writeSixelRun(out *strings.Builder, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(out, "!%d%c", n, row[i])
+		} else {
+			out.Write(row[i:j])
+		}
+		i = j
+	}
+}
+
+// iconCell returns the icon column content for a setting, falling back to a
+// glyph when no image is available.
+func (m model) iconCell(s core.Setting) string {
+	if cell, ok := m.icons[s.Id]; ok {
+		return cell
+	}
+	return FALLBACK_ICON + " "
+}
@@ -0,0 +1,178 @@
+package tui
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	for y := range 32 {
+		for x := range 32 {
+			img.SetNRGBA(x, y, color.NRGBA{255, 0, 0, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func clearGraphicsEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{"YAY_GRAPHICS", "TMUX", "TERM", "TERM_PROGRAM", "KITTY_WINDOW_ID"} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestDetectGraphics(t *testing.T) {
+	tests := []struct {
+		env      map[string]string
+		expected graphicsProtocol
+	}{
+		{map[string]string{}, graphicsNone},
+		{map[string]string{"TERM": "xterm-kitty"}, graphicsKitty},
+		{map[string]string{"KITTY_WINDOW_ID": "1"}, graphicsKitty},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, graphicsITerm2},
+		{map[string]string{"TERM": "foot"}, graphicsSixel},
+		{map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux"}, graphicsNone},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app", "YAY_GRAPHICS": "sixel"}, graphicsSixel},
+		{map[string]string{"TERM": "xterm-kitty", "YAY_GRAPHICS": "none"}, graphicsNone},
+	}
+	for _, tc := range tests {
+		clearGraphicsEnv(t)
+		for k, v := range tc.env {
+			t.Setenv(k, v)
+		}
+		if got := detectGraphics(); got != tc.expected {
+			t.Errorf("detectGraphics() with %v = %d, want %d", tc.env, got, tc.expected)
+		}
+	}
+}
+
+func TestRenderIcon_CellIsTwoColumnsWide(t *testing.T) {
+	data := testPNG(t)
+	for _, proto := range []graphicsProtocol{graphicsKitty, graphicsITerm2, graphicsSixel} {
+		cell, _, err := renderIcon(proto, 1, data)
+		if err != nil {
+			t.Fatalf("renderIcon(%d) returned error: %v", proto, err)
+		}
+		if w := lipgloss.Width(cell); w != 2 {
+			t.Errorf("renderIcon(%d) width = %d, want 2", proto, w)
+		}
+	}
+}
+
+func TestRenderIcon_Kitty(t *testing.T) {
+	cell, upload, err := renderIcon(graphicsKitty, 7, testPNG(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(upload, "\x1b_Ga=t,f=100,t=d,i=7,q=2") {
+		t.Errorf("expected kitty transmit escape, got %q", upload[:min(len(upload), 30)])
+	}
+	if cell != "\x1b_Ga=p,i=7,c=2,r=1,C=1,q=2\x1b\\  " {
+		t.Errorf("expected the cell to only place the image, got %q", cell)
+	}
+}
+
+func TestRenderIcon_ITerm2(t *testing.T) {
+	cell, upload, err := renderIcon(graphicsITerm2, 1, testPNG(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(cell, "\x1b]1337;File=inline=1") {
+		t.Errorf("expected iTerm2 inline image escape, got %q", cell[:min(len(cell), 20)])
+	}
+	if upload != "" {
+		t.Error("expected no separate upload for iTerm2")
+	}
+}
+
+func TestRenderIcon_Sixel(t *testing.T) {
+	cell, _, err := renderIcon(graphicsSixel, 1, testPNG(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(cell, "\x1bP0;1;0q") {
+		t.Error("expected sixel DCS introducer")
+	}
+	// Pure red maps to the last red entry of the color cube
+	if !strings.Contains(cell, "#180;2;100;0;0") {
+		t.Error("expected red palette entry")
+	}
+}
+
+func TestRenderIcon_NoProtocol(t *testing.T) {
+	if _, _, err := renderIcon(graphicsNone, 1, testPNG(t)); err == nil {
+		t.Error("expected error without a graphics protocol")
+	}
+}
+
+func TestLoadIcons_NilWithoutGraphics(t *testing.T) {
+	database := setupTestDatabase(t)
+	if cmd := loadIcons(testSettings(t, database), graphicsNone); cmd != nil {
+		t.Error("expected no icon loading without graphics support")
+	}
+}
+
+func TestIconCell_FallbackGlyph(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(nil, testSettings(t, database), "0.1.0")
+
+	s := m.settings[0]
+	if cell := m.iconCell(s); cell != FALLBACK_ICON+" " {
+		t.Errorf("expected fallback glyph, got %q", cell)
+	}
+
+	m.icons[s.Id] = "XX"
+	if cell := m.iconCell(s); cell != "XX" {
+		t.Errorf("expected rendered icon, got %q", cell)
+	}
+}
+
+func TestUpdate_IconsLoaded(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(nil, testSettings(t, database), "0.1.0")
+
+	result, _ := m.Update(iconsLoadedMsg{cells: map[int]string{m.settings[0].Id: "XX"}})
+	m = result.(model)
+
+	if m.icons[m.settings[0].Id] != "XX" {
+		t.Error("expected loaded icons to be stored on the model")
+	}
+}
+
+func TestUpdate_IconsUploadedBeforeShown(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(nil, testSettings(t, database), "0.1.0")
+	var terminal bytes.Buffer
+	m.terminal = &terminal
+
+	id := m.settings[0].Id
+	result, cmd := m.Update(iconsLoadedMsg{cells: map[int]string{id: "XX"}, upload: "UP"})
+	m = result.(model)
+	if _, ok := m.icons[id]; ok {
+		t.Error("expected the cells to wait for the upload")
+	}
+	if cmd == nil {
+		t.Fatal("expected a command uploading the images")
+	}
+
+	result, _ = m.Update(cmd())
+	m = result.(model)
+	if terminal.String() != "UP" {
+		t.Errorf("expected the images to be uploaded once, got %q", terminal.String())
+	}
+	if m.icons[id] != "XX" {
+		t.Error("expected the cells to be shown after the upload")
+	}
+}
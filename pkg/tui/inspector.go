@@ -0,0 +1,161 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Preference key storing whether the inspector is shown
+const prefInspector = "inspector"
+
+// showingInspector reports whether the terminal is wide enough for the
+// inspector and the user hasn't hidden it.
+func (m model) showingInspector() bool {
+	return m.inspector && m.width >= INSPECTOR_MIN_WIDTH && m.state != stateLog && m.state != stateColumns
+}
+
+// inspectorWidth returns the outer width of the inspector pane, a third of
+// the terminal within the configured bounds.
+func (m model) inspectorWidth() int {
+	return min(max(m.width/3, INSPECTOR_MIN_PANE_WIDTH), INSPECTOR_MAX_PANE_WIDTH)
+}
+
+// tableWidth returns the width left for the table.
+func (m model) tableWidth() int {
+	if m.showingInspector() {
+		return m.width - m.inspectorWidth()
+	}
+	return m.width
+}
+
+func (m *model) toggleInspector() {
+	m.inspector = !m.inspector
+	if m.db == nil {
+		return
+	}
+	if err := m.db.SetPreference(prefInspector, strconv.FormatBool(m.inspector)); err != nil {
+		m.notifyError(err)
+	}
+}
+
+func (m *model) loadInspectorPreference() {
+	if m.db == nil {
+		return
+	}
+	value, ok, err := m.db.GetPreference(prefInspector)
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+	if ok {
+		m.inspector = value != "false"
+	}
+}
+
+// historyKey identifies the app and journal state the inspector history was
+// loaded for. Any edit, undo or redo of the app's hotkey changes the latest
+// entry or the number of entries, so the history only needs reloading when
+// the key changes.
+type historyKey struct {
+	id     int
+	latest int64
+	count  int
+}
+
+// loadHistory reads the hotkey history of the app under the cursor for the
+// inspector, when the cursor moved or its hotkey was edited since the last
+// load.
+func (m *model) loadHistory() {
+	var want historyKey
+	idx, ok := m.selectedIndex()
+	if m.db != nil && m.showingInspector() && ok {
+		want.id = m.settings[idx].Id
+		latest, count, err := m.db.HistoryRevision(want.id, core.FieldHotkey)
+		if err != nil {
+			m.notifyError(err)
+			return
+		}
+		want.latest, want.count = latest, count
+	}
+	if want == m.historyFor {
+		return
+	}
+	m.historyFor = want
+	if want.id == 0 {
+		m.history = nil
+		return
+	}
+
+	history, err := m.db.History(want.id, core.FieldHotkey, INSPECTOR_HISTORY_LIMIT)
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+	m.history = history
+}
+
+// InspectorView renders the details of the app under the cursor.
+func (m model) InspectorView() string {
+	width := m.inspectorWidth()
+	// Border and padding take four columns
+	inner := width - 4
+
+	idx, ok := m.selectedIndex()
+	if !ok {
+		return PanelStyle.Width(width-2).Padding(0, 1).Render(DimStyle.Render("No application selected."))
+	}
+	s := m.settings[idx]
+
+	lines := []string{InspectorTitleStyle.Width(inner).Render(s.Title()), ""}
+	field := func(label, value string) {
+		if value == "" {
+			value = "---"
+		}
+		lines = append(lines,
+			InspectorLabelStyle.Render(label),
+			NormalRowStyle.Width(inner).Render(value),
+		)
+	}
+
+	if s.Name != s.Title() {
+		field("Bundle name", s.Name)
+	}
+	field("Path", s.Path)
+	field("Binary", s.BinName)
+	field("Bundle ID", s.BundleId)
+	field("Version", s.Version)
+	field("Tags", strings.Join(s.Tags, ", "))
+	field("Hotkey", s.HotKey.String)
+	if scope, err := core.ParseScope(s.Scope); err == nil && s.Scope != "" {
+		field("Scope", scope.Describe())
+	}
+	field("Mode", s.Mode)
+	field("Enabled", formatBool(s.Enabled))
+
+	lastUsed := ""
+	if !s.Usage.LastUsed.IsZero() {
+		lastUsed = fmt.Sprintf("%s (%s)", s.Usage.LastUsed.Format("2006-01-02 15:04"), formatLastUsed(s.Usage.LastUsed, time.Now()))
+	}
+	field("Launches", strconv.Itoa(s.Usage.LaunchCount))
+	field("Last used", lastUsed)
+
+	lines = append(lines, "", InspectorLabelStyle.Render("Hotkey history"))
+	if len(m.history) == 0 {
+		lines = append(lines, DimStyle.Render("No changes recorded."))
+	}
+	for _, c := range m.history {
+		lines = append(lines, NormalRowStyle.Width(inner).Render(fmt.Sprintf(
+			"%s  %s → %s",
+			c.Time.Local().Format("2006-01-02 15:04"),
+			displayKey(c.Old.String),
+			displayKey(c.New.String),
+		)))
+	}
+
+	return PanelStyle.Width(width-2).Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
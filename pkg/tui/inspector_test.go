@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/Builtbyjb/yay/pkg/lib"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func resize(t *testing.T, m model, width, height int) model {
+	t.Helper()
+	result, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	return result.(model)
+}
+
+func TestInspector_HiddenOnNarrowTerminals(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = resize(t, m, INSPECTOR_MIN_WIDTH-1, 40)
+	if m.showingInspector() {
+		t.Error("expected no inspector below the minimum width")
+	}
+	if m.tableWidth() != m.width {
+		t.Errorf("expected the table to take the full width, got %d", m.tableWidth())
+	}
+
+	m = resize(t, m, INSPECTOR_MIN_WIDTH, 40)
+	if !m.showingInspector() {
+		t.Error("expected the inspector on a wide terminal")
+	}
+}
+
+func TestInspector_WidthFollowsTerminal(t *testing.T) {
+	m := NewModel(nil, nil, "0.1.0")
+
+	m = resize(t, m, 150, 40)
+	if m.inspectorWidth() != INSPECTOR_MIN_PANE_WIDTH+14 {
+		t.Errorf("expected a third of the width, got %d", m.inspectorWidth())
+	}
+	if m.tableWidth()+m.inspectorWidth() != 150 {
+		t.Errorf("expected the panes to fill the width, got %d+%d", m.tableWidth(), m.inspectorWidth())
+	}
+
+	m = resize(t, m, 400, 40)
+	if m.inspectorWidth() != INSPECTOR_MAX_PANE_WIDTH {
+		t.Errorf("expected the maximum width, got %d", m.inspectorWidth())
+	}
+}
+
+func TestInspector_ShowsDetails(t *testing.T) {
+	database := setupTestDatabase(t)
+	settings := testSettings(t, database)
+	settings[0].DisplayName = "Finder With A Very Long Display Name"
+	settings[0].BundleId = "com.apple.finder"
+	settings[0].Version = "14.2"
+	m := NewModel(database, settings, "0.1.0")
+	m = resize(t, m, 160, 60)
+
+	view := m.View()
+	for _, want := range []string{"com.apple.finder", "14.2", "/path/to/finder", "Bundle name", "Very Long Display Name"} {
+		if !containsAny(view, want) {
+			t.Errorf("expected inspector to contain %q", want)
+		}
+	}
+	panes := lipgloss.JoinHorizontal(lipgloss.Top, m.TableView(), m.InspectorView())
+	if lipgloss.Width(panes) > 160 {
+		t.Errorf("expected the panes to fit the terminal, got width %d", lipgloss.Width(panes))
+	}
+}
+
+func TestInspector_FollowsCursorAndHistory(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m = resize(t, m, 160, 60)
+
+	// Clear Firefox's hotkey, then look at it
+	m = sendKey(t, m, "down")
+	m = sendKey(t, m, "enter")
+	m = sendKey(t, m, "backspace")
+	m = sendKey(t, m, "esc")
+
+	if len(m.history) != 1 {
+		t.Fatalf("expected 1 history entry, got %d", len(m.history))
+	}
+	if !containsAny(m.InspectorView(), "ctrl+1 → ---") {
+		t.Error("expected the cleared hotkey in the history")
+	}
+
+	m = sendKey(t, m, "up")
+	if len(m.history) != 0 {
+		t.Errorf("expected Finder to have no history, got %d", len(m.history))
+	}
+	if !containsAny(m.InspectorView(), "No changes recorded.") {
+		t.Error("expected an empty history message")
+	}
+}
+
+func TestInspector_HistoryFollowsJournal(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m = resize(t, m, 160, 60)
+
+	idx, _ := m.selectedIndex()
+	id := m.settings[idx].Id
+	original := m.settings[idx].HotKey
+	globalKey := func() {
+		t.Helper()
+		result, _ := m.Update(lib.CKeyMsg{Event: lib.KeyEvent{Keycode: 0, EventType: lib.EventKeyDown}})
+		m = result.(model)
+	}
+
+	// Clearing and setting the same hotkey again leaves the hotkey as it
+	// was, the history still has both edits
+	if err := database.ClearHotkey(id); err != nil {
+		t.Fatalf("Failed to clear hotkey: %v", err)
+	}
+	if err := database.UpdateHotkey(id, original); err != nil {
+		t.Fatalf("Failed to update hotkey: %v", err)
+	}
+	globalKey()
+	if len(m.history) != 2 {
+		t.Fatalf("expected both edits in the history, got %d entries", len(m.history))
+	}
+
+	if _, err := database.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	globalKey()
+	if len(m.history) != 1 {
+		t.Errorf("expected the undone edit to leave the history, got %d entries", len(m.history))
+	}
+}
+
+func TestInspector_TogglePersisted(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m = resize(t, m, 160, 60)
+
+	m = sendKey(t, m, INSPECTOR_KEY)
+	if m.showingInspector() {
+		t.Error("expected the inspector to be hidden")
+	}
+
+	reloaded := NewModel(database, m.settings, "0.1.0")
+	if reloaded.inspector {
+		t.Error("expected the hidden inspector to be remembered")
+	}
+}
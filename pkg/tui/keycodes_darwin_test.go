@@ -0,0 +1,10 @@
+//go:build darwin
+
+package tui
+
+// Darwin keycodes used to simulate the global event tap
+const (
+	keycodeCommand = 55
+	keycodeN       = 45
+	keycodeF       = 3
+)
@@ -0,0 +1,10 @@
+//go:build linux
+
+package tui
+
+// Evdev keycodes used to simulate the global event tap
+const (
+	keycodeCommand = 125
+	keycodeN       = 49
+	keycodeF       = 33
+)
@@ -0,0 +1,296 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap holds every key binding of the TUI. The defaults come from the key
+// constants in config.go and can be overridden per action from a JSON file.
+type keyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Top    key.Binding
+	Bottom key.Binding
+	Select key.Binding
+	Cancel key.Binding
+	Quit   key.Binding
+
+	// Moving through results while typing a search, keys must not be
+	// printable as they would end up in the search input
+	PrevResult key.Binding
+	NextResult key.Binding
+
+	Search      key.Binding
+	Group       key.Binding
+	Log         key.Binding
+	Undo        key.Binding
+	Redo        key.Binding
+	Mark        key.Binding
+	Visual      key.Binding
+	SelectAll   key.Binding
+	Bulk        key.Binding
+	Sort        key.Binding
+	SortReverse key.Binding
+	Columns     key.Binding
+	Inspector   key.Binding
+	Launch      key.Binding
+	TestBinding key.Binding // press the hotkey of the app under the cursor
+
+	// Row focus
+	NextColumn  key.Binding
+	Edit        key.Binding // record a hotkey, cycle the mode or toggle enabled
+	ClearHotkey key.Binding
+
+	// Hotkey conflicts
+	Swap     key.Binding
+	Reassign key.Binding
+	Confirm  key.Binding
+
+	// Bulk edit menu
+	BulkEnable  key.Binding
+	BulkDisable key.Binding
+	BulkMode    key.Binding
+	BulkClear   key.Binding
+	BulkTag     key.Binding
+}
+
+func newBinding(keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(helpKeys(keys), ""))
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Up:     newBinding("up", "k"),
+		Down:   newBinding("down", "j"),
+		Top:    newBinding("home", "g"),
+		Bottom: newBinding("end", "G"),
+		Select: newBinding("enter"),
+		Cancel: newBinding(CANCEL_KEY),
+		Quit:   newBinding(EXIT_KEY),
+
+		PrevResult: newBinding("up"),
+		NextResult: newBinding("down"),
+
+		Search:      newBinding(SEARCH_KEY),
+		Group:       newBinding(GROUP_KEY),
+		Log:         newBinding(LOG_KEY),
+		Undo:        newBinding(UNDO_KEY),
+		Redo:        newBinding(REDO_KEY),
+		Mark:        newBinding(MARK_KEY),
+		Visual:      newBinding(VISUAL_KEY),
+		SelectAll:   newBinding(SELECT_ALL_KEY),
+		Bulk:        newBinding(BULK_KEY),
+		Sort:        newBinding(SORT_KEY),
+		SortReverse: newBinding(SORT_REVERSE_KEY),
+		Columns:     newBinding(COLUMNS_KEY),
+		Inspector:   newBinding(INSPECTOR_KEY),
+		Launch:      newBinding(LAUNCH_KEY),
+		TestBinding: newBinding(TEST_BINDING_KEY),
+
+		NextColumn:  newBinding(SWITCH_COLUMN_KEY),
+		Edit:        newBinding("enter", " "),
+		ClearHotkey: newBinding("delete", "backspace"),
+
+		Swap:     newBinding(SWAP_KEY),
+		Reassign: newBinding(REASSIGN_KEY),
+		Confirm:  newBinding(CONFIRM_KEY),
+
+		BulkEnable:  newBinding(BULK_ENABLE_KEY),
+		BulkDisable: newBinding(BULK_DISABLE_KEY),
+		BulkMode:    newBinding(BULK_MODE_KEY),
+		BulkClear:   newBinding(BULK_CLEAR_KEY),
+		BulkTag:     newBinding(BULK_TAG_KEY),
+	}
+}
+
+// actions returns the bindings by the name used in the keys file.
+func (k *keyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":           &k.Up,
+		"down":         &k.Down,
+		"top":          &k.Top,
+		"bottom":       &k.Bottom,
+		"select":       &k.Select,
+		"cancel":       &k.Cancel,
+		"quit":         &k.Quit,
+		"prev_result":  &k.PrevResult,
+		"next_result":  &k.NextResult,
+		"search":       &k.Search,
+		"group":        &k.Group,
+		"log":          &k.Log,
+		"undo":         &k.Undo,
+		"redo":         &k.Redo,
+		"mark":         &k.Mark,
+		"visual":       &k.Visual,
+		"select_all":   &k.SelectAll,
+		"bulk":         &k.Bulk,
+		"sort":         &k.Sort,
+		"sort_reverse": &k.SortReverse,
+		"columns":      &k.Columns,
+		"inspector":    &k.Inspector,
+		"launch":       &k.Launch,
+		"test_binding": &k.TestBinding,
+		"next_column":  &k.NextColumn,
+		"edit":         &k.Edit,
+		"clear_hotkey": &k.ClearHotkey,
+		"swap":         &k.Swap,
+		"reassign":     &k.Reassign,
+		"confirm":      &k.Confirm,
+		"bulk_enable":  &k.BulkEnable,
+		"bulk_disable": &k.BulkDisable,
+		"bulk_mode":    &k.BulkMode,
+		"bulk_clear":   &k.BulkClear,
+		"bulk_tag":     &k.BulkTag,
+	}
+}
+
+// keyContext is a set of actions handled in the same state, which must not
+// share keys.
+type keyContext struct {
+	name    string
+	actions []string
+	// Printable keys are typed into an input instead
+	textInput bool
+}
+
+var keyContexts = []keyContext{
+	{name: "browse", actions: []string{
+		"up", "down", "top", "bottom", "select", "cancel", "quit", "search", "group", "log",
+		"undo", "redo", "mark", "visual", "select_all", "bulk", "sort", "sort_reverse", "columns", "inspector",
+		"launch", "test_binding",
+	}},
+	{name: "search", actions: []string{"prev_result", "next_result", "select", "cancel", "quit"}, textInput: true},
+	{name: "row focus", actions: []string{"up", "down", "cancel", "quit", "next_column", "undo", "redo", "edit", "clear_hotkey"}},
+	{name: "conflict", actions: []string{"cancel", "quit", "swap", "reassign", "confirm"}},
+	{name: "log", actions: []string{"up", "down", "cancel", "quit", "log"}},
+	{name: "columns", actions: []string{"up", "down", "edit", "cancel", "quit", "columns"}},
+	{name: "bulk edit", actions: []string{"cancel", "quit", "bulk_enable", "bulk_disable", "bulk_mode", "bulk_clear", "bulk_tag"}},
+	{name: "bulk tag", actions: []string{"select", "cancel", "quit"}, textInput: true},
+}
+
+// validate reports keys bound to two actions of the same state, and printable
+// keys bound while typing.
+func (k *keyMap) validate() error {
+	actions := k.actions()
+	var errs []error
+	for _, c := range keyContexts {
+		owners := map[string]string{}
+		for _, name := range c.actions {
+			for _, kk := range actions[name].Keys() {
+				if c.textInput && printable(kk) {
+					errs = append(errs, fmt.Errorf("%s: %q can't be bound to %s as it is typed into the input", c.name, kk, name))
+				}
+				if other, ok := owners[kk]; ok && other != name {
+					errs = append(errs, fmt.Errorf("%s: %q is bound to both %s and %s", c.name, kk, other, name))
+				}
+				owners[kk] = name
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LoadKeyMap returns the default key bindings with the overrides from the
+// keys file at path applied. The file maps action names to lists of keys:
+//
+//	{"undo": ["u", "ctrl+z"], "search": ["ctrl+f"]}
+//
+// A missing file leaves the defaults. An invalid file or one with conflicting
+// bindings is rejected as a whole and the defaults are returned with the
+// error.
+func LoadKeyMap(path string) (keyMap, error) {
+	k := defaultKeyMap()
+	if path == "" {
+		return k, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return k, nil
+	}
+	if err != nil {
+		return k, err
+	}
+
+	var overrides map[string][]string
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&overrides); err != nil {
+		return defaultKeyMap(), fmt.Errorf("invalid keys file %s: %w", path, err)
+	}
+
+	actions := k.actions()
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		b, ok := actions[name]
+		if !ok {
+			return defaultKeyMap(), fmt.Errorf("invalid keys file %s: unknown action %q", path, name)
+		}
+		keys := overrides[name]
+		if len(keys) == 0 || slices.Contains(keys, "") {
+			return defaultKeyMap(), fmt.Errorf("invalid keys file %s: %s needs at least one key", path, name)
+		}
+		*b = newBinding(keys...)
+	}
+
+	if err := k.validate(); err != nil {
+		return defaultKeyMap(), fmt.Errorf("invalid keys file %s: %w", path, err)
+	}
+	return k, nil
+}
+
+// printable reports whether k is a key that types a character.
+func printable(k string) bool {
+	return utf8.RuneCountInString(k) == 1
+}
+
+// Symbols shown in the help for keys with long names
+var keySymbols = map[string]string{
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+	" ":     "space",
+}
+
+// helpKeys formats keys for the help bar, e.g. "↑/k".
+func helpKeys(keys []string) string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		if symbol, ok := keySymbols[k]; ok {
+			names[i] = symbol
+		} else {
+			names[i] = k
+		}
+	}
+	return strings.Join(names, "/")
+}
+
+// withDesc returns b described as desc in the help bar. Actions are described
+// differently depending on the state, e.g. cancel quits while browsing.
+func withDesc(b key.Binding, desc string) key.Binding {
+	return key.NewBinding(key.WithKeys(b.Keys()...), key.WithHelp(b.Help().Key, desc))
+}
+
+// joinHelp shows several bindings as one help entry, e.g. "↑/k/↓/j Navigate".
+func joinHelp(desc string, bindings ...key.Binding) key.Binding {
+	var keys, help []string
+	for _, b := range bindings {
+		keys = append(keys, b.Keys()...)
+		help = append(help, b.Help().Key)
+	}
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(help, "/"), desc))
+}
@@ -0,0 +1,172 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func writeKeys(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write keys: %v", err)
+	}
+	return path
+}
+
+// ─── Loading ─────────────────────────────────────────────────────────────────
+
+func TestKeyMap_DefaultsValid(t *testing.T) {
+	k := defaultKeyMap()
+	if err := k.validate(); err != nil {
+		t.Errorf("expected the default keys to be valid, got %v", err)
+	}
+}
+
+func TestKeyMap_EveryContextActionExists(t *testing.T) {
+	k := defaultKeyMap()
+	actions := k.actions()
+	for _, c := range keyContexts {
+		for _, name := range c.actions {
+			if _, ok := actions[name]; !ok {
+				t.Errorf("expected %s action %q to exist", c.name, name)
+			}
+		}
+	}
+}
+
+func TestKeyMap_MissingFileKeepsDefaults(t *testing.T) {
+	k, err := LoadKeyMap(filepath.Join(t.TempDir(), "keys.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := k.Undo.Keys(); len(got) != 1 || got[0] != UNDO_KEY {
+		t.Errorf("expected the default undo key, got %v", got)
+	}
+}
+
+func TestKeyMap_Override(t *testing.T) {
+	k, err := LoadKeyMap(writeKeys(t, `{"undo": ["z", "ctrl+z"], "search": ["f"]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(k.Undo.Keys(), ","); got != "z,ctrl+z" {
+		t.Errorf("expected the overridden undo keys, got %s", got)
+	}
+	if got := k.Search.Help().Key; got != "f" {
+		t.Errorf("expected the help to show the new key, got %s", got)
+	}
+	if got := k.Redo.Keys(); got[0] != REDO_KEY {
+		t.Errorf("expected other keys to keep their defaults, got %v", got)
+	}
+}
+
+func TestKeyMap_InvalidFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     string
+	}{
+		{"conflict", `{"undo": ["j"]}`, `browse: "j" is bound to both down and undo`},
+		{"printable while searching", `{"prev_result": ["k"]}`, `"k" can't be bound to prev_result`},
+		{"unknown action", `{"fly": ["f"]}`, `unknown action "fly"`},
+		{"no keys", `{"undo": []}`, "undo needs at least one key"},
+		{"malformed", `{"undo": "u"}`, "invalid keys file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := LoadKeyMap(writeKeys(t, tt.contents))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error mentioning %q, got %v", tt.want, err)
+			}
+			if got := k.Undo.Keys(); got[0] != UNDO_KEY {
+				t.Errorf("expected the defaults to be kept, got %v", got)
+			}
+		})
+	}
+}
+
+func TestKeyMap_SameKeyInDifferentStates(t *testing.T) {
+	// t groups rows while browsing and adds a tag in the bulk menu
+	if _, err := LoadKeyMap(writeKeys(t, `{"bulk_enable": ["t"], "bulk_tag": ["g"]}`)); err != nil {
+		t.Errorf("expected keys to be reusable across states, got %v", err)
+	}
+}
+
+// ─── Handling ────────────────────────────────────────────────────────────────
+
+func TestKeyMap_RemappedKeysHandled(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	k, err := LoadKeyMap(writeKeys(t, `{"down": ["n"], "inspector": ["I"]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.keymap = k
+
+	m = sendKey(t, m, "j")
+	if m.cursor != 0 {
+		t.Errorf("expected the old key to do nothing, got cursor %d", m.cursor)
+	}
+	m = sendKey(t, m, "n")
+	if m.cursor != 1 {
+		t.Errorf("expected the new key to move down, got cursor %d", m.cursor)
+	}
+
+	m = sendKey(t, m, "I")
+	if m.inspector {
+		t.Error("expected the remapped key to hide the inspector")
+	}
+}
+
+// ─── Help ────────────────────────────────────────────────────────────────────
+
+func TestKeyMap_HelpFollowsBindings(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(nil, testSettings(t, database), "0.1.0")
+	k, err := LoadKeyMap(writeKeys(t, `{"search": ["f"]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.keymap = k
+
+	help := m.HelpView()
+	if !containsAny(help, "f Search") {
+		t.Errorf("expected the help to show the remapped key, got %q", help)
+	}
+	if containsAny(help, "/ Search") {
+		t.Error("expected the old key to be gone from the help")
+	}
+}
+
+func TestKeyMap_HelpFitsWidth(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(nil, testSettings(t, database), "0.1.0")
+	m.width = 60
+
+	if w := lipgloss.Width(m.HelpView()); w > 60 {
+		t.Errorf("expected the help to fit the terminal, got width %d", w)
+	}
+}
+
+func TestKeyMap_HelpPerState(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = sendKey(t, m, "enter")
+	m = sendKey(t, m, "tab")
+	if !containsAny(m.HelpView(), "Cycle Mode") {
+		t.Error("expected mode column help")
+	}
+
+	m = sendKey(t, m, "esc")
+	m = sendKey(t, m, COLUMNS_KEY)
+	if !containsAny(m.HelpView(), "Show/Hide Column") {
+		t.Error("expected column picker help")
+	}
+}
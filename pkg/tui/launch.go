@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Builtbyjb/yay/pkg/lib"
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// launchedMsg reports an app opened from the TUI or a tested hotkey
+type launchedMsg struct {
+	name   string // app opened directly
+	hotkey string // hotkey tested, empty when opening directly
+	desc   string // what the tested hotkey did
+	err    error
+}
+
+// launchSelected opens the app under the cursor the way the listener does,
+// whether or not it has an enabled hotkey.
+func (m *model) launchSelected() tea.Cmd {
+	idx, ok := m.selectedIndex()
+	if !ok {
+		return nil
+	}
+	if m.launch == nil {
+		m.notify(severityWarning, "Launching apps isn't available")
+		return nil
+	}
+
+	s := m.settings[idx]
+	launch := m.launch
+	return func() tea.Msg {
+		return launchedMsg{name: s.Title(), err: launch(s)}
+	}
+}
+
+// testBinding presses the hotkey of the app under the cursor, so it goes
+// through the same lookup as a key press picked up by the listener.
+func (m *model) testBinding() tea.Cmd {
+	idx, ok := m.selectedIndex()
+	if !ok {
+		return nil
+	}
+	s := m.settings[idx]
+	if !s.HotKey.Valid || s.HotKey.String == "" {
+		m.notify(severityWarning, fmt.Sprintf("%s has no hotkey to test", s.Title()))
+		return nil
+	}
+	if !s.Enabled {
+		m.notify(severityWarning, fmt.Sprintf("%s is disabled, %s does nothing", s.Title(), s.HotKey.String))
+		return nil
+	}
+	if m.trigger == nil {
+		m.notify(severityWarning, "Testing hotkeys isn't available")
+		return nil
+	}
+
+	// A scoped binding is tested as if one of its apps was frontmost
+	var bundleId string
+	if scope, err := core.ParseScope(s.Scope); err == nil && scope.Specific() {
+		bundleId = scope.Only[0]
+	}
+
+	hotkey := s.HotKey.String
+	trigger := m.trigger
+	return func() tea.Msg {
+		desc, err := trigger(hotkey, bundleId)
+		return launchedMsg{hotkey: hotkey, desc: desc, err: err}
+	}
+}
+
+func (m *model) handleLaunched(msg launchedMsg) {
+	var suspended *lib.SuspendedError
+	switch {
+	case msg.hotkey == "" && msg.err != nil:
+		m.notifyError(fmt.Errorf("opening %s: %w", msg.name, msg.err))
+		return
+	case msg.hotkey == "":
+		m.notify(severityInfo, "Opened "+msg.name)
+	case errors.Is(msg.err, lib.ErrUnboundHotkey):
+		m.notify(severityWarning, fmt.Sprintf("Nothing is bound to %s", msg.hotkey))
+		return
+	case errors.As(msg.err, &suspended):
+		m.notify(severityWarning, fmt.Sprintf("%s passes through, %s", msg.hotkey, suspended.Reason))
+		return
+	case msg.err != nil:
+		m.notifyError(fmt.Errorf("testing %s: %w", msg.hotkey, msg.err))
+		return
+	default:
+		m.notify(severityInfo, fmt.Sprintf("%s: %s", msg.hotkey, msg.desc))
+	}
+
+	m.reloadUsage()
+}
+
+// reloadUsage reads the launch counts after an app was opened.
+func (m *model) reloadUsage() {
+	if m.db == nil {
+		return
+	}
+	usage, err := m.db.GetUsage()
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+	for i := range m.settings {
+		m.settings[i].Usage = usage[m.settings[i].Id]
+	}
+	m.updateFilter()
+}
@@ -0,0 +1,195 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Builtbyjb/yay/pkg/lib"
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
+// runKey sends key and feeds the message of the command it returns back to
+// the model, as the program would. Notification expiry isn't scheduled so the
+// command doesn't wait for it.
+func runKey(t *testing.T, m model, key string) model {
+	t.Helper()
+	result, cmd := m.update(keyMsg(key))
+	m = result.(model)
+	if cmd == nil {
+		return m
+	}
+	if msg, ok := cmd().(launchedMsg); ok {
+		result, _ = m.Update(msg)
+		m = result.(model)
+	}
+	return m
+}
+
+func lastNotification(m model) string {
+	if len(m.notifications) == 0 {
+		return ""
+	}
+	return m.notifications[len(m.notifications)-1].text
+}
+
+// ─── Launch ──────────────────────────────────────────────────────────────────
+
+func TestLaunch_OpensSelectedApp(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	var launched []string
+	m.launch = func(s core.Setting) error {
+		launched = append(launched, s.Title())
+		return database.RecordLaunch(s.Id, time.Now())
+	}
+
+	// Finder is disabled but can still be opened directly
+	m = runKey(t, m, LAUNCH_KEY)
+	if len(launched) != 1 || launched[0] != "Finder" {
+		t.Fatalf("expected Finder to be launched, got %v", launched)
+	}
+	if got := lastNotification(m); got != "Opened Finder" {
+		t.Errorf("expected a launch notification, got %q", got)
+	}
+	if m.settings[m.rows[0].idx].Usage.LaunchCount != 1 {
+		t.Error("expected the launch count to be reloaded")
+	}
+}
+
+func TestLaunch_Error(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.launch = func(core.Setting) error { return errors.New("osascript failed") }
+
+	m = runKey(t, m, LAUNCH_KEY)
+	if m.errorCount() != 1 {
+		t.Errorf("expected 1 error, got %d", m.errorCount())
+	}
+	if got := lastNotification(m); !containsAny(got, "opening Finder: osascript failed") {
+		t.Errorf("expected the launch error, got %q", got)
+	}
+}
+
+func TestLaunch_Unavailable(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = runKey(t, m, LAUNCH_KEY)
+	if got := lastNotification(m); !containsAny(got, "isn't available") {
+		t.Errorf("expected a warning, got %q", got)
+	}
+}
+
+// ─── Test binding ────────────────────────────────────────────────────────────
+
+func TestLaunch_TestBindingTriggersHotkey(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	var triggered []string
+	m.trigger = func(hotkey, bundleId string) (string, error) {
+		triggered = append(triggered, hotkey)
+		return "open Firefox", nil
+	}
+
+	m = sendKey(t, m, "down") // Firefox
+	m = runKey(t, m, TEST_BINDING_KEY)
+	if len(triggered) != 1 || triggered[0] != "ctrl+1" {
+		t.Fatalf("expected ctrl+1 to be triggered, got %v", triggered)
+	}
+	if got := lastNotification(m); got != "ctrl+1: open Firefox" {
+		t.Errorf("expected what the hotkey did, got %q", got)
+	}
+}
+
+func TestLaunch_TestBindingInScope(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	var frontmost string
+	m.trigger = func(hotkey, bundleId string) (string, error) {
+		frontmost = bundleId
+		return "open Firefox", nil
+	}
+
+	m = sendKey(t, m, "down") // Firefox
+	idx, _ := m.selectedIndex()
+	m.settings[idx].Scope = "only:com.apple.Safari,com.apple.mail"
+	m = runKey(t, m, TEST_BINDING_KEY)
+	if frontmost != "com.apple.Safari" {
+		t.Errorf("expected the hotkey to be tested in an app of its scope, got %q", frontmost)
+	}
+}
+
+func TestLaunch_TestBindingSkipped(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.trigger = func(hotkey, bundleId string) (string, error) {
+		t.Errorf("expected %s not to be triggered", hotkey)
+		return "", nil
+	}
+
+	// Finder is disabled
+	m = runKey(t, m, TEST_BINDING_KEY)
+	if got := lastNotification(m); got != "Finder is disabled, ctrl+3 does nothing" {
+		t.Errorf("expected a disabled warning, got %q", got)
+	}
+
+	// Safari has no hotkey
+	m = sendKey(t, m, "down")
+	m = sendKey(t, m, "down")
+	m = sendKey(t, m, "down")
+	m = runKey(t, m, TEST_BINDING_KEY)
+	if got := lastNotification(m); got != "Safari has no hotkey to test" {
+		t.Errorf("expected a missing hotkey warning, got %q", got)
+	}
+}
+
+func TestLaunch_TestBindingUnbound(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.trigger = func(string, string) (string, error) {
+		return "", fmt.Errorf("wrapped: %w", lib.ErrUnboundHotkey)
+	}
+
+	m = sendKey(t, m, "down")
+	m = runKey(t, m, TEST_BINDING_KEY)
+	if got := lastNotification(m); got != "Nothing is bound to ctrl+1" {
+		t.Errorf("expected an unbound warning, got %q", got)
+	}
+	if m.errorCount() != 0 {
+		t.Errorf("expected no errors, got %d", m.errorCount())
+	}
+}
+
+func TestLaunch_TestBindingSuspended(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.trigger = func(string, string) (string, error) {
+		return "open Firefox", &lib.SuspendedError{Reason: "paused"}
+	}
+
+	m = sendKey(t, m, "down")
+	m = runKey(t, m, TEST_BINDING_KEY)
+	if got := lastNotification(m); got != "ctrl+1 passes through, paused" {
+		t.Errorf("expected a suspended warning, got %q", got)
+	}
+	if m.errorCount() != 0 {
+		t.Errorf("expected no errors, got %d", m.errorCount())
+	}
+}
+
+func TestLaunch_OnlyWhileBrowsing(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.launch = func(s core.Setting) error {
+		t.Errorf("expected %s not to be launched", s.Title())
+		return nil
+	}
+
+	m = sendKey(t, m, SEARCH_KEY)
+	result, _ := m.Update(keyMsg(LAUNCH_KEY))
+	if got := result.(model).searchInput.Value(); got != LAUNCH_KEY {
+		t.Errorf("expected the key to be typed into the search, got %q", got)
+	}
+}
@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Lines of the table above the first row: the top border, the column
+// headers and the line below them
+const tableHeaderLines = 3
+
+// tableCell is a clicked cell of the table
+type tableCell struct {
+	row int // index into m.rows
+	col int // index into visibleColumns
+}
+
+// tableTop returns the screen line of the table's top border.
+func (m model) tableTop() int {
+	return lipgloss.Height(m.HeaderView()) + lipgloss.Height(m.SearchView())
+}
+
+// cellAt maps screen coordinates to the table cell drawn there. Column
+// bounds are read from the rendered top border since the table sizes its
+// columns to the available width.
+func (m model) cellAt(x, y int) (tableCell, bool) {
+	if len(m.rows) == 0 || x >= m.tableWidth() {
+		return tableCell{}, false
+	}
+
+	start, end := m.scrollWindow()
+	row := start + y - m.tableTop() - tableHeaderLines
+	if row < start || row >= end {
+		return tableCell{}, false
+	}
+
+	border, _, _ := strings.Cut(m.TableView(), "\n")
+	col := 0
+	for i, r := range []rune(ansi.Strip(border)) {
+		if i >= x {
+			break
+		}
+		if r == '┬' {
+			col++
+		}
+	}
+	if x == 0 || col >= len(m.visibleColumns()) {
+		return tableCell{}, false
+	}
+
+	return tableCell{row: row, col: col}, true
+}
+
+func (m model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp, tea.MouseButtonWheelDown:
+		delta := 1
+		if msg.Button == tea.MouseButtonWheelUp {
+			delta = -1
+		}
+		switch m.state {
+		case stateBrowse, stateFilter, stateRowFocus:
+			m.moveCursor(delta)
+		case stateLog:
+			m.logOffset = min(max(m.logOffset-delta, 0), max(len(m.notifications)-1, 0))
+		}
+		return m, nil
+
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		switch m.state {
+		case stateBrowse, stateFilter, stateRowFocus:
+		default:
+			return m, nil
+		}
+
+		cell, ok := m.cellAt(msg.X, msg.Y)
+		if !ok {
+			return m, nil
+		}
+		m.clickCell(cell, time.Now())
+	}
+
+	return m, nil
+}
+
+// clickCell moves the cursor to the clicked row. Clicking an editable cell
+// focuses its column, double-clicking the hotkey starts recording and
+// double-clicking a group header collapses it.
+func (m *model) clickCell(cell tableCell, at time.Time) {
+	double := cell == m.lastClick && at.Sub(m.lastClickAt) <= DOUBLE_CLICK_INTERVAL
+	m.lastClick = cell
+	m.lastClickAt = at
+	if double {
+		// A third click starts a new double-click
+		m.lastClickAt = time.Time{}
+	}
+
+	m.cursor = cell.row
+	m.recordingHotkey = false
+
+	if m.rows[cell.row].isHeader() {
+		if double {
+			m.toggleCollapse()
+		}
+		if m.state == stateRowFocus {
+			m.state = stateBrowse
+			m.activeCol = colNone
+		}
+		return
+	}
+
+	edit := m.visibleColumns()[cell.col].edit
+	if edit == colNone {
+		return
+	}
+
+	if m.state == stateFilter {
+		m.searchInput.Blur()
+	}
+	m.state = stateRowFocus
+	m.activeCol = edit
+	if double && edit == colKey {
+		m.recordingHotkey = true
+	}
+}
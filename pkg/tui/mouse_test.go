@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// findText returns the screen position of the first occurrence of text on
+// the line containing row, e.g. the hotkey of an app.
+func findText(t *testing.T, m model, row, text string) (int, int) {
+	t.Helper()
+	for y, line := range strings.Split(ansi.Strip(m.View()), "\n") {
+		if !strings.Contains(line, row) {
+			continue
+		}
+		if i := strings.Index(line, text); i >= 0 {
+			return len([]rune(line[:i])), y
+		}
+	}
+	t.Fatalf("%q not found on the row of %q", text, row)
+	return 0, 0
+}
+
+func sendMouse(t *testing.T, m model, msg tea.MouseMsg) model {
+	t.Helper()
+	result, _ := m.Update(msg)
+	return result.(model)
+}
+
+// clickText presses the left button on text in the row containing row.
+func clickText(t *testing.T, m model, row, text string) model {
+	t.Helper()
+	x, y := findText(t, m, row, text)
+	return sendMouse(t, m, tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+}
+
+func mouseModel(t *testing.T) model {
+	t.Helper()
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.inspector = false
+	return resize(t, m, 120, 40)
+}
+
+func TestMouse_ClickNameSelectsRow(t *testing.T) {
+	m := mouseModel(t)
+
+	m = clickText(t, m, "Notes", "Notes")
+	if m.cursor != 2 {
+		t.Errorf("expected cursor on Notes (2), got %d", m.cursor)
+	}
+	if m.state != stateBrowse {
+		t.Errorf("expected to keep browsing, got state %d", m.state)
+	}
+}
+
+func TestMouse_ClickCellFocusesColumn(t *testing.T) {
+	m := mouseModel(t)
+
+	m = clickText(t, m, "Notes", "alt+n")
+	if m.cursor != 2 || m.state != stateRowFocus || m.activeCol != colKey {
+		t.Errorf("expected hotkey of Notes focused, got cursor %d state %d column %d", m.cursor, m.state, m.activeCol)
+	}
+	if m.recordingHotkey {
+		t.Error("expected a single click not to record")
+	}
+
+	m = clickText(t, m, "Safari", "default")
+	if m.cursor != 3 || m.activeCol != colMode {
+		t.Errorf("expected mode of Safari focused, got cursor %d column %d", m.cursor, m.activeCol)
+	}
+}
+
+func TestMouse_DoubleClickHotkeyRecords(t *testing.T) {
+	m := mouseModel(t)
+
+	m = clickText(t, m, "Firefox", "ctrl+1")
+	m = clickText(t, m, "Firefox", "ctrl+1")
+	if !m.recordingHotkey {
+		t.Error("expected a double-click to start recording")
+	}
+}
+
+func TestMouse_SlowClicksDontRecord(t *testing.T) {
+	m := mouseModel(t)
+	cell := tableCell{row: 1, col: 1}
+	at := time.Now()
+
+	m.clickCell(cell, at)
+	m.clickCell(cell, at.Add(DOUBLE_CLICK_INTERVAL+time.Millisecond))
+	if m.recordingHotkey {
+		t.Error("expected clicks far apart not to record")
+	}
+
+	m.clickCell(tableCell{row: 2, col: 1}, at.Add(DOUBLE_CLICK_INTERVAL+2*time.Millisecond))
+	if m.recordingHotkey {
+		t.Error("expected clicks on different cells not to record")
+	}
+}
+
+func TestMouse_ClickFromSearch(t *testing.T) {
+	m := mouseModel(t)
+	m = sendKey(t, m, SEARCH_KEY)
+
+	m = clickText(t, m, "Terminal", "desktop")
+	if m.state != stateRowFocus || m.activeCol != colMode {
+		t.Errorf("expected the mode column focused, got state %d column %d", m.state, m.activeCol)
+	}
+	if m.searchInput.Focused() {
+		t.Error("expected the search input to lose focus")
+	}
+}
+
+func TestMouse_ClickOutsideRows(t *testing.T) {
+	m := mouseModel(t)
+	m = sendKey(t, m, "down")
+
+	// Column headers and the search line
+	m = clickText(t, m, "Application", "HotKey")
+	m = clickText(t, m, "Search", "Search")
+	if m.cursor != 1 || m.state != stateBrowse {
+		t.Errorf("expected clicks outside rows to be ignored, got cursor %d state %d", m.cursor, m.state)
+	}
+}
+
+func TestMouse_ScrolledTable(t *testing.T) {
+	m := mouseModel(t)
+	m = resize(t, m, 120, 20) // three rows
+	for range 4 {
+		m = sendKey(t, m, "down")
+	}
+
+	m = clickText(t, m, "Safari", "Safari")
+	if m.cursor != 3 {
+		t.Errorf("expected cursor on Safari (3), got %d", m.cursor)
+	}
+}
+
+func TestMouse_Wheel(t *testing.T) {
+	m := mouseModel(t)
+
+	m = sendMouse(t, m, tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	m = sendMouse(t, m, tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	if m.cursor != 2 {
+		t.Errorf("expected cursor 2, got %d", m.cursor)
+	}
+
+	m = sendMouse(t, m, tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelUp})
+	if m.cursor != 1 {
+		t.Errorf("expected cursor 1, got %d", m.cursor)
+	}
+}
+
+func TestMouse_GroupHeader(t *testing.T) {
+	m := mouseModel(t)
+	if err := m.db.AddTag(m.settings[0].Id, "files"); err != nil {
+		t.Fatalf("failed to add tag: %v", err)
+	}
+	m.settings[0].Tags = []string{"files"}
+	m = sendKey(t, m, GROUP_KEY)
+
+	m = clickText(t, m, "files", "files")
+	m = clickText(t, m, "files", "files")
+	if !m.collapsed["files"] {
+		t.Error("expected a double-click to collapse the group")
+	}
+}
@@ -0,0 +1,216 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Notification severities, in increasing order of importance
+type severity int
+
+const (
+	severityInfo severity = iota
+	severityWarning
+	severityError
+)
+
+func (s severity) String() string {
+	switch s {
+	case severityWarning:
+		return "WARN"
+	case severityError:
+		return "ERROR"
+	}
+	return "INFO"
+}
+
+func (s severity) style() lipgloss.Style {
+	switch s {
+	case severityWarning:
+		return WarningStyle
+	case severityError:
+		return ErrorStyle
+	}
+	return InfoStyle
+}
+
+// notification is a message shown as a toast until it expires and kept in
+// the log panel afterwards
+type notification struct {
+	id       int
+	severity severity
+	text     string
+	time     time.Time
+	expired  bool
+	ticking  bool // an expiry tick has been scheduled
+}
+
+// notificationExpiredMsg hides the toast of the notification with the id
+type notificationExpiredMsg int
+
+// notify records a notification. Warnings and errors are also written to the
+// log file, if there is one.
+func (m *model) notify(sev severity, text string) {
+	m.lastNotificationId++
+	n := notification{
+		id:       m.lastNotificationId,
+		severity: sev,
+		text:     text,
+		time:     time.Now(),
+	}
+	m.notifications = append(m.notifications, n)
+
+	if len(m.notifications) > MAX_NOTIFICATIONS {
+		m.notifications = m.notifications[len(m.notifications)-MAX_NOTIFICATIONS:]
+	}
+
+	if sev >= severityWarning && m.logWriter != nil {
+		// The log is best effort, a failing write must not produce more errors
+		fmt.Fprintf(m.logWriter, "%s %s %s\n", n.time.Format(time.RFC3339), sev, text)
+	}
+}
+
+func (m *model) notifyError(err error) {
+	m.notify(severityError, err.Error())
+}
+
+// scheduleExpiry returns a tick for every notification shown since the last
+// update, hiding it once NOTIFICATION_TIMEOUT has passed. Errors stay longer.
+func (m *model) scheduleExpiry() tea.Cmd {
+	var cmds []tea.Cmd
+	for i := range m.notifications {
+		n := &m.notifications[i]
+		if n.ticking || n.expired {
+			continue
+		}
+		n.ticking = true
+
+		timeout := NOTIFICATION_TIMEOUT
+		if n.severity == severityError {
+			timeout = ERROR_NOTIFICATION_TIMEOUT
+		}
+		id := n.id
+		cmds = append(cmds, tea.Tick(timeout, func(time.Time) tea.Msg {
+			return notificationExpiredMsg(id)
+		}))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *model) expireNotification(id int) {
+	for i := range m.notifications {
+		if m.notifications[i].id == id {
+			m.notifications[i].expired = true
+			return
+		}
+	}
+}
+
+// activeNotifications returns the notifications still shown as toasts, most
+// recent first.
+func (m model) activeNotifications() []notification {
+	var active []notification
+	for i := len(m.notifications) - 1; i >= 0; i-- {
+		if !m.notifications[i].expired {
+			active = append(active, m.notifications[i])
+		}
+	}
+	return active
+}
+
+// errorCount returns the number of errors recorded this session.
+func (m model) errorCount() int {
+	count := 0
+	for _, n := range m.notifications {
+		if n.severity == severityError {
+			count++
+		}
+	}
+	return count
+}
+
+func (m model) handleLogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keymap.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keymap.Cancel) || key.Matches(msg, m.keymap.Log):
+		m.state = stateBrowse
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Up):
+		m.logOffset = min(m.logOffset+1, max(len(m.notifications)-1, 0))
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Down):
+		m.logOffset = max(m.logOffset-1, 0)
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// openLog shows the log panel scrolled to the most recent notification and
+// hides the toasts it now lists.
+func (m *model) openLog() {
+	m.state = stateLog
+	m.logOffset = 0
+	for i := range m.notifications {
+		m.notifications[i].expired = true
+	}
+}
+
+// NotificationView renders the most recent toast, with a count of the
+// others still shown.
+func (m model) NotificationView() string {
+	active := m.activeNotifications()
+	if len(active) == 0 {
+		return ""
+	}
+
+	n := active[0]
+	content := n.severity.style().Render(fmt.Sprintf("%s: %s", n.severity, n.text))
+	if len(active) > 1 {
+		content = lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			content,
+			DimStyle.Render(fmt.Sprintf("  (+%d more, %s: log)", len(active)-1, m.keymap.Log.Help().Key)),
+		)
+	}
+	return content
+}
+
+// LogView lists recorded notifications, newest at the bottom.
+func (m model) LogView() string {
+	if len(m.notifications) == 0 {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			DimStyle.Render("Nothing logged yet."),
+			"\n",
+		)
+	}
+
+	maxRows := max(m.height-20, 3)
+	end := len(m.notifications) - m.logOffset
+	start := max(end-maxRows, 0)
+
+	lines := make([]string, 0, end-start+1)
+	for _, n := range m.notifications[start:end] {
+		lines = append(lines, lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			DimStyle.Render(n.time.Format("15:04:05")+" "),
+			n.severity.style().Render(fmt.Sprintf("%-5s", n.severity)),
+			NormalRowStyle.Render(" "+n.text),
+		))
+	}
+	lines = append(lines, DimStyle.Render(fmt.Sprintf("showing %d-%d of %d", start+1, end, len(m.notifications))))
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		PanelStyle.Width(max(m.width-2, 0)).Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
@@ -0,0 +1,166 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// failingModel returns a model whose database has been closed, so every
+// write fails.
+func failingModel(t *testing.T) model {
+	t.Helper()
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	database.Close()
+	return m
+}
+
+func TestNotify_DatabaseErrorIsShown(t *testing.T) {
+	m := failingModel(t)
+	m.width = 120
+	m.height = 40
+
+	m = sendKey(t, m, "enter")
+	m = sendKey(t, m, "tab")
+	m = sendKey(t, m, "tab")
+	result, cmd := m.Update(keyMsg(" "))
+	m = result.(model)
+
+	if m.errorCount() != 1 {
+		t.Fatalf("expected 1 error, got %d", m.errorCount())
+	}
+	if cmd == nil {
+		t.Error("expected an expiry tick for the new notification")
+	}
+	if !containsAny(m.View(), "ERROR:") {
+		t.Error("expected the error toast in the view")
+	}
+}
+
+func TestNotify_ExpiryHidesToast(t *testing.T) {
+	m := NewModel(nil, nil, "0.1.0")
+	m.notify(severityInfo, "saved")
+
+	if len(m.activeNotifications()) != 1 {
+		t.Fatalf("expected 1 active notification, got %d", len(m.activeNotifications()))
+	}
+
+	result, _ := m.Update(notificationExpiredMsg(m.notifications[0].id))
+	m = result.(model)
+
+	if len(m.activeNotifications()) != 0 {
+		t.Errorf("expected no active notifications, got %d", len(m.activeNotifications()))
+	}
+	if len(m.notifications) != 1 {
+		t.Errorf("expected expired notification to stay in the log, got %d", len(m.notifications))
+	}
+	if m.NotificationView() != "" {
+		t.Error("expected no toast once expired")
+	}
+}
+
+func TestNotify_ScheduleExpiryOnce(t *testing.T) {
+	m := NewModel(nil, nil, "0.1.0")
+	m.notify(severityWarning, "careful")
+
+	if m.scheduleExpiry() == nil {
+		t.Fatal("expected a tick for the new notification")
+	}
+	if m.scheduleExpiry() != nil {
+		t.Error("expected no second tick for the same notification")
+	}
+}
+
+func TestNotify_MostRecentToastFirst(t *testing.T) {
+	m := NewModel(nil, nil, "0.1.0")
+	m.notify(severityInfo, "first")
+	m.notify(severityError, "second")
+
+	toast := m.NotificationView()
+	if !strings.Contains(toast, "second") || strings.Contains(toast, "first") {
+		t.Errorf("expected only the latest toast, got %q", toast)
+	}
+	if !strings.Contains(toast, "+1 more") {
+		t.Errorf("expected a count of the other toasts, got %q", toast)
+	}
+}
+
+func TestNotify_WritesWarningsAndErrorsToLog(t *testing.T) {
+	var log strings.Builder
+	m := NewModel(nil, nil, "0.1.0")
+	m.logWriter = &log
+
+	m.notify(severityInfo, "swapped")
+	m.notify(severityWarning, "unknown key")
+	m.notifyError(errors.New("database is locked"))
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %q", len(lines), log.String())
+	}
+	if !strings.HasSuffix(lines[0], "WARN unknown key") {
+		t.Errorf("unexpected log line %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "ERROR database is locked") {
+		t.Errorf("unexpected log line %q", lines[1])
+	}
+}
+
+func TestNotify_KeepsBoundedHistory(t *testing.T) {
+	m := NewModel(nil, nil, "0.1.0")
+	for range MAX_NOTIFICATIONS + 5 {
+		m.notify(severityInfo, "x")
+	}
+	if len(m.notifications) != MAX_NOTIFICATIONS {
+		t.Errorf("expected %d notifications, got %d", MAX_NOTIFICATIONS, len(m.notifications))
+	}
+}
+
+// ─── Log panel ───
+
+func TestLogPanel_ToggleAndScroll(t *testing.T) {
+	m := NewModel(nil, nil, "0.1.0")
+	m.width = 120
+	m.height = 40
+	m.notify(severityError, "first failure")
+	m.notify(severityError, "second failure")
+
+	m = sendKey(t, m, LOG_KEY)
+	if m.state != stateLog {
+		t.Fatalf("expected stateLog, got %d", m.state)
+	}
+	if len(m.activeNotifications()) != 0 {
+		t.Error("expected opening the log to dismiss toasts")
+	}
+
+	view := m.View()
+	if !containsAny(view, "first failure") || !containsAny(view, "second failure") {
+		t.Error("expected both entries in the log panel")
+	}
+
+	m = sendKey(t, m, "k")
+	if m.logOffset != 1 {
+		t.Errorf("expected log offset 1, got %d", m.logOffset)
+	}
+	m = sendKey(t, m, "k")
+	if m.logOffset != 1 {
+		t.Errorf("expected log offset to stop at 1, got %d", m.logOffset)
+	}
+	if containsAny(m.View(), "second failure") {
+		t.Error("expected newest entry scrolled out of view")
+	}
+
+	m = sendKey(t, m, LOG_KEY)
+	if m.state != stateBrowse {
+		t.Errorf("expected stateBrowse after closing the log, got %d", m.state)
+	}
+}
+
+func TestLogPanel_Empty(t *testing.T) {
+	m := NewModel(nil, nil, "0.1.0")
+	m = sendKey(t, m, LOG_KEY)
+	if !containsAny(m.View(), "Nothing logged yet.") {
+		t.Error("expected empty log message")
+	}
+}
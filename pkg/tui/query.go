@@ -0,0 +1,193 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
+// predicate filters settings on a single attribute
+type predicate func(core.Setting) bool
+
+// searchQuery is a parsed search box input: free text terms fuzzy matched
+// against the app, plus field predicates that must all hold.
+type searchQuery struct {
+	terms      []string
+	predicates []predicate
+}
+
+// Field predicates understood in the search box, e.g. "mode:desktop"
+var queryFields = map[string]func(value string) (predicate, error){
+	"tag":     tagPredicate,
+	"enabled": enabledPredicate,
+	"mode":    modePredicate,
+	"hotkey":  hotkeyPredicate,
+	"dir":     dirPredicate,
+}
+
+// parseQuery parses a search box input. Words of the form field:value with a
+// known field are predicates, anything else, including URLs such as
+// https://example.com, is free text. Values containing spaces can be
+// quoted, e.g. dir:"/Applications/Python 3.14". Input is matched case
+// insensitively.
+func parseQuery(input string) (searchQuery, error) {
+	var q searchQuery
+
+	tokens, err := tokenizeQuery(strings.ToLower(input))
+	if err != nil {
+		return q, err
+	}
+
+	for _, token := range tokens {
+		field, value, ok := strings.Cut(token, ":")
+		build, known := queryFields[field]
+		if !ok || !known {
+			q.terms = append(q.terms, token)
+			continue
+		}
+		if value == "" {
+			return q, fmt.Errorf("missing value for %s:", field)
+		}
+
+		p, err := build(value)
+		if err != nil {
+			return q, err
+		}
+		q.predicates = append(q.predicates, p)
+	}
+
+	return q, nil
+}
+
+// matches reports whether s satisfies every predicate of the query.
+func (q searchQuery) matches(s core.Setting) bool {
+	for _, p := range q.predicates {
+		if !p(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenizeQuery splits input on whitespace, keeping double quoted sections
+// together and dropping the quotes.
+func tokenizeQuery(input string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+	hasToken := false
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasToken = true
+		case r == ' ' && !inQuotes:
+			if hasToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				hasToken = false
+			}
+		default:
+			current.WriteRune(r)
+			hasToken = true
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if hasToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
+
+// tag:<prefix> matches apps with a tag starting with prefix
+func tagPredicate(value string) (predicate, error) {
+	return func(s core.Setting) bool {
+		return slices.ContainsFunc(s.Tags, func(tag string) bool {
+			return strings.HasPrefix(tag, value)
+		})
+	}, nil
+}
+
+// enabled:true|false
+func enabledPredicate(value string) (predicate, error) {
+	var want bool
+	switch value {
+	case "true", "yes", "on":
+		want = true
+	case "false", "no", "off":
+		want = false
+	default:
+		return nil, fmt.Errorf("enabled: expects true or false, got %q", value)
+	}
+	return func(s core.Setting) bool {
+		return s.Enabled == want
+	}, nil
+}
+
+// mode:<mode> with one of AvailableModes
+func modePredicate(value string) (predicate, error) {
+	if !slices.Contains(AvailableModes, value) {
+		return nil, fmt.Errorf("mode: expects one of %s, got %q", strings.Join(AvailableModes, ", "), value)
+	}
+	return func(s core.Setting) bool {
+		return s.Mode == value
+	}, nil
+}
+
+// hotkey:none, hotkey:any or a hotkey pattern where * matches any run of
+// characters, e.g. hotkey:command+*
+func hotkeyPredicate(value string) (predicate, error) {
+	switch value {
+	case "none":
+		return func(s core.Setting) bool {
+			return !s.HotKey.Valid || s.HotKey.String == ""
+		}, nil
+	case "any":
+		return func(s core.Setting) bool {
+			return s.HotKey.Valid && s.HotKey.String != ""
+		}, nil
+	}
+	return func(s core.Setting) bool {
+		return s.HotKey.Valid && matchWildcard(value, strings.ToLower(s.HotKey.String))
+	}, nil
+}
+
+// dir:<path> matches apps installed under path
+func dirPredicate(value string) (predicate, error) {
+	prefix := strings.TrimSuffix(value, "/")
+	return func(s core.Setting) bool {
+		path := strings.ToLower(s.Path)
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	}, nil
+}
+
+// matchWildcard matches text against pattern, where * matches any sequence
+// of characters, including none.
+func matchWildcard(pattern, text string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == text
+	}
+
+	if !strings.HasPrefix(text, parts[0]) {
+		return false
+	}
+	text = text[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(text, part)
+		if i < 0 {
+			return false
+		}
+		text = text[i+len(part):]
+	}
+
+	return len(text) >= len(last) && strings.HasSuffix(text, last)
+}
@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"database/sql"
+	"slices"
+	"testing"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
+func filteredNames(m model) []string {
+	names := []string{}
+	for _, idx := range m.searchedIndices {
+		names = append(names, m.settings[idx].Name)
+	}
+	return names
+}
+
+func TestParseQuery_FreeTextAndFields(t *testing.T) {
+	q, err := parseQuery("fire mode:desktop fox")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(q.terms, []string{"fire", "fox"}) {
+		t.Errorf("expected terms [fire fox], got %v", q.terms)
+	}
+	if len(q.predicates) != 1 {
+		t.Errorf("expected 1 predicate, got %d", len(q.predicates))
+	}
+}
+
+func TestParseQuery_QuotedValue(t *testing.T) {
+	q, err := parseQuery(`dir:"/Applications/Python 3.14"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := core.Setting{Path: "/Applications/Python 3.14/IDLE.app/Contents/MacOS"}
+	if !q.matches(s) {
+		t.Error("expected quoted dir to match")
+	}
+}
+
+func TestParseQuery_NonFieldColonIsText(t *testing.T) {
+	for _, input := range []string{"com.apple:finder", "https://example.com", "color:red"} {
+		q, err := parseQuery(input)
+		if err != nil {
+			t.Fatalf("parseQuery(%q): unexpected error: %v", input, err)
+		}
+		if len(q.predicates) != 0 || len(q.terms) != 1 {
+			t.Errorf("parseQuery(%q): expected a single free text term, got %v / %d predicates", input, q.terms, len(q.predicates))
+		}
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	for _, input := range []string{
+		"mode:",
+		"mode:fullscreen",
+		"enabled:maybe",
+		`dir:"/Applications`,
+	} {
+		if _, err := parseQuery(input); err == nil {
+			t.Errorf("parseQuery(%q): expected error, got nil", input)
+		}
+	}
+}
+
+func TestMatchWildcard(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		matched bool
+	}{
+		{"command+*", "command+e", true},
+		{"command+*", "command+shift+e", true},
+		{"command+*", "ctrl+e", false},
+		{"*+e", "command+e", true},
+		{"*shift*", "command+shift+e", true},
+		{"command+e", "command+e", true},
+		{"command+e", "command+f", false},
+		{"a*a", "a", false},
+	}
+	for _, tc := range tests {
+		if got := matchWildcard(tc.pattern, tc.text); got != tc.matched {
+			t.Errorf("matchWildcard(%q, %q) = %v, want %v", tc.pattern, tc.text, got, tc.matched)
+		}
+	}
+}
+
+func TestUpdateFilter_FieldPredicates(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(nil, testSettings(t, database), "0.1.0")
+	m.settings[0].HotKey = sql.NullString{String: "command+f", Valid: true}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"enabled:false", []string{"Finder"}},
+		{"mode:desktop", []string{"Finder", "Terminal"}},
+		{"hotkey:none", []string{"Safari", "Terminal"}},
+		{"hotkey:ctrl+*", []string{"Firefox"}},
+		{"hotkey:command+*", []string{"Finder"}},
+		{"dir:/path/to/notes", []string{"Notes"}},
+		{"dir:/path", []string{"Finder", "Firefox", "Notes", "Safari", "Terminal"}},
+		{"mode:default enabled:true fire", []string{"Firefox"}},
+	}
+	for _, tc := range tests {
+		m.searchInput.SetValue(tc.query)
+		m.updateFilter()
+		if m.filterErr != "" {
+			t.Errorf("%q: unexpected error %q", tc.query, m.filterErr)
+		}
+		if got := filteredNames(m); !slices.Equal(got, tc.expected) {
+			t.Errorf("%q: expected %v, got %v", tc.query, tc.expected, got)
+		}
+	}
+}
+
+func TestUpdateFilter_ParseErrorKeepsResults(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(nil, testSettings(t, database), "0.1.0")
+	m.searchInput.SetValue("mode:desktop")
+	m.updateFilter()
+	before := filteredNames(m)
+
+	m.searchInput.SetValue("mode:fullscreen")
+	m.updateFilter()
+
+	if m.filterErr == "" {
+		t.Fatal("expected a parse error")
+	}
+	if got := filteredNames(m); !slices.Equal(got, before) {
+		t.Errorf("expected previous results %v to be kept, got %v", before, got)
+	}
+
+	m.width = 120
+	m.height = 40
+	if !containsAny(m.View(), "invalid search") {
+		t.Error("expected the parse error in the status line")
+	}
+
+	m.searchInput.SetValue("mode:default")
+	m.updateFilter()
+	if m.filterErr != "" {
+		t.Errorf("expected error to clear, got %q", m.filterErr)
+	}
+}
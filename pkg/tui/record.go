@@ -0,0 +1,305 @@
+package tui
+
+import (
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Builtbyjb/yay/pkg/lib/chord"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Hotkeys are normally recorded from the raw key codes of the global event
+// tap. Without it, e.g. when accessibility access is missing or over SSH,
+// they are recorded from the keys the terminal reports instead. Terminals
+// don't report the command key, unless they support the kitty keyboard
+// protocol, which is turned on while recording.
+
+// listenerFailedMsg reports that the event tap couldn't be started, hotkeys
+// are then recorded from the terminal
+type listenerFailedMsg struct {
+	err error
+}
+
+// listenerLogMsg is something the running listener reports, e.g. hotkeys
+// being paused. err is set when something went wrong.
+type listenerLogMsg struct {
+	msg string
+	err error
+}
+
+// Kitty keyboard protocol sequences: report keys with modifiers as CSI u
+// sequences, and restore the previous mode
+const (
+	kittyKeysPush = "\x1b[>1u"
+	kittyKeysPop  = "\x1b[<u"
+)
+
+// Canonical names of keys whose terminal name differs, see RawToKeyDarwin
+var terminalKeyNames = map[string]string{
+	" ":         "space",
+	"space":     "space",
+	"up":        "up arrow",
+	"down":      "down arrow",
+	"left":      "left arrow",
+	"right":     "right arrow",
+	"pgup":      "page up",
+	"pgdown":    "page down",
+	"-":         "dash",
+	"=":         "equal sign",
+	"[":         "open bracket",
+	"]":         "close bracket / å",
+	"\\":        "back slash",
+	";":         "semi-colon / ñ",
+	"'":         "single quote / ø / ä",
+	",":         "comma",
+	".":         "period",
+	"/":         "forward slash / ç",
+	"`":         "`",
+	"enter":     "enter",
+	"esc":       "esc",
+	"tab":       "tab",
+	"backspace": "backspace",
+	"delete":    "delete",
+	"insert":    "insert",
+	"home":      "home",
+	"end":       "end",
+}
+
+// Keys typed with shift on a US layout, by the key that is pressed
+var shiftedKeys = map[string]string{
+	"!": "1", "@": "2", "#": "3", "$": "4", "%": "5",
+	"^": "6", "&": "7", "*": "8", "(": "9", ")": "0",
+	"_": "-", "+": "=", "{": "[", "}": "]", "|": "\\",
+	":": ";", "\"": "'", "<": ",", ">": ".", "?": "/", "~": "`",
+}
+
+// Terminal modifier names
+var terminalModifiers = map[string]string{
+	"ctrl":  "control",
+	"alt":   "option",
+	"shift": "shift",
+	"super": "command",
+}
+
+// canonicalKey maps a key as reported by the terminal to its hotkey name,
+// adding shift to mods when the key was typed with shift.
+func canonicalKey(k string, mods []string) (string, []string, bool) {
+	if base, ok := shiftedKeys[k]; ok {
+		k = base
+		mods = append(mods, "shift")
+	} else if r := []rune(k); len(r) == 1 && unicode.IsUpper(r[0]) {
+		k = string(unicode.ToLower(r[0]))
+		mods = append(mods, "shift")
+	}
+
+	if name, ok := terminalKeyNames[k]; ok {
+		return name, mods, true
+	}
+	if r := []rune(k); len(r) == 1 && (unicode.IsLower(r[0]) || unicode.IsDigit(r[0])) {
+		return k, mods, true
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(k, "f")); err == nil && strings.HasPrefix(k, "f") && n >= 1 && n <= 20 {
+		return k, mods, true
+	}
+	return "", nil, false
+}
+
+// hotkeyFromKeyMsg builds a hotkey from a key reported by the terminal, e.g.
+// "ctrl+shift+up" becomes "control+shift+up arrow". Keys typed without a
+// modifier, shifted or not, aren't hotkeys.
+func hotkeyFromKeyMsg(msg tea.KeyMsg) (string, bool) {
+	if msg.Paste {
+		return "", false
+	}
+
+	// The key itself may be "+", as in "alt++"
+	s := msg.String()
+	var k string
+	if strings.HasSuffix(s, "++") || s == "+" {
+		k, s = "+", strings.TrimSuffix(s, "+")
+	} else {
+		i := strings.LastIndex(s, "+")
+		k, s = s[i+1:], s[:max(i, 0)]
+	}
+
+	var mods []string
+	for _, token := range strings.Split(s, "+") {
+		if token == "" {
+			continue
+		}
+		mod, ok := terminalModifiers[token]
+		if !ok {
+			return "", false
+		}
+		mods = append(mods, mod)
+	}
+
+	// Shift alone types a character, e.g. "A"
+	if len(mods) == 0 {
+		return "", false
+	}
+	k, mods, ok := canonicalKey(k, mods)
+	if !ok {
+		return "", false
+	}
+	return chord.CanonicalHotkey(mods, k), true
+}
+
+// Modifier bits of the kitty keyboard protocol, set in the reported
+// modifiers minus one
+var kittyModifiers = []struct {
+	bit int
+	mod string
+}{
+	{1, "shift"},
+	{2, "option"},
+	{4, "control"},
+	{8, "command"},
+}
+
+// Keys reported with a number and "~", or a letter, instead of a code point
+var kittyFunctionalKeys = map[string]string{
+	"2~": "insert", "3~": "delete", "5~": "page up", "6~": "page down",
+	"15~": "f5", "17~": "f6", "18~": "f7", "19~": "f8", "20~": "f9",
+	"21~": "f10", "23~": "f11", "24~": "f12",
+	"A": "up arrow", "B": "down arrow", "C": "right arrow", "D": "left arrow",
+	"H": "home", "F": "end", "P": "f1", "Q": "f2", "S": "f4",
+}
+
+// hotkeyFromKitty builds a hotkey from a kitty keyboard protocol sequence,
+// e.g. "\x1b[97;9u" for command+a. cancel is set when escape was pressed
+// without modifiers.
+func hotkeyFromKitty(seq []byte) (hotkey string, cancel bool, ok bool) {
+	body, found := strings.CutPrefix(string(seq), "\x1b[")
+	if !found || len(body) == 0 {
+		return "", false, false
+	}
+	final := body[len(body)-1:]
+	params := strings.Split(body[:len(body)-1], ";")
+
+	modifiers := 1
+	if len(params) > 1 {
+		// Drop the event type, e.g. "5:1"
+		m, _, _ := strings.Cut(params[1], ":")
+		n, err := strconv.Atoi(m)
+		if err != nil {
+			return "", false, false
+		}
+		modifiers = n
+	}
+	var mods []string
+	for _, km := range kittyModifiers {
+		if (modifiers-1)&km.bit != 0 {
+			mods = append(mods, km.mod)
+		}
+	}
+
+	// Drop alternate key codes, e.g. "97:65"
+	code, _, _ := strings.Cut(params[0], ":")
+	var k string
+	switch final {
+	case "u":
+		n, err := strconv.Atoi(code)
+		if err != nil {
+			return "", false, false
+		}
+		switch n {
+		case 27:
+			if len(mods) == 0 {
+				return "", true, false
+			}
+			k = "esc"
+		case 13:
+			k = "enter"
+		case 9:
+			k = "tab"
+		case 127:
+			k = "backspace"
+		default:
+			var known bool
+			if k, mods, known = canonicalKey(string(rune(n)), mods); !known {
+				return "", false, false
+			}
+		}
+	case "~":
+		k = kittyFunctionalKeys[code+final]
+	default:
+		k = kittyFunctionalKeys[final]
+	}
+
+	if k == "" || len(mods) == 0 {
+		return "", false, false
+	}
+	return chord.CanonicalHotkey(mods, k), false, true
+}
+
+// csiSequence returns the raw bytes of a CSI sequence bubbletea didn't
+// recognize, which it hands to the program as an unexported []byte type.
+func csiSequence(msg tea.Msg) ([]byte, bool) {
+	v := reflect.ValueOf(msg)
+	if !v.IsValid() || v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Uint8 {
+		return nil, false
+	}
+	if v.Type().PkgPath() != reflect.TypeOf(tea.KeyMsg{}).PkgPath() {
+		return nil, false
+	}
+	seq := v.Bytes()
+	if !strings.HasPrefix(string(seq), "\x1b[") {
+		return nil, false
+	}
+	return seq, true
+}
+
+// recordingFromTerminal reports whether hotkeys are being recorded from the
+// keys the terminal reports rather than from the event tap.
+func (m model) recordingFromTerminal() bool {
+	return m.recordingHotkey && !m.tapActive
+}
+
+// recordTerminalHotkey assigns a hotkey recorded from the terminal to the
+// app under the cursor.
+func (m *model) recordTerminalHotkey(hotkey string) {
+	idx, ok := m.selectedIndex()
+	if !ok {
+		return
+	}
+	m.recordingHotkey = false
+	m.assignHotkey(idx, hotkey)
+}
+
+// handleTerminalSequence records a hotkey reported with the kitty keyboard
+// protocol.
+func (m model) handleTerminalSequence(seq []byte) (tea.Model, tea.Cmd) {
+	hotkey, cancel, ok := hotkeyFromKitty(seq)
+	switch {
+	case cancel:
+		m.recordingHotkey = false
+	case ok:
+		m.recordTerminalHotkey(hotkey)
+	}
+	return m, nil
+}
+
+// syncKeyboardProtocol turns the kitty keyboard protocol on while a hotkey is
+// recorded from the terminal, and off again afterwards so keys like ctrl+c
+// keep working. Terminals without support ignore the sequences.
+func (m *model) syncKeyboardProtocol() tea.Cmd {
+	want := m.recordingFromTerminal() && m.terminal != nil
+	if want == m.kittyKeys {
+		return nil
+	}
+	m.kittyKeys = want
+
+	seq := kittyKeysPop
+	if want {
+		seq = kittyKeysPush
+	}
+	w := m.terminal
+	return func() tea.Msg {
+		io.WriteString(w, seq)
+		return nil
+	}
+}
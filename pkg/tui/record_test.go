@@ -0,0 +1,195 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Builtbyjb/yay/pkg/lib"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startRecording focuses the cursor row and starts recording a hotkey.
+func startRecording(t *testing.T, m model) model {
+	t.Helper()
+	m = sendKey(t, m, "enter")
+	m = sendKey(t, m, " ")
+	if !m.recordingHotkey {
+		t.Fatal("expected to be recording a hotkey")
+	}
+	return m
+}
+
+func TestHotkeyFromKeyMsg(t *testing.T) {
+	tests := []struct {
+		msg    tea.KeyMsg
+		hotkey string
+		ok     bool
+	}{
+		{tea.KeyMsg{Type: tea.KeyCtrlA}, "control+a", true},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A"), Alt: true}, "option+shift+a", true},
+		{tea.KeyMsg{Type: tea.KeyCtrlShiftUp}, "control+shift+up arrow", true},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!"), Alt: true}, "option+shift+1", true},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("+"), Alt: true}, "option+shift+equal sign", true},
+		{tea.KeyMsg{Type: tea.KeyEsc, Alt: true}, "option+esc", true},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}, "", false},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")}, "", false},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a"), Alt: true, Paste: true}, "", false},
+	}
+	for _, tt := range tests {
+		hotkey, ok := hotkeyFromKeyMsg(tt.msg)
+		if hotkey != tt.hotkey || ok != tt.ok {
+			t.Errorf("hotkeyFromKeyMsg(%q) = %q, %v, want %q, %v", tt.msg.String(), hotkey, ok, tt.hotkey, tt.ok)
+		}
+	}
+}
+
+func TestHotkeyFromKitty(t *testing.T) {
+	tests := []struct {
+		seq    string
+		hotkey string
+		cancel bool
+		ok     bool
+	}{
+		{"\x1b[97;9u", "command+a", false, true},
+		{"\x1b[97:65;10u", "command+shift+a", false, true},
+		{"\x1b[49;13:1u", "command+control+1", false, true},
+		{"\x1b[1;9A", "command+up arrow", false, true},
+		{"\x1b[3;5~", "control+delete", false, true},
+		{"\x1b[27;9u", "command+esc", false, true},
+		{"\x1b[27u", "", true, false},
+		{"\x1b[97u", "", false, false},
+		{"\x1b[?1u", "", false, false},
+	}
+	for _, tt := range tests {
+		hotkey, cancel, ok := hotkeyFromKitty([]byte(tt.seq))
+		if hotkey != tt.hotkey || cancel != tt.cancel || ok != tt.ok {
+			t.Errorf("hotkeyFromKitty(%q) = %q, %v, %v, want %q, %v, %v",
+				tt.seq, hotkey, cancel, ok, tt.hotkey, tt.cancel, tt.ok)
+		}
+	}
+}
+
+func TestRecordFromTerminal_KeyMsg(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := startRecording(t, NewModel(database, testSettings(t, database), "0.1.0"))
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	m = result.(model)
+
+	if m.recordingHotkey {
+		t.Error("expected recording to stop")
+	}
+	if got := hotkeyOf(m, "Finder"); got != "control+f" {
+		t.Errorf("expected Finder hotkey control+f, got %q", got)
+	}
+}
+
+func TestRecordFromTerminal_KittySequence(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := startRecording(t, NewModel(database, testSettings(t, database), "0.1.0"))
+
+	result, _ := m.handleTerminalSequence([]byte("\x1b[102;9u"))
+	m = result.(model)
+
+	if got := hotkeyOf(m, "Finder"); got != "command+f" {
+		t.Errorf("expected Finder hotkey command+f, got %q", got)
+	}
+}
+
+func TestRecordFromTerminal_KittyEscapeCancels(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := startRecording(t, NewModel(database, testSettings(t, database), "0.1.0"))
+
+	result, _ := m.handleTerminalSequence([]byte("\x1b[27u"))
+	m = result.(model)
+
+	if m.recordingHotkey {
+		t.Error("expected escape to cancel recording")
+	}
+	if got := hotkeyOf(m, "Finder"); got != "ctrl+3" {
+		t.Errorf("expected Finder to keep its hotkey, got %q", got)
+	}
+}
+
+func TestRecordFromTerminal_IgnoredOnceTapDeliversKeys(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := startRecording(t, NewModel(database, testSettings(t, database), "0.1.0"))
+
+	// A modifier alone doesn't finish recording, but shows the tap works
+	result, _ := m.Update(lib.CKeyMsg{Event: lib.KeyEvent{Keycode: keycodeCommand, EventType: lib.EventFlagsChanged}})
+	m = result.(model)
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	m = result.(model)
+
+	if !m.recordingHotkey {
+		t.Error("expected to still be recording from the event tap")
+	}
+	if got := hotkeyOf(m, "Finder"); got != "ctrl+3" {
+		t.Errorf("expected the terminal key to be ignored, got %q", got)
+	}
+}
+
+func TestSyncKeyboardProtocol(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	var terminal bytes.Buffer
+	m.terminal = &terminal
+
+	run := func(cmd tea.Cmd) {
+		if cmd != nil {
+			cmd()
+		}
+	}
+
+	m.recordingHotkey = true
+	run(m.syncKeyboardProtocol())
+	if terminal.String() != kittyKeysPush {
+		t.Fatalf("expected the protocol to be turned on, wrote %q", terminal.String())
+	}
+	if cmd := m.syncKeyboardProtocol(); cmd != nil {
+		t.Error("expected no change while still recording")
+	}
+
+	m.recordingHotkey = false
+	run(m.syncKeyboardProtocol())
+	if terminal.String() != kittyKeysPush+kittyKeysPop {
+		t.Errorf("expected the protocol to be turned off, wrote %q", terminal.String())
+	}
+}
+
+func TestListenerFailed_Warns(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	result, _ := m.update(listenerFailedMsg{err: lib.ErrEventTapFailed})
+	m = result.(model)
+
+	if got := lastNotification(m); !strings.Contains(got, "yay doctor") {
+		t.Errorf("expected a warning pointing to yay doctor, got %q", got)
+	}
+	if len(m.notifications) == 0 || m.notifications[len(m.notifications)-1].severity != severityWarning {
+		t.Error("expected the notification to be a warning")
+	}
+}
+
+func TestListenerLog_Notifies(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	result, _ := m.update(listenerLogMsg{msg: "Hotkeys resumed"})
+	m = result.(model)
+	if got := lastNotification(m); got != "Hotkeys resumed" {
+		t.Errorf("expected the listener message as a notification, got %q", got)
+	}
+
+	result, _ = m.update(listenerLogMsg{msg: "Couldn't open Safari", err: errors.New("exit status 1")})
+	m = result.(model)
+	if got := lastNotification(m); got != "Couldn't open Safari: exit status 1" {
+		t.Errorf("expected the listener error as a notification, got %q", got)
+	}
+	if m.notifications[len(m.notifications)-1].severity != severityWarning {
+		t.Error("expected listener errors to be warnings")
+	}
+}
@@ -1,29 +1,69 @@
 package tui
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
 	"github.com/Builtbyjb/yay/pkg/lib"
 	"github.com/Builtbyjb/yay/pkg/lib/core"
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type model struct {
-	db              *core.Database
-	state           focusState
-	settings        []core.Setting
-	searchedIndices []int
-	searchInput     textinput.Model
-	cursor          int // position within SearchedIndices
-	activeCol       columnID
-	version         string
-	width           int
-	height          int
-	keys            []uint16
-	mod             string
-	key             uint16
-	recordingHotkey bool // true when waiting for the next key press for hotkey
-	errors          []string
-	debug           []int
+	db                 *core.Database
+	state              focusState
+	settings           []core.Setting
+	searchedIndices    []int
+	matches            map[int][]int // matched name runes keyed by settings index
+	filterErr          string        // parse error of the search query
+	rows               []tableRow    // table layout of searchedIndices
+	grouped            bool          // group rows by tag
+	collapsed          map[string]bool
+	searchInput        textinput.Model
+	cursor             int // position within SearchedIndices
+	activeCol          columnID
+	version            string
+	width              int
+	height             int
+	keys               []uint16
+	mod                string
+	key                uint16
+	recordingHotkey    bool // true when waiting for the next key press for hotkey
+	notifications      []notification
+	lastNotificationId int
+	logOffset          int       // log panel scroll, in entries from the newest
+	logWriter          io.Writer // error log file, nil when unavailable
+	debug              []int
+	checker            core.ConflictChecker
+	pending            *pendingHotkey // hotkey awaiting conflict resolution
+	marked             map[int]bool   // setting ids selected for a bulk edit
+	visual             bool           // a visual range is being selected
+	visualAnchor       int            // row the visual range started on
+	bulkStep           bulkStep
+	bulkInput          textinput.Model // tag name for bulk tagging
+	columns            []string        // ids of the visible table columns
+	sortCol            string
+	sortDesc           bool
+	columnCursor       int           // position in the column picker
+	inspector          bool          // show the inspector pane when the terminal is wide enough
+	history            []core.Change // hotkey history of the app under the cursor
+	historyFor         historyKey    // app and hotkey history was loaded for
+	keymap             keyMap
+	help               help.Model
+	lastClick          tableCell // last clicked cell, for double-clicks
+	lastClickAt        time.Time
+	launch             func(core.Setting) error                      // opens an app, nil when launching is unavailable
+	trigger            func(hotkey, bundleId string) (string, error) // acts as if hotkey was pressed in the app
+	tapActive          bool                                          // the global event tap forwards key events
+	kittyKeys          bool                                          // the kitty keyboard protocol is turned on
+	terminal           io.Writer                                     // for escape sequences, nil in tests
+	graphics           graphicsProtocol
+	icons              map[int]string // rendered icon cells keyed by setting id
 }
 
 func NewModel(db *core.Database, settings []core.Setting, version string) model {
@@ -32,6 +72,11 @@ func NewModel(db *core.Database, settings []core.Setting, version string) model
 	ti.CharLimit = 64
 	ti.Width = 40
 
+	bi := textinput.New()
+	bi.Placeholder = "tag"
+	bi.CharLimit = 64
+	bi.Width = 30
+
 	m := model{
 		db:          db,
 		state:       stateBrowse,
@@ -41,33 +86,91 @@ func NewModel(db *core.Database, settings []core.Setting, version string) model
 		activeCol:   colNone,
 		version:     version,
 		keys:        []uint16{},
-		errors:      []string{},
 		debug:       []int{},
+		graphics:    detectGraphics(),
+		icons:       map[int]string{},
+		collapsed:   map[string]bool{},
+		marked:      map[int]bool{},
+		columns:     slices.Clone(DEFAULT_COLUMNS),
+		sortCol:     DEFAULT_SORT,
+		inspector:   true,
+		bulkInput:   bi,
+		keymap:      defaultKeyMap(),
+		help:        help.New(),
 	}
+	m.loadPreferences()
+	m.loadInspectorPreference()
 	m.updateFilter()
 	return m
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	return loadIcons(m.settings, m.graphics)
 }
 
-// Starts the TUI
-func Run(db *core.Database, settings []core.Setting, version string) error {
+// Run starts the TUI. theme names a built-in theme, or is empty or "auto" to
+// use the user theme file if there is one and otherwise follow the terminal
+// background.
+func Run(db *core.Database, settings []core.Setting, version string, theme string) error {
+	// Without a support directory there is no user theme to load
+	themePath, _ := lib.GetThemePath()
+	t, themeErr := ResolveTheme(theme, themePath)
+	ApplyTheme(t)
+
 	m := NewModel(db, settings, version)
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	m.checker = lib.NewConflictChecker()
+	m.terminal = os.Stdout
+	m.launch = func(s core.Setting) error { return lib.LaunchSetting(db, s) }
+	m.trigger = func(hotkey, bundleId string) (string, error) { return lib.TriggerHotkey(db, hotkey, bundleId) }
+	if themeErr != nil {
+		m.notify(severityWarning, fmt.Sprintf("Using the %s theme: %v", t.Name, themeErr))
+	}
 
-	go lib.KeyEventListener(db, func(event lib.KeyEvent) {
-		p.Send(lib.CKeyMsg{Event: event})
-	})
+	keysPath, _ := lib.GetKeysPath()
+	keymap, err := LoadKeyMap(keysPath)
+	m.keymap = keymap
+	if err != nil {
+		m.notify(severityWarning, fmt.Sprintf("Using the default keys: %v", err))
+	}
 
-	_, err := p.Run()
+	logPath, err := lib.GetLogPath()
+	if err == nil {
+		var logFile *os.File
+		logFile, err = os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err == nil {
+			defer logFile.Close()
+			m.logWriter = logFile
+		}
+	}
+	if err != nil {
+		m.notify(severityWarning, fmt.Sprintf("Errors won't be logged to a file: %v", err))
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+
+	go func() {
+		err := lib.KeyEventListener(db, func(event lib.KeyEvent) {
+			p.Send(lib.CKeyMsg{Event: event})
+		}, func(msg string, err error) {
+			p.Send(listenerLogMsg{msg: msg, err: err})
+		})
+		if err != nil {
+			p.Send(listenerFailedMsg{err: err})
+		}
+	}()
+
+	final, err := p.Run()
+	lib.StopEventTap()
+	if fm, ok := final.(model); ok && fm.kittyKeys {
+		io.WriteString(os.Stdout, kittyKeysPop)
+	}
 	if err != nil {
 		return err
 	}
 
-	// fmt.Println(fModel.(model).errors)
-	// fmt.Println(fModel.(model).debug)
+	if n := final.(model).errorCount(); n > 0 && m.logWriter != nil {
+		fmt.Fprintf(os.Stderr, "%d error(s) occurred, see %s\n", n, logPath)
+	}
 
 	return nil
 }
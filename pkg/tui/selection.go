@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"slices"
+)
+
+// hasSelection reports whether any app is marked or a visual range is open.
+func (m model) hasSelection() bool {
+	return len(m.marked) > 0 || m.visual
+}
+
+// isRowSelected reports whether the table row at i is marked or inside the
+// visual range.
+func (m model) isRowSelected(i int) bool {
+	row := m.rows[i]
+	if row.isHeader() {
+		return false
+	}
+	if m.marked[m.settings[row.idx].Id] {
+		return true
+	}
+	if !m.visual {
+		return false
+	}
+	lo, hi := min(m.visualAnchor, m.cursor), max(m.visualAnchor, m.cursor)
+	return i >= lo && i <= hi
+}
+
+// selectedIds returns the setting ids of the marked apps and of those in the
+// visual range, in settings order.
+func (m model) selectedIds() []int {
+	selected := make(map[int]bool, len(m.marked))
+	for id := range m.marked {
+		selected[id] = true
+	}
+	if m.visual {
+		for i := range m.rows {
+			if m.isRowSelected(i) {
+				selected[m.settings[m.rows[i].idx].Id] = true
+			}
+		}
+	}
+
+	ids := make([]int, 0, len(selected))
+	for _, s := range m.settings {
+		if selected[s.Id] {
+			ids = append(ids, s.Id)
+		}
+	}
+	return ids
+}
+
+// toggleMark marks or unmarks the app under the cursor and moves down. On a
+// group header it marks every filtered app of the group, or unmarks them if
+// they are all marked already.
+func (m *model) toggleMark() {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return
+	}
+
+	if row := m.rows[m.cursor]; row.isHeader() {
+		var members []int
+		for _, idx := range m.searchedIndices {
+			if m.inGroup(idx, row.group) {
+				members = append(members, idx)
+			}
+		}
+		m.toggleMarks(members)
+	} else {
+		id := m.settings[row.idx].Id
+		if m.marked[id] {
+			delete(m.marked, id)
+		} else {
+			m.marked[id] = true
+		}
+	}
+
+	m.moveCursor(1)
+}
+
+// inGroup reports whether the app at settings index idx is listed under group.
+func (m model) inGroup(idx int, group string) bool {
+	tags := m.settings[idx].Tags
+	if len(tags) == 0 {
+		return group == untaggedGroup
+	}
+	return slices.Contains(tags, group)
+}
+
+// toggleMarks marks all apps at the given settings indices, or unmarks them
+// when they are all marked already.
+func (m *model) toggleMarks(indices []int) {
+	all := len(indices) > 0
+	for _, idx := range indices {
+		if !m.marked[m.settings[idx].Id] {
+			all = false
+			break
+		}
+	}
+
+	for _, idx := range indices {
+		if all {
+			delete(m.marked, m.settings[idx].Id)
+		} else {
+			m.marked[m.settings[idx].Id] = true
+		}
+	}
+}
+
+// selectAllFiltered marks every app matching the search, or unmarks them all
+// when they already are.
+func (m *model) selectAllFiltered() {
+	m.toggleMarks(m.searchedIndices)
+}
+
+// toggleVisual starts a visual range at the cursor, or marks the apps in the
+// range and ends it.
+func (m *model) toggleVisual() {
+	if !m.visual {
+		if len(m.rows) > 0 {
+			m.visual = true
+			m.visualAnchor = m.cursor
+		}
+		return
+	}
+
+	for _, id := range m.selectedIds() {
+		m.marked[id] = true
+	}
+	m.visual = false
+}
+
+func (m *model) clearSelection() {
+	m.marked = map[int]bool{}
+	m.visual = false
+}
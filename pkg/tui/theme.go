@@ -1,49 +1,262 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
-var (
-	LogoStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(PRIMARY_COLOR))
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is a color scheme for the TUI. Colors are hex values such as
+// "#00b4d8" or ANSI color numbers from 0 to 255.
+type Theme struct {
+	Name string `json:"name"`
+	// Built-in theme filling in the colors a user theme leaves out, "auto"
+	// picks dark or light from the terminal background
+	Base            string `json:"base,omitempty"`
+	Primary         string `json:"primary,omitempty"`
+	Secondary       string `json:"secondary,omitempty"`
+	PrimaryAccent   string `json:"primary_accent,omitempty"`
+	SecondaryAccent string `json:"secondary_accent,omitempty"`
+	Active          string `json:"active,omitempty"`
+	Error           string `json:"error,omitempty"`
+	Warning         string `json:"warning,omitempty"`
+}
 
-	VersionStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(PRIMARY_COLOR)).
-			Bold(true)
+// Built-in themes, selectable by name
+var BuiltinThemes = []Theme{DARK_THEME, LIGHT_THEME, HIGH_CONTRAST_THEME}
 
-	DimStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(SECONDARY_COLOR))
+// Reports whether the terminal has a dark background. Replaced in tests, the
+// real check queries the terminal.
+var hasDarkBackground = lipgloss.HasDarkBackground
+
+var (
+	LogoStyle    lipgloss.Style
+	VersionStyle lipgloss.Style
+	DimStyle     lipgloss.Style
 
 	// Filter input label
-	FilterLabelStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(PRIMARY_COLOR)).
-				Bold(true)
+	FilterLabelStyle lipgloss.Style
+
+	// Table cells and headers
+	TableCellStyle   lipgloss.Style
+	TableHeaderStyle lipgloss.Style
 
 	// Normal row
-	NormalRowStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(PRIMARY_COLOR))
+	NormalRowStyle lipgloss.Style
 
 	// Cursor row (highlighted in browse/filter mode)
-	CursorRowStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(PRIMARY_COLOR)).
-			Background(lipgloss.Color(PRIMARY_ACCENT_COLOR))
+	CursorRowStyle lipgloss.Style
 
 	// Focused row (in row-focus mode)
-	FocusedRowStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(PRIMARY_COLOR)).
-			Background(lipgloss.Color(SECONDARY_ACCENT_COLOR))
+	FocusedRowStyle lipgloss.Style
 
 	// Active column cell (the column currently being edited)
-	ActiveCellStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(PRIMARY_COLOR)).
-			Background(lipgloss.Color(ACTIVE_COLOR)).
-			Bold(true)
+	ActiveCellStyle lipgloss.Style
+
+	// Rows marked for a bulk edit
+	SelectedRowStyle lipgloss.Style
+
+	// Tag group header row
+	GroupHeaderStyle lipgloss.Style
+
+	// Characters matched by the search query
+	MatchStyle lipgloss.Style
+
+	// Errors shown in the status line
+	ErrorStyle lipgloss.Style
+
+	// Warnings shown as toasts and in the log panel
+	WarningStyle lipgloss.Style
+
+	// Informational toasts
+	InfoStyle lipgloss.Style
+
+	// Panels shown in place of the table, e.g. the notification log
+	PanelStyle lipgloss.Style
+
+	// App name at the top of the inspector
+	InspectorTitleStyle lipgloss.Style
+
+	// Field labels in the inspector
+	InspectorLabelStyle lipgloss.Style
 
 	// Help bar at the bottom
-	HelpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(PRIMARY_COLOR))
+	HelpStyle lipgloss.Style
 
 	// Status indicator style
-	StatusStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(PRIMARY_COLOR)).
-			Bold(true)
+	StatusStyle lipgloss.Style
 )
+
+func init() {
+	ApplyTheme(DARK_THEME)
+}
+
+// ApplyTheme derives every style from t. Call it before the program starts,
+// styles are read while rendering.
+func ApplyTheme(t Theme) {
+	primary := lipgloss.Color(t.Primary)
+	secondary := lipgloss.Color(t.Secondary)
+	active := lipgloss.Color(t.Active)
+
+	LogoStyle = lipgloss.NewStyle().Foreground(primary)
+	VersionStyle = lipgloss.NewStyle().Foreground(primary).Bold(true)
+	DimStyle = lipgloss.NewStyle().Foreground(secondary)
+	FilterLabelStyle = lipgloss.NewStyle().Foreground(primary).Bold(true)
+
+	TableCellStyle = lipgloss.NewStyle().Foreground(primary).Padding(0, 1)
+	TableHeaderStyle = TableCellStyle.Bold(true)
+
+	NormalRowStyle = lipgloss.NewStyle().Foreground(primary)
+	CursorRowStyle = lipgloss.NewStyle().
+		Foreground(primary).
+		Background(lipgloss.Color(t.PrimaryAccent))
+	FocusedRowStyle = lipgloss.NewStyle().
+		Foreground(primary).
+		Background(lipgloss.Color(t.SecondaryAccent))
+	ActiveCellStyle = lipgloss.NewStyle().
+		Foreground(primary).
+		Background(active).
+		Bold(true)
+	SelectedRowStyle = lipgloss.NewStyle().Foreground(active)
+	GroupHeaderStyle = lipgloss.NewStyle().Foreground(secondary).Bold(true)
+	MatchStyle = lipgloss.NewStyle().
+		Foreground(active).
+		Bold(true).
+		Underline(true)
+
+	ErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Error)).Bold(true)
+	WarningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Warning)).Bold(true)
+	InfoStyle = lipgloss.NewStyle().Foreground(active)
+
+	PanelStyle = lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(secondary)
+	InspectorTitleStyle = lipgloss.NewStyle().Foreground(primary).Bold(true)
+	InspectorLabelStyle = lipgloss.NewStyle().Foreground(secondary).Bold(true)
+
+	HelpStyle = lipgloss.NewStyle().Foreground(primary)
+	StatusStyle = lipgloss.NewStyle().Foreground(primary).Bold(true)
+}
+
+// ThemeNames lists the names accepted by ResolveTheme.
+func ThemeNames() []string {
+	names := []string{THEME_AUTO}
+	for _, t := range BuiltinThemes {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func builtinTheme(name string) (Theme, bool) {
+	i := slices.IndexFunc(BuiltinThemes, func(t Theme) bool { return t.Name == name })
+	if i < 0 {
+		return Theme{}, false
+	}
+	return BuiltinThemes[i], true
+}
+
+// detectTheme picks the dark or light theme from the terminal background.
+func detectTheme() Theme {
+	if hasDarkBackground() {
+		return DARK_THEME
+	}
+	return LIGHT_THEME
+}
+
+// ResolveTheme returns the theme to use. An empty name or "auto" loads the
+// user theme at path if there is one, and otherwise follows the terminal
+// background. Any other name picks a built-in theme.
+func ResolveTheme(name string, path string) (Theme, error) {
+	if name != "" && name != THEME_AUTO {
+		t, ok := builtinTheme(name)
+		if !ok {
+			return detectTheme(), fmt.Errorf("unknown theme %q, expected one of %s", name, strings.Join(ThemeNames(), ", "))
+		}
+		return t, nil
+	}
+
+	if path != "" {
+		t, err := LoadThemeFile(path)
+		if err == nil {
+			return t, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return detectTheme(), err
+		}
+	}
+	return detectTheme(), nil
+}
+
+// LoadThemeFile reads a user theme from a JSON file. Colors it leaves out are
+// taken from its base theme.
+func LoadThemeFile(path string) (Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, err
+	}
+
+	var t Theme
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&t); err != nil {
+		return Theme{}, fmt.Errorf("invalid theme %s: %w", path, err)
+	}
+
+	var base Theme
+	switch t.Base {
+	case "", THEME_AUTO:
+		base = detectTheme()
+	default:
+		var ok bool
+		if base, ok = builtinTheme(t.Base); !ok {
+			return Theme{}, fmt.Errorf("invalid theme %s: unknown base theme %q", path, t.Base)
+		}
+	}
+
+	if t.Name == "" {
+		t.Name = "custom"
+	}
+	for _, c := range []struct {
+		field string
+		value *string
+		base  string
+	}{
+		{"primary", &t.Primary, base.Primary},
+		{"secondary", &t.Secondary, base.Secondary},
+		{"primary_accent", &t.PrimaryAccent, base.PrimaryAccent},
+		{"secondary_accent", &t.SecondaryAccent, base.SecondaryAccent},
+		{"active", &t.Active, base.Active},
+		{"error", &t.Error, base.Error},
+		{"warning", &t.Warning, base.Warning},
+	} {
+		if *c.value == "" {
+			*c.value = c.base
+			continue
+		}
+		if !validColor(*c.value) {
+			return Theme{}, fmt.Errorf("invalid theme %s: %s color %q is not a hex color or ANSI color number", path, c.field, *c.value)
+		}
+	}
+
+	return t, nil
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validColor reports whether lipgloss can render c, a hex color or an ANSI
+// color number.
+func validColor(c string) bool {
+	if hexColor.MatchString(c) {
+		return true
+	}
+	n, err := strconv.Atoi(c)
+	return err == nil && n >= 0 && n <= 255
+}
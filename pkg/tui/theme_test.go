@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// withBackground makes background detection report dark for the test.
+func withBackground(t *testing.T, dark bool) {
+	t.Helper()
+	original := hasDarkBackground
+	hasDarkBackground = func() bool { return dark }
+	t.Cleanup(func() { hasDarkBackground = original })
+}
+
+func writeTheme(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "theme.json")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write theme: %v", err)
+	}
+	return path
+}
+
+// ─── Built-in themes ─────────────────────────────────────────────────────────
+
+func TestTheme_BuiltinColorsValid(t *testing.T) {
+	for _, theme := range BuiltinThemes {
+		for _, c := range []string{theme.Primary, theme.Secondary, theme.PrimaryAccent, theme.SecondaryAccent, theme.Active, theme.Error, theme.Warning} {
+			if !validColor(c) {
+				t.Errorf("expected valid colors in the %s theme, got %q", theme.Name, c)
+			}
+		}
+	}
+}
+
+func TestTheme_ResolveByName(t *testing.T) {
+	withBackground(t, true)
+
+	theme, err := ResolveTheme("high-contrast", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if theme.Name != "high-contrast" {
+		t.Errorf("expected high-contrast, got %s", theme.Name)
+	}
+
+	theme, err = ResolveTheme("sepia", "")
+	if err == nil {
+		t.Error("expected an error for an unknown theme")
+	}
+	if theme.Name != "dark" {
+		t.Errorf("expected the detected theme as fallback, got %s", theme.Name)
+	}
+}
+
+func TestTheme_AutoFollowsBackground(t *testing.T) {
+	withBackground(t, false)
+	missing := filepath.Join(t.TempDir(), "theme.json")
+
+	theme, err := ResolveTheme(THEME_AUTO, missing)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if theme.Name != "light" {
+		t.Errorf("expected light on a light background, got %s", theme.Name)
+	}
+
+	withBackground(t, true)
+	if theme, _ := ResolveTheme("", missing); theme.Name != "dark" {
+		t.Errorf("expected dark on a dark background, got %s", theme.Name)
+	}
+}
+
+// ─── User themes ─────────────────────────────────────────────────────────────
+
+func TestTheme_UserFileFillsFromBase(t *testing.T) {
+	withBackground(t, true)
+	path := writeTheme(t, `{"name": "mine", "base": "light", "active": "#ff00ff", "error": "196"}`)
+
+	theme, err := ResolveTheme(THEME_AUTO, path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if theme.Name != "mine" {
+		t.Errorf("expected the user theme, got %s", theme.Name)
+	}
+	if theme.Active != "#ff00ff" || theme.Error != "196" {
+		t.Errorf("expected the user colors, got %s and %s", theme.Active, theme.Error)
+	}
+	if theme.Primary != LIGHT_THEME.Primary {
+		t.Errorf("expected missing colors from the light theme, got %s", theme.Primary)
+	}
+}
+
+func TestTheme_UserFileAutoBase(t *testing.T) {
+	withBackground(t, false)
+	path := writeTheme(t, `{"active": "#123"}`)
+
+	theme, err := LoadThemeFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if theme.Name != "custom" {
+		t.Errorf("expected a default name, got %s", theme.Name)
+	}
+	if theme.Secondary != LIGHT_THEME.Secondary {
+		t.Errorf("expected missing colors from the detected theme, got %s", theme.Secondary)
+	}
+}
+
+func TestTheme_NamedThemeIgnoresUserFile(t *testing.T) {
+	path := writeTheme(t, `{"name": "mine"}`)
+
+	theme, err := ResolveTheme("dark", path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if theme.Name != "dark" {
+		t.Errorf("expected dark, got %s", theme.Name)
+	}
+}
+
+func TestTheme_InvalidUserFile(t *testing.T) {
+	withBackground(t, true)
+
+	tests := []struct {
+		name     string
+		contents string
+		want     string
+	}{
+		{"bad color", `{"primary": "#fffff"}`, "primary color"},
+		{"bad ansi", `{"warning": "256"}`, "warning color"},
+		{"unknown field", `{"primry": "#ffffff"}`, "primry"},
+		{"unknown base", `{"base": "sepia"}`, "sepia"},
+		{"malformed", `{`, "invalid theme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theme, err := ResolveTheme(THEME_AUTO, writeTheme(t, tt.contents))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error mentioning %q, got %v", tt.want, err)
+			}
+			if theme.Name != "dark" {
+				t.Errorf("expected the detected theme as fallback, got %s", theme.Name)
+			}
+		})
+	}
+}
+
+// ─── Styles ──────────────────────────────────────────────────────────────────
+
+func TestTheme_ApplyDerivesStyles(t *testing.T) {
+	t.Cleanup(func() { ApplyTheme(DARK_THEME) })
+
+	ApplyTheme(LIGHT_THEME)
+
+	if got := NormalRowStyle.GetForeground(); got != lipgloss.Color(LIGHT_THEME.Primary) {
+		t.Errorf("expected rows in the primary color, got %v", got)
+	}
+	if got := CursorRowStyle.GetBackground(); got != lipgloss.Color(LIGHT_THEME.PrimaryAccent) {
+		t.Errorf("expected the cursor row on the accent color, got %v", got)
+	}
+	if got := ErrorStyle.GetForeground(); got != lipgloss.Color(LIGHT_THEME.Error) {
+		t.Errorf("expected errors in the error color, got %v", got)
+	}
+	if got := TableHeaderStyle.GetForeground(); got != lipgloss.Color(LIGHT_THEME.Primary) {
+		t.Errorf("expected table headers in the primary color, got %v", got)
+	}
+}
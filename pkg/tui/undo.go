@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+)
+
+// undo reverts the last edit recorded in the database journal, which may
+// have been made by an earlier session or the CLI.
+func (m *model) undo() {
+	changes, err := m.db.Undo()
+	m.replayed("Undid", changes, err, func(c core.Change) core.Change {
+		c.New = c.Old
+		return c
+	})
+}
+
+// redo reapplies the last undone edit.
+func (m *model) redo() {
+	changes, err := m.db.Redo()
+	m.replayed("Redid", changes, err, func(c core.Change) core.Change {
+		return c
+	})
+}
+
+// replayed mirrors the replayed changes in the model. target maps each
+// change to the value the setting now holds.
+func (m *model) replayed(verb string, changes []core.Change, err error, target func(core.Change) core.Change) {
+	if errors.Is(err, core.ErrNothingToUndo) || errors.Is(err, core.ErrNothingToRedo) {
+		m.notify(severityInfo, err.Error())
+		return
+	}
+	if err != nil {
+		m.notifyError(err)
+		return
+	}
+
+	for _, c := range changes {
+		m.applyChange(target(c))
+	}
+	m.updateFilter()
+
+	if len(changes) == 1 {
+		m.notify(severityInfo, fmt.Sprintf("%s: %s", verb, changes[0]))
+	} else {
+		m.notify(severityInfo, fmt.Sprintf("%s %d changes", verb, len(changes)))
+	}
+}
+
+// applyChange sets the new value of a journal change on the matching setting.
+func (m *model) applyChange(c core.Change) {
+	idx := m.indexOfId(c.SettingId)
+	if idx < 0 {
+		return
+	}
+
+	switch c.Field {
+	case core.FieldHotkey:
+		m.settings[idx].HotKey = c.New
+	case core.FieldMode:
+		m.settings[idx].Mode = c.New.String
+	case core.FieldEnabled:
+		m.settings[idx].Enabled = c.New.String == "true"
+	case core.FieldScope:
+		m.settings[idx].Scope = c.New.String
+	}
+}
@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"testing"
+)
+
+func TestUndo_ClearedHotkey(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	// Finder is the first row and has ctrl+3
+	m = sendKey(t, m, "enter")
+	m = sendKey(t, m, "backspace")
+	if got := hotkeyOf(m, "Finder"); got != "" {
+		t.Fatalf("expected hotkey to be cleared, got %q", got)
+	}
+
+	m = sendKey(t, m, UNDO_KEY)
+	if got := hotkeyOf(m, "Finder"); got != "ctrl+3" {
+		t.Errorf("expected ctrl+3 after undo, got %q", got)
+	}
+
+	stored, _ := database.FindByHotkey("ctrl+3")
+	if stored == nil || stored.Name != "Finder" {
+		t.Errorf("expected undo to be persisted, got %v", stored)
+	}
+
+	m = sendKey(t, m, REDO_KEY)
+	if got := hotkeyOf(m, "Finder"); got != "" {
+		t.Errorf("expected hotkey cleared again after redo, got %q", got)
+	}
+}
+
+func TestUndo_ToggleEnabledFromBrowse(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = sendKey(t, m, "enter")
+	m = sendKey(t, m, "tab")
+	m = sendKey(t, m, "tab")
+	m = sendKey(t, m, " ")
+	m = sendKey(t, m, "esc")
+
+	idx := m.indexOfId(m.settings[m.rows[0].idx].Id)
+	if !m.settings[idx].Enabled {
+		t.Fatal("expected Finder to be enabled")
+	}
+
+	m = sendKey(t, m, UNDO_KEY)
+	if m.settings[idx].Enabled {
+		t.Error("expected Finder to be disabled again after undo")
+	}
+	if m.state != stateBrowse {
+		t.Errorf("expected stateBrowse, got %d", m.state)
+	}
+}
+
+func TestUndo_SwapRestoresBothSettings(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+	m.settings[4].HotKey.String = "command+n"
+	m.settings[4].HotKey.Valid = true
+	database.UpdateHotkey(m.settings[4].Id, m.settings[4].HotKey)
+
+	m = recordHotkey(t, m, keycodeN)
+	m = sendKey(t, m, SWAP_KEY)
+	m = sendKey(t, m, UNDO_KEY)
+
+	if got := hotkeyOf(m, "Finder"); got != "ctrl+3" {
+		t.Errorf("expected Finder back on ctrl+3, got %q", got)
+	}
+	if got := hotkeyOf(m, "Terminal"); got != "command+n" {
+		t.Errorf("expected Terminal back on command+n, got %q", got)
+	}
+}
+
+func TestUndo_NothingToUndo(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	m = sendKey(t, m, UNDO_KEY)
+
+	active := m.activeNotifications()
+	if len(active) != 1 || active[0].severity != severityInfo {
+		t.Fatalf("expected an info notification, got %v", active)
+	}
+	if m.errorCount() != 0 {
+		t.Errorf("expected no errors, got %d", m.errorCount())
+	}
+}
+
+func TestUndo_KeepsFilterInSync(t *testing.T) {
+	database := setupTestDatabase(t)
+	m := NewModel(database, testSettings(t, database), "0.1.0")
+
+	finder := m.settings[m.rows[0].idx].Id
+	if err := database.UpdateEnabled(finder, true); err != nil {
+		t.Fatalf("Failed to update enabled: %v", err)
+	}
+	m.settings[m.indexOfId(finder)].Enabled = true
+
+	m.searchInput.SetValue("enabled:false")
+	m.updateFilter()
+	if len(m.searchedIndices) != 0 {
+		t.Fatalf("expected no disabled apps, got %d", len(m.searchedIndices))
+	}
+
+	m = sendKey(t, m, UNDO_KEY)
+	if len(m.searchedIndices) != 1 {
+		t.Errorf("expected Finder to match enabled:false after undo, got %d matches", len(m.searchedIndices))
+	}
+}
@@ -3,14 +3,34 @@ package tui
 import (
 	"database/sql"
 	"fmt"
+	"io"
 	"slices"
-	"strings"
 
 	"github.com/Builtbyjb/yay/pkg/lib"
+	"github.com/Builtbyjb/yay/pkg/lib/core"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+
+	// Notifications can be raised anywhere while handling a message, start
+	// their expiry timers once it has been handled
+	if nm, ok := next.(model); ok {
+		nm.loadHistory()
+		if keyboard := nm.syncKeyboardProtocol(); keyboard != nil {
+			cmd = tea.Batch(cmd, keyboard)
+		}
+		if expiry := nm.scheduleExpiry(); expiry != nil {
+			return nm, tea.Batch(cmd, expiry)
+		}
+		return nm, cmd
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -25,15 +45,66 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.SearchUpdate(msg)
 		case stateRowFocus:
 			return m.handleRowFocusKey(msg)
+		case stateConflict:
+			return m.handleConflictKey(msg)
+		case stateLog:
+			return m.handleLogKey(msg)
+		case stateBulk:
+			return m.handleBulkKey(msg)
+		case stateColumns:
+			return m.handleColumnsKey(msg)
 		}
 		return m, nil
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
+
 	case lib.CKeyMsg:
 		return m.RecordKey(msg)
+
+	case launchedMsg:
+		m.handleLaunched(msg)
+		return m, nil
+
+	case listenerFailedMsg:
+		m.notify(severityWarning, fmt.Sprintf("Global hotkeys are unavailable, run yay doctor: %v", msg.err))
+		return m, nil
+
+	case listenerLogMsg:
+		if msg.err != nil {
+			m.notify(severityWarning, fmt.Sprintf("%s: %v", msg.msg, msg.err))
+		} else {
+			m.notify(severityInfo, msg.msg)
+		}
+		return m, nil
+
+	case iconsLoadedMsg:
+		if msg.upload == "" || m.terminal == nil {
+			m.icons = msg.cells
+			return m, nil
+		}
+		// Upload the images before the cells placing them are shown
+		w := m.terminal
+		return m, func() tea.Msg {
+			io.WriteString(w, msg.upload)
+			return iconsLoadedMsg{cells: msg.cells}
+		}
+
+	case notificationExpiredMsg:
+		m.expireNotification(int(msg))
+		return m, nil
+
+	default:
+		if seq, ok := csiSequence(msg); ok && m.recordingFromTerminal() {
+			return m.handleTerminalSequence(seq)
+		}
 	}
 	return m, nil
 }
 
 func (m model) RecordKey(msg lib.CKeyMsg) (tea.Model, tea.Cmd) {
+	// The tap works, keys reported by the terminal are no longer needed
+	m.tapActive = true
+
 	if m.recordingHotkey {
 		m.keys = append(m.keys, msg.Event.Keycode)
 		// m.debug = append(m.debug, int(msg.Event.Keycode))
@@ -43,22 +114,17 @@ func (m model) RecordKey(msg lib.CKeyMsg) (tea.Model, tea.Cmd) {
 		k, err := lib.RawcodeToString(m.key)
 
 		if err != nil {
-			m.errors = append(m.errors, fmt.Sprintf("Unknown modifier key: %s", k))
+			m.notify(severityWarning, fmt.Sprintf("Unknown key code: %d", m.key))
 			m.recordingHotkey = false
 		}
 
 		switch msg.Event.EventType {
 		case lib.EventKeyDown:
 			if m.mod != "" {
-				if len(m.searchedIndices) > 0 && m.cursor < len(m.searchedIndices) {
+				if idx, ok := m.selectedIndex(); ok {
 					hotkey := fmt.Sprintf("%s+%s", m.mod, k)
-					idx := m.searchedIndices[m.cursor]
-					// m.errors = append(m.errors, hotkey)
-					m.settings[idx].HotKey = sql.NullString{String: hotkey, Valid: true}
-					if err := m.db.UpdateHotkey(m.settings[idx].Id, m.settings[idx].HotKey); err != nil {
-						m.errors = append(m.errors, err.Error())
-					}
 					m.recordingHotkey = false
+					m.assignHotkey(idx, hotkey)
 					m.mod = ""
 
 					return m, nil
@@ -76,37 +142,103 @@ func (m model) RecordKey(msg lib.CKeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) HandleBrowseKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case EXIT_KEY, CANCEL_KEY:
+	switch {
+	case key.Matches(msg, m.keymap.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keymap.Cancel):
+		// Drop the selection before quitting
+		if m.hasSelection() {
+			m.clearSelection()
+			return m, nil
+		}
 		return m, tea.Quit
 
-	case "up", "k":
+	case key.Matches(msg, m.keymap.Up):
 		m.moveCursor(-1)
 		return m, nil
 
-	case "down", "j":
+	case key.Matches(msg, m.keymap.Down):
 		m.moveCursor(1)
 		return m, nil
 
-	case "home", "g":
+	case key.Matches(msg, m.keymap.Top):
 		m.cursor = 0
 		return m, nil
 
-	case "end", "G":
-		if len(m.searchedIndices) > 0 {
-			m.cursor = len(m.searchedIndices) - 1
+	case key.Matches(msg, m.keymap.Bottom):
+		if len(m.rows) > 0 {
+			m.cursor = len(m.rows) - 1
 		}
 		return m, nil
 
-	case "enter":
-		if len(m.searchedIndices) > 0 {
+	case key.Matches(msg, m.keymap.Select):
+		if m.onHeader() {
+			m.toggleCollapse()
+			return m, nil
+		}
+		if _, ok := m.selectedIndex(); ok {
 			m.state = stateRowFocus
-			m.activeCol = colKey
+			m.activeCol = m.nextEditableColumn(colNone)
 			m.recordingHotkey = false
 		}
 		return m, nil
 
-	case SEARCH_KEY:
+	case key.Matches(msg, m.keymap.Group):
+		m.toggleGrouping()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Log):
+		m.openLog()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Undo):
+		m.undo()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Redo):
+		m.redo()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Mark):
+		m.toggleMark()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Visual):
+		m.toggleVisual()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.SelectAll):
+		m.selectAllFiltered()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Bulk):
+		m.openBulk()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Sort):
+		m.cycleSort()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.SortReverse):
+		m.reverseSort()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Columns):
+		m.state = stateColumns
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Inspector):
+		m.toggleInspector()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Launch):
+		return m, m.launchSelected()
+
+	case key.Matches(msg, m.keymap.TestBinding):
+		return m, m.testBinding()
+
+	case key.Matches(msg, m.keymap.Search):
 		m.state = stateFilter
 		m.searchInput.Focus()
 		return m, nil
@@ -121,25 +253,33 @@ func (m model) handleRowFocusKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleHotkeyRecording(msg)
 	}
 
-	switch msg.String() {
-	case EXIT_KEY:
+	switch {
+	case key.Matches(msg, m.keymap.Quit):
 		return m, tea.Quit
 
-	case CANCEL_KEY:
+	case key.Matches(msg, m.keymap.Cancel):
 		m.state = stateBrowse
 		m.activeCol = colNone
 		m.recordingHotkey = false
 		return m, nil
 
-	case SWITCH_COLUMN_KEY:
+	case key.Matches(msg, m.keymap.NextColumn):
 		m.cycleColumn()
 		return m, nil
 
-	case "up", "k":
+	case key.Matches(msg, m.keymap.Undo):
+		m.undo()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Redo):
+		m.redo()
+		return m, nil
+
+	case key.Matches(msg, m.keymap.Up):
 		m.moveCursor(-1)
 		return m, nil
 
-	case "down", "j":
+	case key.Matches(msg, m.keymap.Down):
 		m.moveCursor(1)
 		return m, nil
 	}
@@ -147,35 +287,33 @@ func (m model) handleRowFocusKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Column-specific actions
 	switch m.activeCol {
 	case colKey:
-		switch msg.String() {
-		case "enter", " ":
+		switch {
+		case key.Matches(msg, m.keymap.Edit):
 			m.recordingHotkey = true
 			return m, nil
-		case "delete", "backspace":
-			if len(m.searchedIndices) == 0 || m.cursor >= len(m.searchedIndices) {
+		case key.Matches(msg, m.keymap.ClearHotkey):
+			idx, ok := m.selectedIndex()
+			if !ok {
 				return m, nil
 			}
-			idx := m.searchedIndices[m.cursor]
 			settingId := m.settings[idx].Id
 			err := m.db.ClearHotkey(settingId)
 			m.settings[idx].HotKey = sql.NullString{String: "", Valid: false}
 
 			if err != nil {
-				m.errors = append(m.errors, err.Error())
+				m.notifyError(err)
 			}
 			return m, nil
 		}
 
 	case colMode:
-		switch msg.String() {
-		case "enter", " ":
+		if key.Matches(msg, m.keymap.Edit) {
 			m.cycleMode()
 			return m, nil
 		}
 
 	case colEnabled:
-		switch msg.String() {
-		case "enter", " ":
+		if key.Matches(msg, m.keymap.Edit) {
 			m.toggleEnabled()
 			return m, nil
 		}
@@ -185,7 +323,7 @@ func (m model) handleRowFocusKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *model) moveCursor(delta int) {
-	if len(m.searchedIndices) == 0 {
+	if len(m.rows) == 0 {
 		m.cursor = 0
 		return
 	}
@@ -193,86 +331,87 @@ func (m *model) moveCursor(delta int) {
 	if m.cursor < 0 {
 		m.cursor = 0
 	}
-	if m.cursor >= len(m.searchedIndices) {
-		m.cursor = len(m.searchedIndices) - 1
+	if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
 	}
 }
 
 func (m *model) cycleColumn() {
-	switch m.activeCol {
-	case colNone, colEnabled:
-		m.activeCol = colKey
-	case colKey:
-		m.activeCol = colMode
-	case colMode:
-		m.activeCol = colEnabled
-	}
+	m.activeCol = m.nextEditableColumn(m.activeCol)
 	m.recordingHotkey = false
 }
 
 func (m *model) cycleMode() {
-	if len(m.searchedIndices) == 0 || m.cursor >= len(m.searchedIndices) {
+	idx, ok := m.selectedIndex()
+	if !ok {
 		return
 	}
-	idx := m.searchedIndices[m.cursor]
 	prev := m.settings[idx].Mode
 	currentIdx := slices.Index(AvailableModes, prev)
 	nextIdx := (currentIdx + 1) % len(AvailableModes)
 	m.settings[idx].Mode = AvailableModes[nextIdx]
 	if err := m.db.UpdateMode(m.settings[idx].Id, m.settings[idx].Mode); err != nil {
-		m.errors = append(m.errors, err.Error())
+		m.notifyError(err)
 	}
 }
 
 func (m *model) toggleEnabled() {
-	if len(m.searchedIndices) == 0 || m.cursor >= len(m.searchedIndices) {
+	idx, ok := m.selectedIndex()
+	if !ok {
 		return
 	}
-	idx := m.searchedIndices[m.cursor]
 	prev := m.settings[idx].Enabled
 	m.settings[idx].Enabled = !prev
 	if err := m.db.UpdateEnabled(m.settings[idx].Id, m.settings[idx].Enabled); err != nil {
-		m.errors = append(m.errors, err.Error())
+		m.notifyError(err)
 	}
 }
 
 func (m model) handleHotkeyRecording(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	key := msg.String()
-
-	if key == CANCEL_KEY {
+	switch {
+	case key.Matches(msg, m.keymap.Cancel):
 		m.recordingHotkey = false
 		return m, nil
-	}
 
-	if key == EXIT_KEY {
+	case key.Matches(msg, m.keymap.Quit):
 		return m, tea.Quit
 	}
 
+	if m.recordingFromTerminal() {
+		if hotkey, ok := hotkeyFromKeyMsg(msg); ok {
+			m.recordTerminalHotkey(hotkey)
+		}
+	}
+
 	return m, nil
 }
 
 func (m model) SearchUpdate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case EXIT_KEY:
+	switch {
+	case key.Matches(msg, m.keymap.Quit):
 		return m, tea.Quit
 
-	case CANCEL_KEY:
+	case key.Matches(msg, m.keymap.Cancel):
 		m.state = stateBrowse
 		m.searchInput.Blur()
 		return m, nil
 
-	case "up":
+	case key.Matches(msg, m.keymap.PrevResult):
 		m.moveCursor(-1)
 		return m, nil
 
-	case "down":
+	case key.Matches(msg, m.keymap.NextResult):
 		m.moveCursor(1)
 		return m, nil
 
-	case "enter":
-		if len(m.searchedIndices) > 0 {
+	case key.Matches(msg, m.keymap.Select):
+		if m.onHeader() {
+			m.toggleCollapse()
+			return m, nil
+		}
+		if _, ok := m.selectedIndex(); ok {
 			m.state = stateRowFocus
-			m.activeCol = colKey
+			m.activeCol = m.nextEditableColumn(colNone)
 			m.recordingHotkey = false
 			m.searchInput.Blur()
 		}
@@ -284,9 +423,9 @@ func (m model) SearchUpdate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	m.searchInput, cmd = m.searchInput.Update(msg)
 	m.updateFilter()
 	// Ensure cursor is in bounds after filter changes
-	if m.cursor >= len(m.searchedIndices) {
-		if len(m.searchedIndices) > 0 {
-			m.cursor = len(m.searchedIndices) - 1
+	if m.cursor >= len(m.rows) {
+		if len(m.rows) > 0 {
+			m.cursor = len(m.rows) - 1
 		} else {
 			m.cursor = 0
 		}
@@ -295,17 +434,82 @@ func (m model) SearchUpdate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *model) updateFilter() {
-	query := strings.ToLower(m.searchInput.Value())
+	query, err := parseQuery(m.searchInput.Value())
+	if err != nil {
+		// Keep the previous results rather than showing an empty table
+		m.filterErr = err.Error()
+		return
+	}
+	m.filterErr = ""
+	terms := query.terms
+
 	m.searchedIndices = make([]int, 0, len(m.settings))
+	m.matches = make(map[int][]int)
+	scores := make([]int, len(m.settings))
 	for i, s := range m.settings {
-		if query == "" || strings.Contains(strings.ToLower(s.Name), query) {
-			m.searchedIndices = append(m.searchedIndices, i)
+		if !query.matches(s) {
+			continue
+		}
+		score, positions, ok := matchSetting(s, terms)
+		if !ok {
+			continue
+		}
+		scores[i] = score
+		if len(positions) > 0 {
+			m.matches[i] = positions
 		}
+		m.searchedIndices = append(m.searchedIndices, i)
+	}
+
+	// Best matches first, ties keep the alphabetical order. A sort column
+	// chosen by the user takes precedence over the ranking.
+	if len(terms) > 0 && m.sortedByScore() {
+		slices.SortStableFunc(m.searchedIndices, func(a, b int) int {
+			return scores[b] - scores[a]
+		})
+	} else if !m.sortedByScore() {
+		m.sortIndices()
 	}
+
+	m.buildRows()
 	// Clamp cursor to stay within the new filtered list
-	if len(m.searchedIndices) == 0 {
+	if len(m.rows) == 0 {
 		m.cursor = 0
-	} else if m.cursor >= len(m.searchedIndices) {
-		m.cursor = len(m.searchedIndices) - 1
+	} else if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
+	}
+}
+
+// matchSetting fuzzy matches every search term against the app name, bundle
+// id and hotkey, keeping the best scoring field per term. The returned
+// positions are the matched runes of the name, for highlighting.
+func matchSetting(s core.Setting, terms []string) (int, []int, bool) {
+	total := 0
+	var positions []int
+	for _, term := range terms {
+		pattern := []rune(term)
+
+		best, ok := 0, false
+		var bestPositions []int
+		for field, text := range []string{s.Title(), s.BundleId, s.HotKey.String} {
+			score, pos, matched := fuzzyMatch(pattern, text)
+			if !matched || (ok && score <= best) {
+				continue
+			}
+			best, ok = score, true
+			bestPositions = nil
+			if field == 0 {
+				bestPositions = pos
+			}
+		}
+		if !ok {
+			return 0, nil, false
+		}
+
+		total += best
+		positions = append(positions, bestPositions...)
 	}
+
+	slices.Sort(positions)
+	return total, slices.Compact(positions), true
 }
@@ -60,12 +60,15 @@ func TestUpdateFilter_NoMatch(t *testing.T) {
 func TestUpdateFilter_MultipleMatches(t *testing.T) {
 	database := setupTestDatabase(t)
 	m := NewModel(nil, testSettings(t, database), "0.1.0")
-	// Both "Firefox" and "Finder" contain "fi" (case-insensitive)
+	// "Firefox" and "Finder" start with "fi", "Safari" contains f...i
 	m.searchInput.SetValue("fi")
 	m.updateFilter()
 
-	if len(m.searchedIndices) != 2 {
-		t.Fatalf("expected 2 matches for 'fi', got %d", len(m.searchedIndices))
+	if len(m.searchedIndices) != 3 {
+		t.Fatalf("expected 3 matches for 'fi', got %d", len(m.searchedIndices))
+	}
+	if last := m.settings[m.searchedIndices[2]].Name; last != "Safari" {
+		t.Errorf("expected the scattered match Safari to rank last, got %s", last)
 	}
 }
 
@@ -2,6 +2,8 @@ package tui
 
 import (
 	"strings"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
 func padRight(s string, width int) string {
@@ -12,13 +14,55 @@ func padRight(s string, width int) string {
 }
 
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
 	if maxLen <= 3 {
-		return s[:maxLen]
+		return string(runes[:maxLen])
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
+
+// highlightMatches renders the runes of s at the given positions with
+// MatchStyle. Every segment is rendered with base as well, since the resets
+// emitted after each highlight would otherwise drop the row's colors.
+func highlightMatches(s string, positions []int, base lipgloss.Style) string {
+	if len(positions) == 0 {
+		return s
 	}
-	return s[:maxLen-3] + "..."
+
+	match := MatchStyle.Inherit(base)
+	var b strings.Builder
+	var segment []rune
+	highlighted := false
+	flush := func() {
+		if len(segment) == 0 {
+			return
+		}
+		if highlighted {
+			b.WriteString(match.Render(string(segment)))
+		} else {
+			b.WriteString(base.Render(string(segment)))
+		}
+		segment = segment[:0]
+	}
+
+	p := 0
+	for i, r := range []rune(s) {
+		for p < len(positions) && positions[p] < i {
+			p++
+		}
+		isMatch := p < len(positions) && positions[p] == i
+		if isMatch != highlighted {
+			flush()
+			highlighted = isMatch
+		}
+		segment = append(segment, r)
+	}
+	flush()
+
+	return b.String()
 }
 
 func formatBool(b bool) string {
@@ -2,8 +2,10 @@ package tui
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
 )
@@ -12,8 +14,22 @@ func (m model) View() string {
 	contents := []string{}
 	contents = append(contents, m.HeaderView())
 	contents = append(contents, m.SearchView())
-	contents = append(contents, m.TableView())
+	switch m.state {
+	case stateLog:
+		contents = append(contents, m.LogView())
+	case stateColumns:
+		contents = append(contents, m.ColumnsView())
+	default:
+		if m.showingInspector() {
+			contents = append(contents, lipgloss.JoinHorizontal(lipgloss.Top, m.TableView(), m.InspectorView()))
+		} else {
+			contents = append(contents, m.TableView())
+		}
+	}
 	contents = append(contents, m.StatusLineView())
+	if toast := m.NotificationView(); toast != "" {
+		contents = append(contents, toast)
+	}
 	contents = append(contents, m.HelpView())
 
 	return lipgloss.JoinVertical(lipgloss.Left, contents...)
@@ -70,52 +86,67 @@ func (m model) SearchView() string {
 	)
 }
 
-func (m model) TableView() string {
-	contents := []string{}
-
+// scrollWindow returns the range of rows shown in the table, keeping the
+// cursor in view.
+func (m model) scrollWindow() (int, int) {
 	// Set table height
 	maxRows := max(m.height-20, 3)
 
-	// Calculate scroll window
 	startIdx := 0
-	if len(m.searchedIndices) > maxRows {
+	if len(m.rows) > maxRows {
 		if m.cursor >= maxRows {
 			startIdx = m.cursor - maxRows + 1
 		}
-		if startIdx+maxRows > len(m.searchedIndices) {
-			startIdx = len(m.searchedIndices) - maxRows
+		if startIdx+maxRows > len(m.rows) {
+			startIdx = len(m.rows) - maxRows
 		}
 	}
 
-	endIdx := min(startIdx+maxRows, len(m.searchedIndices))
+	return startIdx, min(startIdx+maxRows, len(m.rows))
+}
+
+func (m model) TableView() string {
+	contents := []string{}
+
+	startIdx, endIdx := m.scrollWindow()
 
-	if len(m.searchedIndices) == 0 {
+	if len(m.rows) == 0 {
 		contents = append(contents, lipgloss.JoinVertical(
 			lipgloss.Left,
 			DimStyle.Render("No matching applications."),
 			"\n",
 		))
 	} else {
+		columns := m.visibleColumns()
+
 		table := table.New().
 			Border(lipgloss.NormalBorder()).
-			Width(m.width).
+			Width(m.tableWidth()).
 			StyleFunc(func(row, col int) lipgloss.Style {
 				isHeader := row == table.HeaderRow
 				isCursorRow := row >= 0 && (startIdx+row) == m.cursor
 				isFocused := isCursorRow && m.state == stateRowFocus
 
-				base := lipgloss.NewStyle().
-					Padding(0, 1).
-					Foreground(lipgloss.Color(PRIMARY_COLOR))
+				base := TableCellStyle
 
 				if isHeader {
-					return base.Bold(true).Foreground(lipgloss.Color(PRIMARY_COLOR))
+					return TableHeaderStyle
+				}
+
+				// Group headers span the row and can't be edited
+				if m.rows[startIdx+row].isHeader() {
+					if isCursorRow {
+						return CursorRowStyle.Bold(true).Padding(0, 1)
+					}
+					return GroupHeaderStyle.Padding(0, 1)
 				}
 
 				// Default row style
 				style := base
 				if isCursorRow {
 					style = CursorRowStyle // your existing cursor style
+				} else if m.isRowSelected(startIdx + row) {
+					style = SelectedRowStyle
 				} else {
 					style = NormalRowStyle // your normal row style
 				}
@@ -125,9 +156,7 @@ func (m model) TableView() string {
 					style = FocusedRowStyle // base for whole row
 
 					// Active (editing/recording) column gets stronger highlight
-					if (col == 1 && m.activeCol == colKey) ||
-						(col == 2 && m.activeCol == colMode) ||
-						(col == 3 && m.activeCol == colEnabled) {
+					if m.activeCol != colNone && columns[col].edit == m.activeCol {
 						style = ActiveCellStyle
 					}
 				}
@@ -137,13 +166,14 @@ func (m model) TableView() string {
 				return style
 			})
 
-		table.Headers("Application", "HotKey", "Mode", "Enabled")
+		headers := make([]string, len(columns))
+		for i, c := range columns {
+			headers[i] = m.headerTitle(c)
+		}
+		table.Headers(headers...)
 
 		// Add only the visible rows
 		for i := startIdx; i < endIdx; i++ {
-			idx := m.searchedIndices[i]
-			s := m.settings[idx]
-
 			isCursor := i == m.cursor
 			isFocused := isCursor && m.state == stateRowFocus
 
@@ -151,22 +181,61 @@ func (m model) TableView() string {
 			if isCursor {
 				prefix = "> "
 			}
+			if m.isRowSelected(i) {
+				prefix = prefix[:1] + MARK_ICON
+			}
 
-			name := truncate(s.Name, colWidthName-3) // -3 for safety + prefix
-			name = prefix + name
+			if row := m.rows[i]; row.isHeader() {
+				marker := "▾"
+				if m.collapsed[row.group] {
+					marker = "▸"
+				}
+				cells := make([]string, len(columns))
+				cells[0] = fmt.Sprintf("%s%s %s (%d)", prefix, marker, row.group, row.count)
+				table.Row(cells...)
+				continue
+			}
 
-			hotkey := displayKey(s.HotKey.String)
+			idx := m.rows[i].idx
+			s := m.settings[idx]
 
-			// Special case: recording hotkey
-			if isFocused && m.activeCol == colKey && m.recordingHotkey {
-				hotkeyDisplay := "recording..."
-				hotkey = hotkeyDisplay
+			name := truncate(s.Title(), colWidthName-3-colWidthIcon) // -3 for safety + prefix
+			if positions := m.matches[idx]; len(positions) > 0 {
+				rowStyle := NormalRowStyle
+				if isFocused {
+					rowStyle = FocusedRowStyle
+				} else if isCursor {
+					rowStyle = CursorRowStyle
+				} else if m.isRowSelected(i) {
+					rowStyle = SelectedRowStyle
+				}
+				// Skip matches hidden by truncation
+				visible := len([]rune(name))
+				if name != s.Title() {
+					visible -= 3
+				}
+				end := 0
+				for end < len(positions) && positions[end] < visible {
+					end++
+				}
+				name = highlightMatches(name, positions[:end], rowStyle)
+			}
+			name = prefix + m.iconCell(s) + " " + name
+
+			cells := make([]string, len(columns))
+			for col, c := range columns {
+				switch {
+				case col == 0:
+					cells[col] = name
+				case c.edit == colKey && isFocused && m.recordingHotkey:
+					// Special case: recording hotkey
+					cells[col] = "recording..."
+				default:
+					cells[col] = displayKey(truncate(c.value(s), c.width))
+				}
 			}
 
-			mode := s.Mode
-			enabled := formatBool(s.Enabled)
-
-			table.Row(name, hotkey, mode, enabled)
+			table.Row(cells...)
 		}
 
 		contents = append(contents, lipgloss.JoinVertical(
@@ -175,10 +244,10 @@ func (m model) TableView() string {
 		))
 
 		// Scroll indicator
-		if len(m.searchedIndices) >= endIdx {
+		if len(m.rows) >= endIdx {
 			contents = append(contents, lipgloss.JoinVertical(
 				lipgloss.Left,
-				DimStyle.Render(fmt.Sprintf("showing %d-%d of %d", startIdx+1, endIdx, len(m.searchedIndices))),
+				DimStyle.Render(fmt.Sprintf("showing %d-%d of %d", startIdx+1, endIdx, len(m.rows))),
 			))
 		}
 	}
@@ -216,10 +285,62 @@ func (m model) StatusLineView() string {
 			lipgloss.Left,
 			StatusStyle.Render("SEARCH MODE"),
 		)
+	case stateConflict:
+		content = lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			StatusStyle.Render("HOTKEY CONFLICT  |  "),
+			ErrorStyle.Render(m.pending.String()),
+		)
+	case stateLog:
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			StatusStyle.Render("LOG"),
+		)
+	case stateColumns:
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			StatusStyle.Render("COLUMNS"),
+		)
+	case stateBulk:
+		status := fmt.Sprintf("BULK EDIT  |  %d apps", len(m.selectedIds()))
+		if m.bulkStep == bulkTag {
+			content = lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				StatusStyle.Render(status+"  |  tag: "),
+				m.bulkInput.View(),
+			)
+		} else {
+			content = StatusStyle.Render(status)
+		}
 	default:
+		status := "BROWSE"
+		if m.visual {
+			status = "VISUAL"
+		}
+		if m.hasSelection() {
+			status += fmt.Sprintf("  |  %d selected", len(m.selectedIds()))
+		}
 		content = lipgloss.JoinVertical(
 			lipgloss.Left,
-			StatusStyle.Render("BROWSE"),
+			StatusStyle.Render(status),
+		)
+	}
+
+	if n := m.errorCount(); n > 0 && m.state != stateLog {
+		content = lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			content,
+			StatusStyle.Render("  |  "),
+			ErrorStyle.Render(fmt.Sprintf("%d error(s)", n)),
+		)
+	}
+
+	if m.filterErr != "" {
+		content = lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			content,
+			StatusStyle.Render("  |  "),
+			ErrorStyle.Render("invalid search: "+m.filterErr),
 		)
 	}
 
@@ -227,50 +348,142 @@ func (m model) StatusLineView() string {
 }
 
 func (m model) HelpView() string {
-	var content string
+	h := m.help
+	h.Width = m.width
+	h.Styles.ShortKey = HelpStyle.Bold(true)
+	h.Styles.ShortDesc = HelpStyle
+	h.Styles.ShortSeparator = DimStyle
+	h.Styles.Ellipsis = DimStyle
+
+	if m.state == stateRowFocus && m.recordingHotkey {
+		return lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			HelpStyle.Render("Press any key to set the hotkey"),
+			DimStyle.Render(h.ShortSeparator),
+			h.ShortHelpView([]key.Binding{withDesc(m.keymap.Cancel, "Cancel")}),
+		)
+	}
+
+	// The help model stops adding entries once the width is reached but may
+	// still overshoot when there's no room left for its ellipsis
+	return lipgloss.NewStyle().MaxWidth(max(m.width, 0)).Render(h.ShortHelpView(m.helpBindings()))
+}
+
+// helpBindings returns the bindings shown in the help bar for the current
+// state, described for that state.
+func (m model) helpBindings() []key.Binding {
+	k := m.keymap
+	navigate := joinHelp("Navigate", k.Up, k.Down)
 
 	switch m.state {
 	case stateBrowse:
-		content = lipgloss.JoinVertical(
-			lipgloss.Left,
-			HelpStyle.Render("↑/↓/j/k: Navigate | enter: Edit Row | /: Search | esc/ctrl+c: Quit"),
-		)
+		if m.hasSelection() {
+			return []key.Binding{
+				navigate,
+				withDesc(k.Mark, "Mark"),
+				withDesc(k.Visual, "Range"),
+				withDesc(k.SelectAll, "All"),
+				withDesc(k.Bulk, "Bulk Edit"),
+				withDesc(k.Cancel, "Clear Selection"),
+			}
+		}
+		return []key.Binding{
+			navigate,
+			withDesc(k.Select, "Edit Row"),
+			withDesc(k.Launch, "Launch"),
+			withDesc(k.TestBinding, "Test Hotkey"),
+			withDesc(k.Search, "Search"),
+			withDesc(k.Group, "Group by Tag"),
+			joinHelp("Sort", k.Sort, k.SortReverse),
+			withDesc(k.Columns, "Columns"),
+			withDesc(k.Inspector, "Inspector"),
+			joinHelp("Select", k.Mark, k.Visual, k.SelectAll),
+			joinHelp("Undo/Redo", k.Undo, k.Redo),
+			withDesc(k.Log, "Log"),
+			joinHelp("Quit", k.Cancel, k.Quit),
+		}
+
+	case stateLog:
+		return []key.Binding{
+			joinHelp("Scroll", k.Up, k.Down),
+			joinHelp("Close Log", k.Log, k.Cancel),
+			withDesc(k.Quit, "Quit"),
+		}
+
+	case stateColumns:
+		return []key.Binding{
+			navigate,
+			withDesc(k.Edit, "Show/Hide Column"),
+			joinHelp("Close", k.Columns, k.Cancel),
+			withDesc(k.Quit, "Quit"),
+		}
+
+	case stateBulk:
+		switch m.bulkStep {
+		case bulkMode:
+			bindings := make([]key.Binding, 0, len(AvailableModes)+1)
+			for i, mode := range AvailableModes {
+				n := strconv.Itoa(i + 1)
+				bindings = append(bindings, key.NewBinding(key.WithKeys(n), key.WithHelp(n, mode)))
+			}
+			return append(bindings, withDesc(k.Cancel, "Cancel"))
+		case bulkTag:
+			return []key.Binding{withDesc(k.Select, "Add Tag"), withDesc(k.Cancel, "Cancel")}
+		}
+		return []key.Binding{
+			withDesc(k.BulkEnable, "Enable"),
+			withDesc(k.BulkDisable, "Disable"),
+			withDesc(k.BulkMode, "Set Mode"),
+			withDesc(k.BulkClear, "Clear Hotkeys"),
+			withDesc(k.BulkTag, "Add Tag"),
+			withDesc(k.Cancel, "Cancel"),
+		}
+
 	case stateFilter:
-		content = lipgloss.JoinVertical(
-			lipgloss.Left,
-			HelpStyle.Render("↑/↓: Navigate | enter: Edit Row | esc: Stop Searching | ctrl+c: Quit"),
-		)
+		return []key.Binding{
+			joinHelp("Navigate", k.PrevResult, k.NextResult),
+			withDesc(k.Select, "Edit Row"),
+			withDesc(k.Cancel, "Stop Searching"),
+			withDesc(k.Quit, "Quit"),
+		}
+
+	case stateConflict:
+		if _, ok := m.pending.otherSetting(); m.pending.reserved() {
+			return []key.Binding{withDesc(k.Cancel, "Choose Another Hotkey")}
+		} else if ok {
+			return []key.Binding{
+				withDesc(k.Swap, "Swap Hotkeys"),
+				withDesc(k.Reassign, "Reassign"),
+				withDesc(k.Cancel, "Cancel"),
+			}
+		}
+		return []key.Binding{withDesc(k.Confirm, "Assign Anyway"), withDesc(k.Cancel, "Cancel")}
+
 	case stateRowFocus:
 		switch m.activeCol {
 		case colKey:
-			if m.recordingHotkey {
-				content = lipgloss.JoinVertical(
-					lipgloss.Left,
-					HelpStyle.Render("Press any key to set key | backspace: Clear | esc: Cancel"),
-				)
-			} else {
-				content = lipgloss.JoinVertical(
-					lipgloss.Left,
-					HelpStyle.Render("tab: Next Column | enter: Record Hotkey | esc: Un-focus"),
-				)
+			return []key.Binding{
+				withDesc(k.NextColumn, "Next Column"),
+				withDesc(k.Edit, "Record Hotkey"),
+				withDesc(k.ClearHotkey, "Clear"),
+				joinHelp("Undo/Redo", k.Undo, k.Redo),
+				withDesc(k.Cancel, "Un-focus"),
 			}
 		case colMode:
-			content = lipgloss.JoinVertical(
-				lipgloss.Left,
-				HelpStyle.Render("space/enter/←/→: Cycle Mode | tab: Next Column | esc: Un-focus"),
-			)
+			return []key.Binding{
+				withDesc(k.Edit, "Cycle Mode"),
+				withDesc(k.NextColumn, "Next Column"),
+				withDesc(k.Cancel, "Un-focus"),
+			}
 		case colEnabled:
-			content = lipgloss.JoinVertical(
-				lipgloss.Left,
-				HelpStyle.Render("space/enter: Toggle | tab: Next Column | esc: Un-focus"),
-			)
-		default:
-			content = lipgloss.JoinVertical(
-				lipgloss.Left,
-				HelpStyle.Render("tab: select column | esc/ctrl+q: un-focus"),
-			)
+			return []key.Binding{
+				withDesc(k.Edit, "Toggle"),
+				withDesc(k.NextColumn, "Next Column"),
+				withDesc(k.Cancel, "Un-focus"),
+			}
 		}
+		return []key.Binding{withDesc(k.NextColumn, "Select Column"), withDesc(k.Cancel, "Un-focus")}
 	}
 
-	return content
+	return nil
 }